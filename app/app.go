@@ -0,0 +1,180 @@
+// Package app wires MyGram's repositories, usecases and handlers into the
+// HTTP router, so main and the end-to-end tests serve the same API.
+package app
+
+import (
+	auditRepository "api-mygram-go/audit/repository/postgres"
+	"api-mygram-go/auth"
+	authDelivery "api-mygram-go/auth/delivery/http"
+	"api-mygram-go/auth/oidc"
+	authRepository "api-mygram-go/auth/repository/postgres"
+	authUseCase "api-mygram-go/auth/usecase"
+	commentDelivery "api-mygram-go/comment/delivery/http"
+	commentRepository "api-mygram-go/comment/repository/postgres"
+	commentUseCase "api-mygram-go/comment/usecase"
+	"api-mygram-go/config"
+	"api-mygram-go/config/database"
+	"api-mygram-go/health"
+	healthDelivery "api-mygram-go/health/delivery/http"
+	"api-mygram-go/helpers"
+	"api-mygram-go/imagemeta"
+	"api-mygram-go/logging"
+	"api-mygram-go/mail"
+	"api-mygram-go/metrics"
+	metricsDelivery "api-mygram-go/metrics/delivery/http"
+	photoDelivery "api-mygram-go/photo/delivery/http"
+	photoRepository "api-mygram-go/photo/repository/postgres"
+	photoUseCase "api-mygram-go/photo/usecase"
+	socialMediaDelivery "api-mygram-go/socialmedia/delivery/http"
+	socialMediaRepository "api-mygram-go/socialmedia/repository/postgres"
+	socialMediaUseCase "api-mygram-go/socialmedia/usecase"
+	"api-mygram-go/storage/local"
+	"api-mygram-go/tracing"
+	userDelivery "api-mygram-go/user/delivery/http"
+	userRepository "api-mygram-go/user/repository/postgres"
+	userUseCase "api-mygram-go/user/usecase"
+	"api-mygram-go/worker"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"time"
+
+	_ "api-mygram-go/docs"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
+)
+
+// NewRouter returns the router serving every MyGram endpoint from db, and
+// schedules the background jobs the endpoints rely on in workers. It also
+// installs the process-wide token signing keys, password hasher and session
+// validator, so a process should only build one router.
+func NewRouter(cfg config.Config, db *gorm.DB, workers *worker.Group) (*gin.Engine, error) {
+	keySet, err := helpers.NewKeySetFromConfig(cfg.JWT)
+
+	if err != nil {
+		return nil, fmt.Errorf("loading token signing keys: %w", err)
+	}
+
+	blobStore, err := local.NewBlobStore(cfg.Storage.Dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("opening blob storage: %w", err)
+	}
+
+	exportSigningKey := []byte(cfg.Exports.SigningKey)
+
+	if len(exportSigningKey) == 0 {
+		exportSigningKey = make([]byte, 32)
+
+		if _, err = rand.Read(exportSigningKey); err != nil {
+			return nil, fmt.Errorf("generating the export signing key: %w", err)
+		}
+	}
+
+	routers := gin.New()
+
+	routers.Use(tracing.Middleware(), logging.Middleware(), logging.Recovery(), metrics.Middleware())
+
+	routers.Use(func(ctx *gin.Context) {
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.Writer.Header().Set("Access-Control-Max-Age", "86400")
+		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, UPDATE")
+		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Device-Name, X-Request-ID, X-Max")
+		ctx.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		ctx.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+
+		if ctx.Request.Method == "OPTIONS" {
+			ctx.AbortWithStatus(200)
+		} else {
+			ctx.Next()
+		}
+	})
+
+	txManager := database.NewTxManager(db)
+
+	loginAttemptRepository := userRepository.NewLoginAttemptRepository(db)
+	mfaRepository := userRepository.NewMFARepository(db)
+	apiKeyRepository := userRepository.NewAPIKeyRepository(db)
+	sessionRepository := userRepository.NewSessionRepository(db)
+	accountDeletionRepository := userRepository.NewAccountDeletionRepository(db)
+	dataExportRepository := userRepository.NewDataExportRepository(db)
+	emailChangeRepository := userRepository.NewEmailChangeRepository(db)
+	userRepository := userRepository.NewUserRepository(db, helpers.NewPasswordHasher(cfg.Password))
+	mfaUseCase := userUseCase.NewTracedMFAUseCase(userUseCase.NewMFAUseCase(mfaRepository, loginAttemptRepository, txManager, cfg.TOTP))
+	apiKeyUseCase := userUseCase.NewTracedAPIKeyUseCase(userUseCase.NewAPIKeyUseCase(apiKeyRepository))
+	sessionUseCase := userUseCase.NewTracedSessionUseCase(userUseCase.NewSessionUseCase(sessionRepository, keySet.Options.TTL))
+	deletionUseCase := userUseCase.NewTracedAccountDeletionUseCase(userUseCase.NewAccountDeletionUseCase(accountDeletionRepository, userRepository, auditRepository.NewAuditRepository(db), blobStore, txManager, cfg.Accounts.DeletionGracePeriod))
+	exportSignal := worker.NewSignal()
+	dataExportUseCase := userUseCase.NewTracedDataExportUseCase(userUseCase.NewDataExportUseCase(dataExportRepository, blobStore, exportSigningKey, cfg.Exports.Retention, cfg.Exports.LinkTTL, exportSignal.Notify))
+	avatarUseCase := userUseCase.NewTracedAvatarUseCase(userUseCase.NewAvatarUseCase(userRepository, blobStore))
+	userUseCase := userUseCase.NewTracedUserUseCase(userUseCase.NewUserUseCase(userRepository, loginAttemptRepository, emailChangeRepository, mail.NewMailer(cfg.Mail), txManager, cfg.Accounts.EmailVerificationTTL, cfg.Accounts.EmailVerificationURL))
+
+	authenticator := auth.NewAuthenticator(keySet, apiKeyUseCase, sessionUseCase)
+
+	userDelivery.NewUserHandler(routers, userUseCase, mfaUseCase, apiKeyUseCase, sessionUseCase, deletionUseCase, dataExportUseCase, avatarUseCase, keySet, authenticator)
+
+	workers.Every("account-purge", cfg.Accounts.PurgeInterval, func(ctx context.Context) {
+		purged, err := deletionUseCase.PurgeDue(ctx, time.Now())
+
+		if err != nil {
+			slog.ErrorContext(ctx, "purging deleted accounts", "error", err, "purged", purged)
+		} else if purged > 0 {
+			slog.InfoContext(ctx, "purged deleted accounts", "purged", purged)
+		}
+	})
+
+	workers.OnSignal("data-export", exportSignal, time.Minute, func(ctx context.Context) {
+		if _, err := dataExportUseCase.Process(ctx); err != nil {
+			slog.ErrorContext(ctx, "assembling data exports", "error", err)
+		}
+	})
+
+	authDelivery.NewJWKSHandler(routers, keySet)
+
+	providers := oidc.ProvidersFromConfig(cfg.OIDC)
+
+	identityRepository := authRepository.NewIdentityRepository(db)
+	authUseCase := authUseCase.NewTracedAuthUseCase(authUseCase.NewAuthUseCase(identityRepository, userRepository))
+
+	authDelivery.NewAuthHandler(routers, authUseCase, mfaUseCase, sessionUseCase, providers, keySet)
+
+	photoRepository := photoRepository.NewPhotoRepository(db)
+	photoSignal := worker.NewSignal()
+	photoUseCase := photoUseCase.NewTracedPhotoUseCase(photoUseCase.NewPhotoUseCase(photoRepository, blobStore, imagemeta.NewFetcher(cfg.Photos.FetchTimeout), txManager, photoSignal.Notify))
+
+	photoDelivery.NewPhotoHandler(routers, photoUseCase, authenticator)
+
+	workers.OnSignal("photo-metadata", photoSignal, time.Minute, func(ctx context.Context) {
+		processed, err := photoUseCase.ProcessMetadata(ctx)
+
+		if err != nil {
+			slog.ErrorContext(ctx, "reading photo metadata", "error", err, "processed", processed)
+		} else if processed > 0 {
+			// Go on with the next batch rather than wait for a minute.
+			photoSignal.Notify()
+		}
+	})
+
+	commentRepository := commentRepository.NewCommentRepository(db)
+	commentUseCase := commentUseCase.NewTracedCommentUseCase(commentUseCase.NewCommentUseCase(commentRepository, txManager))
+
+	commentDelivery.NewCommentHandler(routers, commentUseCase, photoUseCase, authenticator)
+
+	socialMediaRepository := socialMediaRepository.NewSocialMediaRepository(db)
+	socialMediaUseCase := socialMediaUseCase.NewTracedSocialMediaUseCase(socialMediaUseCase.NewSocialMediaUseCase(socialMediaRepository))
+
+	socialMediaDelivery.NewSocialMediaHandler(routers, socialMediaUseCase, authenticator)
+
+	metricsDelivery.NewMetricsHandler(routers)
+
+	healthDelivery.NewHealthHandler(routers, health.Database(db), health.Migrations(db, database.Models()...))
+
+	routers.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	return routers, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *auditRepository {
+	return &auditRepository{db}
+}
+
+func (auditRepository *auditRepository) Store(ctx context.Context, event *domain.AuditEvent) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	event.ID = fmt.Sprintf("audit-%s", ID)
+
+	if err = database.FromContext(ctx, auditRepository.db).Create(&event).Error; err != nil {
+		return err
+	}
+
+	return
+}
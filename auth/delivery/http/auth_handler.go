@@ -0,0 +1,306 @@
+package delivery
+
+import (
+	"api-mygram-go/auth/oidc"
+	"api-mygram-go/auth/utils"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	stateTokenType   = "oidc_state"
+	stateTokenTTL    = 10 * time.Minute
+	stateCookieName  = "mygram_oidc_state"
+	stateCookiePath  = "/auth"
+	stateValueLength = 24
+)
+
+type authHandler struct {
+	authUseCase    domain.AuthUseCase
+	mfaUseCase     domain.MFAUseCase
+	sessionUseCase domain.SessionUseCase
+	providers      map[string]*oidc.Provider
+	names          []string
+	keySet         *helpers.KeySet
+}
+
+func NewAuthHandler(routers *gin.Engine, authUseCase domain.AuthUseCase, mfaUseCase domain.MFAUseCase, sessionUseCase domain.SessionUseCase, providers []*oidc.Provider, keySet *helpers.KeySet) {
+	handler := &authHandler{authUseCase, mfaUseCase, sessionUseCase, map[string]*oidc.Provider{}, []string{}, keySet}
+
+	for _, provider := range providers {
+		handler.providers[provider.Name()] = provider
+		handler.names = append(handler.names, provider.Name())
+	}
+
+	router := routers.Group("/auth")
+	{
+		router.GET("/providers", handler.Providers)
+		router.GET("/:provider/login", handler.Login)
+		router.GET("/:provider/callback", handler.Callback)
+	}
+}
+
+// Providers godoc
+// @Summary			Fetch sign in providers
+// @Description	List the OpenID Connect providers users can sign in with
+// @Tags				auth
+// @Produce			json
+// @Success			200		{object}	utils.ResponseDataProviders
+// @Router			/auth/providers	[get]
+func (handler *authHandler) Providers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data: utils.Providers{
+			Providers: handler.names,
+		},
+	})
+}
+
+// Login godoc
+// @Summary			Sign in with a provider
+// @Description	Redirect to the provider to start an authorization code flow with PKCE. Pass age to create an account for a new identity.
+// @Tags				auth
+// @Param				provider	path			string	true	"Provider name"
+// @Param				age				query			int			false	"Age used when a new account is created"
+// @Success			302
+// @Failure			404				{object}	utils.ResponseMessage
+// @Failure			502				{object}	utils.ResponseMessage
+// @Router			/auth/{provider}/login	[get]
+func (handler *authHandler) Login(ctx *gin.Context) {
+	provider, ok := handler.providers[ctx.Param("provider")]
+
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: fmt.Sprintf("provider %s isn't configured", ctx.Param("provider")),
+		})
+
+		return
+	}
+
+	age, _ := strconv.Atoi(ctx.Query("age"))
+	state, _ := oidc.RandomString(stateValueLength)
+	nonce, _ := oidc.RandomString(stateValueLength)
+	verifier, err := oidc.NewCodeVerifier()
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ResponseMessage{
+			Status:  "error",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	stateToken, err := handler.keySet.GenerateTypedToken(stateTokenType, jwt.MapClaims{
+		"provider": provider.Name(),
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"age":      age,
+	}, stateTokenTTL)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ResponseMessage{
+			Status:  "error",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx.Request.Context(), state, nonce, verifier)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadGateway, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: "the identity provider is unavailable",
+		})
+
+		return
+	}
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(stateCookieName, stateToken, int(stateTokenTTL.Seconds()), stateCookiePath, "", ctx.Request.TLS != nil, true)
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary			Complete a provider sign in
+// @Description	Exchange the authorization code, verify the id token and retrieve a token for the linked account
+// @Tags				auth
+// @Produce			json
+// @Param				provider	path			string	true	"Provider name"
+// @Param				code			query			string	true	"Authorization code"
+// @Param				state			query			string	true	"State"
+// @Success			200				{object}	utils.ResponseDataLoggedinUser
+// @Success			202				{object}	utils.ResponseDataMFAChallenge
+// @Failure			400				{object}	utils.ResponseMessage
+// @Failure			401				{object}	utils.ResponseMessage
+// @Failure			404				{object}	utils.ResponseMessage
+// @Router			/auth/{provider}/callback	[get]
+func (handler *authHandler) Callback(ctx *gin.Context) {
+	provider, ok := handler.providers[ctx.Param("provider")]
+
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: fmt.Sprintf("provider %s isn't configured", ctx.Param("provider")),
+		})
+
+		return
+	}
+
+	if providerError := ctx.Query("error"); providerError != "" {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: fmt.Sprintf("the identity provider refused the sign in: %s %s", providerError, ctx.Query("error_description")),
+		})
+
+		return
+	}
+
+	stateCookie, _ := ctx.Cookie(stateCookieName)
+	ctx.SetCookie(stateCookieName, "", -1, stateCookiePath, "", ctx.Request.TLS != nil, true)
+
+	claims, err := handler.keySet.VerifyTypedToken(stateTokenType, stateCookie)
+
+	if err != nil || claims["provider"] != provider.Name() || claims["state"] != ctx.Query("state") {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: "the sign in session is invalid or has expired, start again",
+		})
+
+		return
+	}
+
+	verifier, _ := claims["verifier"].(string)
+	nonce, _ := claims["nonce"].(string)
+	age, _ := claims["age"].(float64)
+
+	token, err := provider.Exchange(ctx.Request.Context(), ctx.Query("code"), verifier)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	idClaims, err := provider.VerifyIDToken(ctx.Request.Context(), token.IDToken, nonce)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	user := domain.User{Age: uint(age)}
+	external := domain.ExternalIdentity{
+		Provider:          provider.Name(),
+		Subject:           idClaims.Subject,
+		Email:             idClaims.Email,
+		EmailVerified:     idClaims.EmailVerified,
+		Name:              idClaims.Name,
+		PreferredUsername: idClaims.PreferredUsername,
+	}
+
+	if err = handler.authUseCase.SignInWithIdentity(ctx.Request.Context(), external, &user); err != nil {
+		if errors.Is(err, domain.ErrIdentityAgeRequired) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	mfaEnabled, err := handler.mfaUseCase.IsEnabled(ctx.Request.Context(), user.ID)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if mfaEnabled {
+		mfaToken, err := handler.keySet.GenerateMFAChallengeToken(user.ID, user.Email)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+				Status:  "unauthenticated",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, helpers.ResponseData{
+			Status: "mfa_required",
+			Data: utils.MFAChallenge{
+				MFAToken:  mfaToken,
+				ExpiresIn: int(helpers.MFAChallengeTokenTTL.Seconds()),
+			},
+		})
+
+		return
+	}
+
+	session, err := handler.sessionUseCase.Start(ctx.Request.Context(), user.ID, domain.LoginInfo{
+		IPAddress:  ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+		DeviceName: strings.TrimSpace(ctx.GetHeader("X-Device-Name")),
+	})
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	accessToken, err := handler.keySet.GenerateToken(user.ID, user.Email, session.ID)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data: utils.LoggedinUser{
+			Token: accessToken,
+		},
+	})
+}
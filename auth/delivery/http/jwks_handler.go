@@ -0,0 +1,30 @@
+package delivery
+
+import (
+	"api-mygram-go/helpers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jwksHandler struct {
+	keySet *helpers.KeySet
+}
+
+func NewJWKSHandler(routers *gin.Engine, keySet *helpers.KeySet) {
+	handler := &jwksHandler{keySet}
+
+	routers.GET("/.well-known/jwks.json", handler.JWKS)
+}
+
+// JWKS godoc
+// @Summary			Fetch token signing keys
+// @Description	Public keys, identified by kid, that verify tokens issued by MyGram
+// @Tags				auth
+// @Produce			json
+// @Success			200		{object}	helpers.JSONWebKeySet
+// @Router			/.well-known/jwks.json	[get]
+func (handler *jwksHandler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, handler.keySet.JWKS())
+}
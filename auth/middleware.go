@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/logging"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticator resolves the caller of a request from a bearer login token or
+// an API key.
+type Authenticator struct {
+	keySet         *helpers.KeySet
+	apiKeyUseCase  domain.APIKeyUseCase
+	sessionUseCase domain.SessionUseCase
+}
+
+// NewAuthenticator verifies login tokens with keySet and checks that their
+// session is still active with sessionUseCase.
+func NewAuthenticator(keySet *helpers.KeySet, apiKeyUseCase domain.APIKeyUseCase, sessionUseCase domain.SessionUseCase) *Authenticator {
+	return &Authenticator{keySet, apiKeyUseCase, sessionUseCase}
+}
+
+// Authentication accepts either a bearer login token or an API key and
+// stores the resolved Principal in the request context.
+func (authenticator *Authenticator) Authentication() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, err := authenticator.authenticate(ctx)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+				Status:  "unauthenticated",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		requestCtx := logging.With(ctx.Request.Context(), "user_id", principal.UserID)
+
+		if principal.APIKeyID != "" {
+			requestCtx = logging.With(requestCtx, "api_key_id", principal.APIKeyID)
+		}
+
+		ctx.Request = ctx.Request.WithContext(WithPrincipal(requestCtx, principal))
+		ctx.Next()
+	}
+}
+
+func (authenticator *Authenticator) authenticate(ctx *gin.Context) (Principal, error) {
+	if key := helpers.APIKeyFromRequest(ctx); key != "" {
+		apiKey, err := authenticator.apiKeyUseCase.Authenticate(ctx.Request.Context(), key)
+
+		if err != nil {
+			return Principal{}, err
+		}
+
+		return Principal{
+			UserID:   apiKey.UserID,
+			APIKeyID: apiKey.ID,
+			Scopes:   apiKey.ScopeList(),
+		}, nil
+	}
+
+	claims, err := authenticator.keySet.VerifyToken(ctx)
+
+	if err != nil {
+		return Principal{}, err
+	}
+
+	principal := Principal{Scopes: domain.Scopes}
+	principal.UserID, _ = claims["id"].(string)
+	principal.Email, _ = claims["email"].(string)
+	principal.SessionID, _ = claims["sid"].(string)
+
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	}
+
+	// Every login token is bound to a session. One without was issued before
+	// sessions existed and couldn't be revoked.
+	if principal.UserID == "" || principal.SessionID == "" {
+		return Principal{}, errors.New("sign in to proceed")
+	}
+
+	if err = authenticator.sessionUseCase.Validate(ctx.Request.Context(), principal.SessionID); err != nil {
+		return Principal{}, err
+	}
+
+	return principal, nil
+}
+
+// Authenticated returns the caller of the request. When Authentication
+// didn't run it aborts with 401 and returns false, so handlers only need to
+// return.
+func Authenticated(ctx *gin.Context) (Principal, bool) {
+	principal, ok := PrincipalFrom(ctx.Request.Context())
+
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: "sign in to proceed",
+		})
+	}
+
+	return principal, ok
+}
+
+// RequireScope stops the request unless the principal was granted scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := Authenticated(ctx)
+
+		if !ok {
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, helpers.ResponseMessage{
+				Status:  "forbidden",
+				Message: fmt.Sprintf("this action requires the %s scope, which your credentials weren't granted", scope),
+			})
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireInteractive stops requests made with an API key, so a leaked key
+// can't be used to mint keys or sign devices out.
+func RequireInteractive() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := Authenticated(ctx)
+
+		if !ok {
+			return
+		}
+
+		if !principal.Interactive() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, helpers.ResponseMessage{
+				Status:  "forbidden",
+				Message: "this action requires a login token, api keys aren't accepted",
+			})
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package oidc
+
+import "api-mygram-go/config"
+
+// ProvidersFromConfig creates a provider for every configured OIDC provider.
+func ProvidersFromConfig(cfg config.OIDC) []*Provider {
+	var providers []*Provider
+
+	for _, provider := range cfg.Providers {
+		providers = append(providers, NewProvider(Config{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		}, nil))
+	}
+
+	return providers
+}
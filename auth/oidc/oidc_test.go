@@ -0,0 +1,160 @@
+package oidc_test
+
+import (
+	"api-mygram-go/auth/oidc"
+	"api-mygram-go/auth/oidc/oidctest"
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newIssuer(t *testing.T) *oidctest.Issuer {
+	t.Helper()
+
+	issuer, err := oidctest.NewIssuer("mygram", oidctest.User{
+		Subject:       "subject-1",
+		Email:         "johndoe@example.com",
+		EmailVerified: true,
+		Name:          "John Doe",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(issuer.Close)
+
+	return issuer
+}
+
+// authorize follows the issuer's authorization endpoint and returns the
+// query of the redirect back to MyGram.
+func authorize(t *testing.T, provider *oidc.Provider, state, nonce, verifier string) url.Values {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	response, err := client.Get(authURL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusFound {
+		t.Fatalf("authorize responded with %d", response.StatusCode)
+	}
+
+	location, err := url.Parse(response.Header.Get("Location"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query()
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	issuer := newIssuer(t)
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "mock",
+		Issuer:      issuer.URL(),
+		ClientID:    "mygram",
+		RedirectURL: "http://localhost:8080/auth/mock/callback",
+	}, nil)
+
+	verifier, _ := oidc.NewCodeVerifier()
+	callback := authorize(t, provider, "state-1", "nonce-1", verifier)
+
+	if callback.Get("state") != "state-1" {
+		t.Fatalf("state = %q, want state-1", callback.Get("state"))
+	}
+
+	token, err := provider.Exchange(context.Background(), callback.Get("code"), verifier)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := provider.VerifyIDToken(context.Background(), token.IDToken, "nonce-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "subject-1" || claims.Email != "johndoe@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	issuer := newIssuer(t)
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "mock",
+		Issuer:      issuer.URL(),
+		ClientID:    "mygram",
+		RedirectURL: "http://localhost:8080/auth/mock/callback",
+	}, nil)
+
+	verifier, _ := oidc.NewCodeVerifier()
+	otherVerifier, _ := oidc.NewCodeVerifier()
+	callback := authorize(t, provider, "state-1", "nonce-1", verifier)
+
+	if _, err := provider.Exchange(context.Background(), callback.Get("code"), otherVerifier); err == nil {
+		t.Fatal("expected the exchange to fail with a mismatched code verifier")
+	}
+}
+
+func TestVerifyIDTokenRejectsWrongNonceAndAudience(t *testing.T) {
+	issuer := newIssuer(t)
+
+	tests := []struct {
+		name     string
+		clientID string
+		nonce    string
+	}{
+		{"wrong nonce", "mygram", "other-nonce"},
+		{"wrong audience", "other-client", "nonce-1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := oidc.NewProvider(oidc.Config{
+				Name:        "mock",
+				Issuer:      issuer.URL(),
+				ClientID:    "mygram",
+				RedirectURL: "http://localhost:8080/auth/mock/callback",
+			}, nil)
+
+			verifier, _ := oidc.NewCodeVerifier()
+			callback := authorize(t, provider, "state-1", "nonce-1", verifier)
+
+			token, err := provider.Exchange(context.Background(), callback.Get("code"), verifier)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			verifying := oidc.NewProvider(oidc.Config{
+				Name:        "mock",
+				Issuer:      issuer.URL(),
+				ClientID:    test.clientID,
+				RedirectURL: "http://localhost:8080/auth/mock/callback",
+			}, nil)
+
+			if _, err := verifying.VerifyIDToken(context.Background(), token.IDToken, test.nonce); err == nil {
+				t.Fatal("expected the id token to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package oidc
+
+import (
+	"api-mygram-go/helpers"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var ErrInvalidIDToken = errors.New("the identity provider returned an invalid id token")
+
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Discovery is the subset of the OpenID provider metadata used by MyGram.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type IDTokenClaims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Provider runs the authorization code flow with PKCE against one OpenID
+// Connect issuer. Metadata and signing keys are fetched lazily and cached so
+// an unreachable provider doesn't prevent the API from starting.
+type Provider struct {
+	config     Config
+	httpClient *http.Client
+
+	mutex     sync.Mutex
+	discovery *Discovery
+	keys      helpers.JSONWebKeySet
+	keysAt    time.Time
+}
+
+func NewProvider(config Config, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{config: config, httpClient: httpClient}
+}
+
+func (provider *Provider) Name() string {
+	return provider.config.Name
+}
+
+func (provider *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if provider.discovery != nil {
+		return provider.discovery, nil
+	}
+
+	discovery := &Discovery{}
+	wellKnown := strings.TrimSuffix(provider.config.Issuer, "/") + "/.well-known/openid-configuration"
+
+	if err := provider.getJSON(ctx, wellKnown, discovery); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(provider.config.Issuer, "/") {
+		return nil, fmt.Errorf("issuer mismatch: expected %q, got %q", provider.config.Issuer, discovery.Issuer)
+	}
+
+	provider.discovery = discovery
+
+	return discovery, nil
+}
+
+// AuthCodeURL returns the URL the user agent is redirected to, carrying state,
+// nonce and the S256 challenge of codeVerifier.
+func (provider *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := provider.Discover(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.config.ClientID)
+	query.Set("redirect_uri", provider.config.RedirectURL)
+	query.Set("scope", strings.Join(provider.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (provider *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	discovery, err := provider.Discover(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.config.RedirectURL)
+	form.Set("client_id", provider.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	if provider.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(provider.config.ClientID), url.QueryEscape(provider.config.ClientSecret))
+	}
+
+	response, err := provider.httpClient.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint responded with %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	token := &TokenResponse{}
+
+	if err = json.Unmarshal(body, token); err != nil {
+		return nil, err
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("the identity provider didn't return an id token")
+	}
+
+	return token, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// rawIDToken and returns its identity claims.
+func (provider *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (claims IDTokenClaims, err error) {
+	discovery, err := provider.Discover(ctx)
+
+	if err != nil {
+		return claims, err
+	}
+
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, ErrInvalidIDToken
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		return provider.publicKey(ctx, discovery, kid)
+	})
+
+	if err != nil || !token.Valid {
+		return claims, ErrInvalidIDToken
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return claims, ErrInvalidIDToken
+	}
+
+	if _, ok := mapClaims["exp"]; !ok {
+		return claims, ErrInvalidIDToken
+	}
+
+	if iss, _ := mapClaims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(discovery.Issuer, "/") {
+		return claims, ErrInvalidIDToken
+	}
+
+	if !hasAudience(mapClaims["aud"], provider.config.ClientID) {
+		return claims, ErrInvalidIDToken
+	}
+
+	if tokenNonce, _ := mapClaims["nonce"].(string); tokenNonce != nonce {
+		return claims, ErrInvalidIDToken
+	}
+
+	claims.Subject, _ = mapClaims["sub"].(string)
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.Name, _ = mapClaims["name"].(string)
+	claims.PreferredUsername, _ = mapClaims["preferred_username"].(string)
+
+	switch verified := mapClaims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	}
+
+	if claims.Subject == "" {
+		return claims, ErrInvalidIDToken
+	}
+
+	return claims, nil
+}
+
+func (provider *Provider) publicKey(ctx context.Context, discovery *Discovery, kid string) (interface{}, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	key, ok := provider.keys.Key(kid)
+
+	// Refetch the key set when the key is unknown, which is how providers
+	// announce rotations, but not more than once a minute.
+	if !ok && time.Since(provider.keysAt) > time.Minute {
+		keys := helpers.JSONWebKeySet{}
+
+		if err := provider.getJSON(ctx, discovery.JWKSURI, &keys); err != nil {
+			return nil, err
+		}
+
+		provider.keys = keys
+		provider.keysAt = time.Now()
+		key, ok = provider.keys.Key(kid)
+	}
+
+	if !ok && kid == "" && len(provider.keys.Keys) == 1 {
+		key, ok = provider.keys.Keys[0], true
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key.PublicKey()
+}
+
+func (provider *Provider) getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := provider.httpClient.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with %d", endpoint, response.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(target)
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, value := range aud {
+			if value == clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"api-mygram-go/helpers"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnerLoader returns the id of the user who owns the resource with id.
+type OwnerLoader func(ctx context.Context, id string) (ownerID string, err error)
+
+// Ownership stops the request unless the principal owns the resource named by
+// the path parameter param. resource names the resource in error messages.
+func Ownership(resource string, param string, load OwnerLoader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := Authenticated(ctx)
+
+		if !ok {
+			return
+		}
+
+		id := ctx.Param(param)
+		ownerID, err := load(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: fmt.Sprintf("%s with id %s doesn't exist", resource, id),
+			})
+
+			return
+		}
+
+		if ownerID != principal.UserID {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+				Status:  "unauthorized",
+				Message: fmt.Sprintf("you don't have permission to view or edit this %s", resource),
+			})
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,44 @@
+// Package auth resolves who is calling the API and what they may do. The
+// Authentication middleware stores a Principal in the request context, which
+// handlers read back with Authenticated.
+package auth
+
+import "context"
+
+// Principal is the caller of a request, authenticated either with a login
+// token or an API key.
+type Principal struct {
+	UserID    string
+	Email     string
+	SessionID string
+	APIKeyID  string
+	Scopes    []string
+}
+
+func (principal Principal) HasScope(scope string) bool {
+	for _, s := range principal.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Interactive reports whether the principal signed in with a login token
+// rather than an API key.
+func (principal Principal) Interactive() bool {
+	return principal.APIKeyID == ""
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+
+	return principal, ok && principal.UserID != ""
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) *identityRepository {
+	return &identityRepository{db}
+}
+
+func (identityRepository *identityRepository) GetByProviderSubject(ctx context.Context, identity *domain.Identity, provider string, subject string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, identityRepository.db).Where("provider = ? AND subject = ?", provider, subject).Take(&identity).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (identityRepository *identityRepository) Store(ctx context.Context, identity *domain.Identity) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	identity.ID = fmt.Sprintf("identity-%s", ID)
+
+	if err = database.FromContext(ctx, identityRepository.db).Create(&identity).Error; err != nil {
+		return err
+	}
+
+	return
+}
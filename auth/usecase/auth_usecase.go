@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/metrics"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+var usernameSanitizer = regexp.MustCompile(`[^a-z0-9_.]+`)
+
+type authUseCase struct {
+	identityRepository domain.IdentityRepository
+	userRepository     domain.UserRepository
+}
+
+func NewAuthUseCase(identityRepository domain.IdentityRepository, userRepository domain.UserRepository) *authUseCase {
+	return &authUseCase{identityRepository, userRepository}
+}
+
+// SignInWithIdentity resolves the MyGram account of an external identity. A
+// known identity signs in its linked user. Otherwise the identity is linked to
+// the account with the same verified email, or a new account is created when
+// user carries the age required by registration.
+func (authUseCase *authUseCase) SignInWithIdentity(ctx context.Context, external domain.ExternalIdentity, user *domain.User) (err error) {
+	identity := domain.Identity{}
+
+	err = authUseCase.identityRepository.GetByProviderSubject(ctx, &identity, external.Provider, external.Subject)
+
+	if err == nil {
+		return authUseCase.userRepository.GetByID(ctx, user, identity.UserID)
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	existing := domain.User{}
+
+	if external.Email != "" && external.EmailVerified {
+		if err = authUseCase.userRepository.GetByEmail(ctx, &existing, external.Email); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	if existing.ID == "" {
+		if user.Age == 0 {
+			return domain.ErrIdentityAgeRequired
+		}
+
+		if external.Email == "" || !external.EmailVerified {
+			return errors.New("the identity provider didn't share a verified email address")
+		}
+
+		password, err := gonanoid.New(32)
+
+		if err != nil {
+			return err
+		}
+
+		existing = domain.User{
+			Username: usernameFor(external),
+			Email:    external.Email,
+			Password: password,
+			Age:      user.Age,
+		}
+
+		if err = authUseCase.userRepository.Register(ctx, &existing); err != nil {
+			return err
+		}
+
+		metrics.UsersRegistered.Inc()
+	}
+
+	identity = domain.Identity{
+		UserID:   existing.ID,
+		Provider: external.Provider,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}
+
+	if err = authUseCase.identityRepository.Store(ctx, &identity); err != nil {
+		return err
+	}
+
+	*user = existing
+
+	return
+}
+
+func usernameFor(external domain.ExternalIdentity) string {
+	base := external.PreferredUsername
+
+	if base == "" {
+		base = strings.SplitN(external.Email, "@", 2)[0]
+	}
+
+	base = usernameSanitizer.ReplaceAllString(strings.ToLower(base), "")
+
+	if base == "" {
+		base = "user"
+	}
+
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	suffix, _ := gonanoid.Generate("abcdefghijklmnopqrstuvwxyz0123456789", 6)
+
+	return base + "_" + suffix
+}
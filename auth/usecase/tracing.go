@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/tracing"
+	"context"
+)
+
+type tracedAuthUseCase struct {
+	next domain.AuthUseCase
+}
+
+// NewTracedAuthUseCase wraps next so every call is recorded as a span.
+func NewTracedAuthUseCase(next domain.AuthUseCase) *tracedAuthUseCase {
+	return &tracedAuthUseCase{next}
+}
+
+func (traced *tracedAuthUseCase) SignInWithIdentity(ctx context.Context, identity domain.ExternalIdentity, user *domain.User) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthUseCase.SignInWithIdentity")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.SignInWithIdentity(ctx, identity, user)
+}
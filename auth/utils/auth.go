@@ -0,0 +1,34 @@
+package utils
+
+type Providers struct {
+	Providers []string `json:"providers" example:"google"`
+}
+
+type ResponseDataProviders struct {
+	Status string    `json:"status" example:"success"`
+	Data   Providers `json:"data"`
+}
+
+type LoggedinUser struct {
+	Token string `json:"token" example:"the token generated here"`
+}
+
+type ResponseDataLoggedinUser struct {
+	Status string       `json:"status" example:"success"`
+	Data   LoggedinUser `json:"data"`
+}
+
+type MFAChallenge struct {
+	MFAToken  string `json:"mfa_token" example:"the challenge token generated here"`
+	ExpiresIn int    `json:"expires_in" example:"300"`
+}
+
+type ResponseDataMFAChallenge struct {
+	Status string       `json:"status" example:"mfa_required"`
+	Data   MFAChallenge `json:"data"`
+}
+
+type ResponseMessage struct {
+	Status string `json:"status" example:"fail"`
+	Data   string `json:"data" example:"the error explained here"`
+}
@@ -1,14 +1,14 @@
 package delivery
 
 import (
-	"fmt"
-	"api-mygram-go/comment/delivery/http/middleware"
+	"api-mygram-go/auth"
 	"api-mygram-go/comment/utils"
 	"api-mygram-go/domain"
 	"api-mygram-go/helpers"
+	"context"
+	"fmt"
 	"net/http"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,17 +17,28 @@ type commentHandler struct {
 	photoUseCase   domain.PhotoUseCase
 }
 
-func NewCommentHandler(routers *gin.Engine, commentUseCase domain.CommentUseCase, photoUseCase domain.PhotoUseCase) {
+func NewCommentHandler(routers *gin.Engine, commentUseCase domain.CommentUseCase, photoUseCase domain.PhotoUseCase, authenticator *auth.Authenticator) {
 	handler := &commentHandler{commentUseCase, photoUseCase}
 
 	router := routers.Group("/comments")
 	{
-		router.Use(middleware.Authentication())
-		router.GET("", handler.Fetch)
-		router.POST("", handler.Store)
-		router.PUT("/:commentId", middleware.Authorization(handler.commentUseCase), handler.Update)
-		router.DELETE("/:commentId", middleware.Authorization(handler.commentUseCase), handler.Delete)
+		router.Use(authenticator.Authentication())
+		router.GET("", auth.RequireScope(domain.ScopeCommentsRead), handler.Fetch)
+		router.POST("", auth.RequireScope(domain.ScopeCommentsWrite), handler.Store)
+		router.PUT("/:commentId", auth.RequireScope(domain.ScopeCommentsWrite), auth.Ownership("comment", "commentId", handler.commentOwner), handler.Update)
+		router.DELETE("/:commentId", auth.RequireScope(domain.ScopeCommentsWrite), auth.Ownership("comment", "commentId", handler.commentOwner), handler.Delete)
+	}
+}
+
+// commentOwner loads the owner of a Comment for auth.Ownership.
+func (handler *commentHandler) commentOwner(ctx context.Context, id string) (string, error) {
+	var comment domain.Comment
+
+	if err := handler.commentUseCase.GetByID(ctx, &comment, id); err != nil {
+		return "", err
 	}
+
+	return comment.UserID, nil
 }
 
 // Fetch godoc
@@ -39,6 +50,7 @@ func NewCommentHandler(routers *gin.Engine, commentUseCase domain.CommentUseCase
 // @Success     200	{object}	utils.ResponseDataFetchedComment
 // @Failure     400	{object}	utils.ResponseMessage
 // @Failure     401	{object}	utils.ResponseMessage
+// @Failure     403	{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /comments     [get]
 func (handler *commentHandler) Fetch(ctx *gin.Context) {
@@ -48,8 +60,13 @@ func (handler *commentHandler) Fetch(ctx *gin.Context) {
 		err error
 	)
 
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = handler.commentUseCase.Fetch(ctx.Request.Context(), &comments, userID); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -76,6 +93,7 @@ func (handler *commentHandler) Fetch(ctx *gin.Context) {
 // @Success     201		{object}  utils.ResponseDataAddedComment
 // @Failure     400		{object}	utils.ResponseMessage
 // @Failure     401		{object}	utils.ResponseMessage
+// @Failure     403		{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /comments	[post]
 func (handler *commentHandler) Store(ctx *gin.Context) {
@@ -85,8 +103,13 @@ func (handler *commentHandler) Store(ctx *gin.Context) {
 		err     error
 	)
 
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = ctx.ShouldBindJSON(&comment); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -142,6 +165,7 @@ func (handler *commentHandler) Store(ctx *gin.Context) {
 // @Success     200		{object}  utils.ResponseDataUpdatedComment
 // @Failure     400		{object}	utils.ResponseMessage
 // @Failure     401		{object}	utils.ResponseMessage
+// @Failure     403		{object}	utils.ResponseMessage
 // @Failure     404		{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /comments/{id}	[put]
@@ -153,8 +177,13 @@ func (handler *commentHandler) Update(ctx *gin.Context) {
 	)
 
 	commentID := ctx.Param("commentId")
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = ctx.ShouldBindJSON(&comment); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -202,6 +231,7 @@ func (handler *commentHandler) Update(ctx *gin.Context) {
 // @Success     200 {object}	utils.ResponseMessageDeletedComment
 // @Failure     400 {object}	utils.ResponseMessage
 // @Failure     401	{object}	utils.ResponseMessage
+// @Failure     403	{object}	utils.ResponseMessage
 // @Failure     404	{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /comments/{id}	[delete]
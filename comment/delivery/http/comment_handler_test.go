@@ -0,0 +1,214 @@
+package delivery_test
+
+import (
+	"api-mygram-go/auth"
+	commentDelivery "api-mygram-go/comment/delivery/http"
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var comments = map[string]domain.Comment{
+	"comment-1": {ID: "comment-1", UserID: "user-1", PhotoID: "photo-1", Message: "Nice shot"},
+	"comment-2": {ID: "comment-2", UserID: "user-2", PhotoID: "photo-1", Message: "Love it"},
+}
+
+func getComment(ctx context.Context, comment *domain.Comment, id string) error {
+	stored, ok := comments[id]
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*comment = stored
+
+	return nil
+}
+
+func getPhoto(ctx context.Context, photo *domain.Photo, id string) error {
+	if id != "photo-1" {
+		return gorm.ErrRecordNotFound
+	}
+
+	*photo = domain.Photo{ID: "photo-1", Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: "user-2"}
+
+	return nil
+}
+
+func TestCommentHandler(t *testing.T) {
+	readWrite := []string{domain.ScopeCommentsRead, domain.ScopeCommentsWrite}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		scopes     []string
+		useCase    fakes.CommentUseCase
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "fetch without credentials",
+			method:     http.MethodGet,
+			path:       "/comments",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "fetch with the photos scope only",
+			method:     http.MethodGet,
+			path:       "/comments",
+			scopes:     []string{domain.ScopePhotosRead},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "comments:read",
+		},
+		{
+			name:   "fetch returns the caller's comments",
+			method: http.MethodGet,
+			path:   "/comments",
+			scopes: readWrite,
+			useCase: fakes.CommentUseCase{FetchFunc: func(ctx context.Context, fetched *[]domain.Comment, userID string) error {
+				if userID != "user-1" {
+					return errors.New("fetched the comments of " + userID)
+				}
+
+				*fetched = []domain.Comment{comments["comment-1"]}
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"message":"Nice shot"`,
+		},
+		{
+			name:       "store on a missing photo",
+			method:     http.MethodPost,
+			path:       "/comments",
+			body:       `{"message":"Wow","photo_id":"photo-9"}`,
+			scopes:     readWrite,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "photo with id photo-9 doesn't exist",
+		},
+		{
+			name:   "store",
+			method: http.MethodPost,
+			path:   "/comments",
+			body:   `{"message":"Wow","photo_id":"photo-1"}`,
+			scopes: readWrite,
+			useCase: fakes.CommentUseCase{StoreFunc: func(ctx context.Context, comment *domain.Comment) error {
+				comment.ID = "comment-3"
+
+				return nil
+			}},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"user_id":"user-1"`,
+		},
+		{
+			name:   "store fails validation",
+			method: http.MethodPost,
+			path:   "/comments",
+			body:   `{"message":"","photo_id":"photo-1"}`,
+			scopes: readWrite,
+			useCase: fakes.CommentUseCase{StoreFunc: func(context.Context, *domain.Comment) error {
+				return errors.New("message: non zero value required")
+			}},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "non zero value required",
+		},
+		{
+			name:       "update a missing comment",
+			method:     http.MethodPut,
+			path:       "/comments/comment-9",
+			body:       `{"message":"Edited"}`,
+			scopes:     readWrite,
+			useCase:    fakes.CommentUseCase{GetByIDFunc: getComment},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "update someone else's comment",
+			method:     http.MethodPut,
+			path:       "/comments/comment-2",
+			body:       `{"message":"Edited"}`,
+			scopes:     readWrite,
+			useCase:    fakes.CommentUseCase{GetByIDFunc: getComment},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "update",
+			method: http.MethodPut,
+			path:   "/comments/comment-1",
+			body:   `{"message":"Edited"}`,
+			scopes: readWrite,
+			useCase: fakes.CommentUseCase{
+				GetByIDFunc: getComment,
+				UpdateFunc: func(ctx context.Context, comment domain.Comment, id string) (photo domain.Photo, err error) {
+					err = getPhoto(ctx, &photo, comments[id].PhotoID)
+
+					return photo, err
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"title":"Sunset"`,
+		},
+		{
+			name:       "delete",
+			method:     http.MethodDelete,
+			path:       "/comments/comment-1",
+			scopes:     readWrite,
+			useCase:    fakes.CommentUseCase{GetByIDFunc: getComment},
+			wantStatus: http.StatusOK,
+			wantBody:   "successfully deleted",
+		},
+		{
+			name:   "delete fails",
+			method: http.MethodDelete,
+			path:   "/comments/comment-1",
+			scopes: readWrite,
+			useCase: fakes.CommentUseCase{
+				GetByIDFunc: getComment,
+				DeleteFunc: func(context.Context, string) error {
+					return errors.New("connection refused")
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routers := gin.New()
+			useCase := test.useCase
+
+			commentDelivery.NewCommentHandler(routers, &useCase, &fakes.PhotoUseCase{GetByIDFunc: getPhoto}, auth.NewAuthenticator(fakes.KeySet(), fakes.AuthenticatingAPIKeys("user-1", test.scopes...), &fakes.SessionUseCase{}))
+
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			if test.scopes != nil {
+				request.Header.Set("X-API-Key", "mygram_test")
+			}
+
+			recorder := httptest.NewRecorder()
+			routers.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+
+			if !strings.Contains(recorder.Body.String(), test.wantBody) {
+				t.Errorf("body %s doesn't contain %s", recorder.Body, test.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+//go:build integration
+
+package repository_test
+
+import (
+	commentRepository "api-mygram-go/comment/repository/postgres"
+	"api-mygram-go/config/database/databasetest"
+	"api-mygram-go/domain"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	databasetest.Main(m)
+}
+
+func TestCommentRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := commentRepository.NewCommentRepository(db)
+	user := domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}
+	photo := domain.Photo{ID: "photo-1", Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: user.ID}
+
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Create(&photo).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	comment := domain.Comment{UserID: user.ID, PhotoID: photo.ID, Message: "Nice shot"}
+
+	if err := repository.Store(ctx, &comment); err != nil {
+		t.Fatal(err)
+	}
+
+	var comments []domain.Comment
+
+	if err := repository.Fetch(ctx, &comments, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(comments) != 1 || comments[0].Photo == nil || comments[0].Photo.Title != photo.Title || comments[0].User == nil {
+		t.Fatalf("fetched %+v, want the comment with its user and photo preloaded", comments)
+	}
+
+	if err := repository.Fetch(ctx, &comments, "user-2"); err != nil || len(comments) != 0 {
+		t.Errorf("fetched %d comments of another user, %v", len(comments), err)
+	}
+
+	if err := db.Create(&domain.Photo{ID: "photo-0", Title: "Sunrise", PhotoUrl: "https://example.com/sunrise.jpg", UserID: user.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	commented, err := repository.Update(ctx, domain.Comment{Message: "Great shot"}, comment.ID)
+
+	if err != nil || commented.ID != photo.ID {
+		t.Errorf("update returned photo %q, %v, want the commented photo %q", commented.ID, err, photo.ID)
+	}
+
+	if err := repository.Delete(ctx, comment.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.GetByID(ctx, &domain.Comment{}, comment.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleted comment is still found: %v", err)
+	}
+}
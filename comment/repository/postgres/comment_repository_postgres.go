@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"api-mygram-go/config/database"
 	"api-mygram-go/domain"
 	"time"
 
@@ -23,7 +24,7 @@ func (commentRepository *commentRepository) Fetch(ctx context.Context, comments
 
 	defer cancel()
 
-	if err = commentRepository.db.WithContext(ctx).Where("user_id = ?", userID).Preload("User", func(db *gorm.DB) *gorm.DB {
+	if err = database.FromContext(ctx, commentRepository.db).Where("user_id = ?", userID).Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id", "email", "username", "profile_image_url")
 	}).Preload("Photo", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id", "user_id", "title", "photo_url", "caption")
@@ -43,7 +44,7 @@ func (commentRepository *commentRepository) Store(ctx context.Context, comment *
 
 	comment.ID = fmt.Sprintf("comment-%s", ID)
 
-	if err = commentRepository.db.WithContext(ctx).Create(&comment).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).Create(&comment).Error; err != nil {
 		return err
 	}
 
@@ -55,7 +56,7 @@ func (commentRepository *commentRepository) GetByID(ctx context.Context, comment
 
 	defer cancel()
 
-	if err = commentRepository.db.WithContext(ctx).First(&comment, &id).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).First(&comment, &id).Error; err != nil {
 		return err
 	}
 
@@ -71,15 +72,15 @@ func (commentRepository *commentRepository) Update(ctx context.Context, comment
 
 	photo = domain.Photo{}
 
-	if err = commentRepository.db.WithContext(ctx).First(&c, &id).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).First(&c, &id).Error; err != nil {
 		return photo, err
 	}
 
-	if err = commentRepository.db.WithContext(ctx).Model(&c).Updates(comment).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).Model(&c).Updates(comment).Error; err != nil {
 		return photo, err
 	}
 
-	if err = commentRepository.db.WithContext(ctx).First(&photo, comment.PhotoID).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).First(&photo, "id = ?", c.PhotoID).Error; err != nil {
 		return photo, err
 	}
 
@@ -91,11 +92,11 @@ func (commentRepository *commentRepository) Delete(ctx context.Context, id strin
 
 	defer cancel()
 
-	if err = commentRepository.db.WithContext(ctx).First(&domain.Comment{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).First(&domain.Comment{}, &id).Error; err != nil {
 		return err
 	}
 
-	if err = commentRepository.db.WithContext(ctx).Delete(&domain.Comment{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, commentRepository.db).Delete(&domain.Comment{}, &id).Error; err != nil {
 		return err
 	}
 
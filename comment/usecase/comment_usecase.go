@@ -1,16 +1,18 @@
 package usecase
 
 import (
-	"context"
 	"api-mygram-go/domain"
+	"api-mygram-go/metrics"
+	"context"
 )
 
 type commentUseCase struct {
 	commentRepository domain.CommentRepository
+	txManager         domain.TxManager
 }
 
-func NewCommentUseCase(commentRepository domain.CommentRepository) *commentUseCase {
-	return &commentUseCase{commentRepository}
+func NewCommentUseCase(commentRepository domain.CommentRepository, txManager domain.TxManager) *commentUseCase {
+	return &commentUseCase{commentRepository, txManager}
 }
 
 func (commentUseCase *commentUseCase) Fetch(ctx context.Context, comments *[]domain.Comment, userID string) (err error) {
@@ -26,6 +28,8 @@ func (commentUseCase *commentUseCase) Store(ctx context.Context, comment *domain
 		return err
 	}
 
+	metrics.CommentsPosted.Inc()
+
 	return
 }
 
@@ -38,11 +42,13 @@ func (commentUseCase *commentUseCase) GetByID(ctx context.Context, comment *doma
 }
 
 func (commentUseCase *commentUseCase) Update(ctx context.Context, comment domain.Comment, id string) (photo domain.Photo, err error) {
-	if photo, err = commentUseCase.commentRepository.Update(ctx, comment, id); err != nil {
-		return photo, err
-	}
+	err = commentUseCase.txManager.Do(ctx, func(ctx context.Context) (err error) {
+		photo, err = commentUseCase.commentRepository.Update(ctx, comment, id)
+
+		return err
+	})
 
-	return photo, nil
+	return photo, err
 }
 
 func (commentUseCase *commentUseCase) Delete(ctx context.Context, id string) (err error) {
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/tracing"
+	"context"
+)
+
+type tracedCommentUseCase struct {
+	next domain.CommentUseCase
+}
+
+// NewTracedCommentUseCase wraps next so every call is recorded as a span.
+func NewTracedCommentUseCase(next domain.CommentUseCase) *tracedCommentUseCase {
+	return &tracedCommentUseCase{next}
+}
+
+func (traced *tracedCommentUseCase) Fetch(ctx context.Context, comments *[]domain.Comment, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "CommentUseCase.Fetch")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Fetch(ctx, comments, userID)
+}
+
+func (traced *tracedCommentUseCase) Store(ctx context.Context, comment *domain.Comment) (err error) {
+	ctx, span := tracing.Start(ctx, "CommentUseCase.Store")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Store(ctx, comment)
+}
+
+func (traced *tracedCommentUseCase) GetByID(ctx context.Context, comment *domain.Comment, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "CommentUseCase.GetByID")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.GetByID(ctx, comment, id)
+}
+
+func (traced *tracedCommentUseCase) Update(ctx context.Context, comment domain.Comment, id string) (p domain.Photo, err error) {
+	ctx, span := tracing.Start(ctx, "CommentUseCase.Update")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Update(ctx, comment, id)
+}
+
+func (traced *tracedCommentUseCase) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "CommentUseCase.Delete")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Delete(ctx, id)
+}
@@ -3,9 +3,10 @@ package utils
 import "time"
 
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	ID              string `json:"id"`
+	Username        string `json:"username"`
+	Email           string `json:"email"`
+	ProfileImageUrl string `json:"profileImageUrl,omitempty" example:"/avatars/user-1/k3j5h2g8d9s1-256.jpg"`
 }
 
 type Photo struct {
@@ -0,0 +1,276 @@
+// Package config loads MyGram's settings from environment variables, an
+// optional .env file and an optional YAML file, in that order of precedence.
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Env      string   `yaml:"env" env:"ENV"`
+	HTTP     HTTP     `yaml:"http"`
+	Database Database `yaml:"database"`
+	JWT      JWT      `yaml:"jwt"`
+	Password Password `yaml:"password"`
+	TOTP     TOTP     `yaml:"totp"`
+	OIDC     OIDC     `yaml:"oidc"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Logging  Logging  `yaml:"logging"`
+	Storage  Storage  `yaml:"storage"`
+	Accounts Accounts `yaml:"accounts"`
+	Exports  Exports  `yaml:"exports"`
+	Mail     Mail     `yaml:"mail"`
+	Photos   Photos   `yaml:"photos"`
+}
+
+// HTTP configures the API server. It listens on Host, or every interface
+// when Host is empty.
+type HTTP struct {
+	Host              string        `yaml:"host" env:"HOST"`
+	Port              int           `yaml:"port" env:"PORT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	TLSCertFile       string        `yaml:"tls_cert_file" env:"HTTP_TLS_CERT_FILE"`
+	TLSKeyFile        string        `yaml:"tls_key_file" env:"HTTP_TLS_KEY_FILE"`
+}
+
+func (http HTTP) TLS() bool {
+	return http.TLSCertFile != "" && http.TLSKeyFile != ""
+}
+
+// Database selects the driver with Driver: "postgres" connects with the PG
+// settings, "sqlite" opens SQLitePath, or an in-memory database when it is
+// ":memory:".
+type Database struct {
+	Driver     string `yaml:"driver" env:"DB_DRIVER"`
+	SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH"`
+	Host       string `yaml:"host" env:"PGHOST"`
+	User       string `yaml:"user" env:"PGUSER"`
+	Password   string `yaml:"password" env:"PGPASSWORD"`
+	Name       string `yaml:"name" env:"PGDBNAME"`
+	Port       int    `yaml:"port" env:"PGPORT"`
+	TimeZone   string `yaml:"time_zone" env:"TIMEZONE"`
+	SSLMode    string `yaml:"ssl_mode" env:"PGSSLMODE"`
+}
+
+// DSN returns the connection string for the postgres driver.
+func (database Database) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s", database.Host, database.User, database.Password, database.Name, database.Port, database.SSLMode, database.TimeZone)
+}
+
+type JWT struct {
+	Algorithm string        `yaml:"algorithm" env:"JWT_ALGORITHM"`
+	TokenKey  string        `yaml:"token_key" env:"TOKEN_KEY"`
+	Keys      JWTKeys       `yaml:"keys" env:"JWT_KEYS"`
+	ActiveKID string        `yaml:"active_kid" env:"JWT_ACTIVE_KID"`
+	Issuer    string        `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience  string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	TTL       time.Duration `yaml:"ttl" env:"JWT_TTL"`
+}
+
+// JWTKey points at the PEM file of an RS256 or EdDSA signing key.
+type JWTKey struct {
+	ID   string `yaml:"kid"`
+	Path string `yaml:"path"`
+}
+
+// JWTKeys reads JWT_KEYS, a comma separated list of kid=path entries.
+type JWTKeys []JWTKey
+
+func (keys *JWTKeys) DecodeEnv(value string) error {
+	*keys = nil
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		kid, path, found := strings.Cut(entry, "=")
+
+		if !found || kid == "" || path == "" {
+			return fmt.Errorf("%q isn't a kid=path entry", entry)
+		}
+
+		*keys = append(*keys, JWTKey{ID: kid, Path: path})
+	}
+
+	return nil
+}
+
+type Password struct {
+	Hasher        string `yaml:"hasher" env:"PASSWORD_HASHER"`
+	BcryptCost    int    `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+	Argon2Time    uint32 `yaml:"argon2_time" env:"ARGON2_TIME"`
+	Argon2Memory  uint32 `yaml:"argon2_memory" env:"ARGON2_MEMORY"`
+	Argon2Threads uint8  `yaml:"argon2_threads" env:"ARGON2_THREADS"`
+}
+
+// TOTP names the issuer shown by authenticator apps. The secrets are stored
+// encrypted with EncryptionKey, so changing it disables every enrolled
+// authenticator.
+type TOTP struct {
+	Issuer        string `yaml:"issuer" env:"TOTP_ISSUER"`
+	EncryptionKey string `yaml:"encryption_key" env:"TOTP_ENCRYPTION_KEY"`
+}
+
+// OIDC lists the sign in providers. OIDC_PROVIDERS names them, e.g.
+// "google,gitlab", and each reads OIDC_<NAME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL and _SCOPES.
+type OIDC struct {
+	Providers []OIDCProvider `yaml:"providers"`
+}
+
+type OIDCProvider struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer" env:"ISSUER"`
+	ClientID     string   `yaml:"client_id" env:"CLIENT_ID"`
+	ClientSecret string   `yaml:"client_secret" env:"CLIENT_SECRET"`
+	RedirectURL  string   `yaml:"redirect_url" env:"REDIRECT_URL"`
+	Scopes       []string `yaml:"scopes" env:"SCOPES"`
+}
+
+// EnvPrefix returns the prefix of the provider's environment variables.
+func (provider OIDCProvider) EnvPrefix() string {
+	return "OIDC_" + strings.ToUpper(strings.ReplaceAll(provider.Name, "-", "_")) + "_"
+}
+
+// Tracing exports OpenTelemetry spans over OTLP/HTTP when Endpoint is set,
+// e.g. "localhost:4318".
+type Tracing struct {
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+func (tracing Tracing) Enabled() bool {
+	return tracing.Endpoint != ""
+}
+
+// Logging writes JSON logs at Level or above. Queries slower than
+// SlowQueryThreshold are logged as warnings.
+type Logging struct {
+	Level              string        `yaml:"level" env:"LOG_LEVEL"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"LOG_SLOW_QUERY_THRESHOLD"`
+}
+
+// Storage keeps uploaded and generated files under Dir.
+type Storage struct {
+	Dir string `yaml:"dir" env:"STORAGE_DIR"`
+}
+
+// Accounts controls account deletion: an account is purged
+// DeletionGracePeriod after its owner asks, unless they cancel first. Due
+// deletions are looked for every PurgeInterval.
+//
+// A new email address is confirmed with a link valid for
+// EmailVerificationTTL. The link is EmailVerificationURL with a token query
+// parameter, or only the token when it is empty.
+type Accounts struct {
+	DeletionGracePeriod  time.Duration `yaml:"deletion_grace_period" env:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	PurgeInterval        time.Duration `yaml:"purge_interval" env:"ACCOUNT_PURGE_INTERVAL"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl" env:"EMAIL_VERIFICATION_TTL"`
+	EmailVerificationURL string        `yaml:"email_verification_url" env:"EMAIL_VERIFICATION_URL"`
+}
+
+// Exports controls personal data exports. Archives are kept for Retention
+// once ready, and their download links stay valid for LinkTTL. Links are
+// signed with SigningKey, or with a random key of the process when it is
+// empty, which invalidates them on restart.
+type Exports struct {
+	Retention  time.Duration `yaml:"retention" env:"EXPORT_RETENTION"`
+	LinkTTL    time.Duration `yaml:"link_ttl" env:"EXPORT_LINK_TTL"`
+	SigningKey string        `yaml:"signing_key" env:"EXPORT_SIGNING_KEY"`
+}
+
+// Mail sends account emails from From through the SMTP server at SMTPHost.
+// Without a server they are written to the log, which only suits development.
+type Mail struct {
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+}
+
+// Photos controls how the images of photos given by URL are fetched to read
+// their metadata. A fetch taking longer than FetchTimeout fails.
+type Photos struct {
+	FetchTimeout time.Duration `yaml:"fetch_timeout" env:"PHOTO_FETCH_TIMEOUT"`
+}
+
+// Default returns the settings used for anything left unconfigured.
+func Default() Config {
+	return Config{
+		Env: "development",
+		HTTP: HTTP{
+			Port:              8080,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Database: Database{
+			Driver:     "postgres",
+			SQLitePath: "mygram.db",
+			Host:       "localhost",
+			Port:       5432,
+			TimeZone:   "UTC",
+		},
+		JWT: JWT{
+			Algorithm: "HS256",
+			Issuer:    "mygram",
+			Audience:  "mygram",
+			TTL:       24 * time.Hour,
+		},
+		Password: Password{
+			Hasher:        "bcrypt",
+			BcryptCost:    10,
+			Argon2Time:    1,
+			Argon2Memory:  64 * 1024,
+			Argon2Threads: 4,
+		},
+		TOTP: TOTP{
+			Issuer: "MyGram",
+		},
+		Tracing: Tracing{
+			ServiceName: "mygram",
+			SampleRatio: 1,
+		},
+		Logging: Logging{
+			Level:              "info",
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Storage: Storage{
+			Dir: "data/blobs",
+		},
+		Accounts: Accounts{
+			DeletionGracePeriod:  30 * 24 * time.Hour,
+			PurgeInterval:        time.Hour,
+			EmailVerificationTTL: 24 * time.Hour,
+		},
+		Exports: Exports{
+			Retention: 7 * 24 * time.Hour,
+			LinkTTL:   15 * time.Minute,
+		},
+		Mail: Mail{
+			From:     "MyGram <no-reply@localhost>",
+			SMTPPort: 587,
+		},
+		Photos: Photos{
+			FetchTimeout: 15 * time.Second,
+		},
+	}
+}
+
+func (config Config) Production() bool {
+	return config.Env == "production"
+}
@@ -0,0 +1,216 @@
+// Package databasetest runs tests against a real database. By default every
+// test gets an in-memory SQLite database, which needs no server at all. With
+// TEST_DATABASE_DSN, or TEST_DATABASE_DRIVER=postgres, they run against
+// PostgreSQL instead: Main uses the server named by the DSN or starts an
+// embedded one for the test binary, and Open gives every test a schema of
+// its own.
+//
+// The repository integration tests are built with the integration tag:
+//
+//	go test -tags integration ./...
+//	TEST_DATABASE_DRIVER=postgres go test -tags integration ./...
+package databasetest
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/config/database"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// DSNEnv names a key=value connection string of an existing server to
+	// use instead of starting one, e.g. "host=localhost user=postgres".
+	DSNEnv = "TEST_DATABASE_DSN"
+	// DriverEnv selects sqlite or postgres. It defaults to postgres when
+	// DSNEnv is set and to sqlite otherwise.
+	DriverEnv = "TEST_DATABASE_DRIVER"
+)
+
+var (
+	dsn         string
+	driver      string
+	nonSchemaID = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// Main starts PostgreSQL if needed, runs the tests and stops it again. Call
+// it from TestMain.
+func Main(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	dsn = os.Getenv(DSNEnv)
+
+	switch driver = os.Getenv(DriverEnv); driver {
+	case "":
+		if dsn == "" {
+			driver = "sqlite"
+
+			return m.Run()
+		}
+
+		driver = "postgres"
+	case "postgres":
+	case "sqlite":
+		return m.Run()
+	default:
+		log.Printf("%s must be postgres or sqlite, got %q", DriverEnv, driver)
+
+		return 1
+	}
+
+	if dsn != "" {
+		return m.Run()
+	}
+
+	port, err := freePort()
+
+	if err != nil {
+		log.Print("Error finding a port for postgres: ", err)
+
+		return 1
+	}
+
+	runtimePath, err := os.MkdirTemp("", "mygram-postgres-")
+
+	if err != nil {
+		log.Print("Error creating the postgres directory: ", err)
+
+		return 1
+	}
+
+	defer os.RemoveAll(runtimePath)
+
+	server := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(port).
+		Database("mygram").
+		RuntimePath(filepath.Join(runtimePath, "runtime")).
+		DataPath(filepath.Join(runtimePath, "data")).
+		Logger(nil))
+
+	if err = server.Start(); err != nil {
+		log.Print("Error starting postgres, set ", DSNEnv, " to use a running server: ", err)
+
+		return 1
+	}
+
+	defer func() {
+		if err := server.Stop(); err != nil {
+			log.Print("Error stopping postgres: ", err)
+		}
+	}()
+
+	dsn = fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=mygram sslmode=disable TimeZone=UTC", port)
+
+	return m.Run()
+}
+
+// Open returns a connection whose search path is a new schema holding every
+// migrated model. The schema is dropped when t finishes, so tests can run in
+// parallel without seeing each other's rows.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	if driver == "sqlite" {
+		return openSQLite(t)
+	}
+
+	if dsn == "" {
+		t.Fatal("databasetest.Main must run before Open, call it from TestMain")
+	}
+
+	admin := open(t, dsn)
+	suffix, _ := gonanoid.Generate("abcdefghijklmnopqrstuvwxyz0123456789", 8)
+	name := nonSchemaID.ReplaceAllString(strings.ToLower(t.Name()), "_")
+
+	if len(name) > 40 {
+		name = name[:40]
+	}
+
+	schema := fmt.Sprintf("test_%s_%s", name, suffix)
+
+	if err := admin.Exec(fmt.Sprintf(`CREATE SCHEMA "%s"`, schema)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := admin.Exec(fmt.Sprintf(`DROP SCHEMA "%s" CASCADE`, schema)).Error; err != nil {
+			t.Error(err)
+		}
+	})
+
+	db := open(t, fmt.Sprintf("%s search_path=%s", dsn, schema))
+
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// openSQLite returns a new in-memory database, which lives as long as its
+// single connection.
+func openSQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(config.Database{Driver: "sqlite", SQLitePath: ":memory:"}, &gorm.Config{FullSaveAssociations: true, Logger: logger.Discard})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err = db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func open(t testing.TB, dsn string) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{FullSaveAssociations: true, Logger: logger.Discard})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return db
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer listener.Close()
+
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
@@ -1,42 +1,86 @@
 package database
 
 import (
+	"api-mygram-go/config"
+	"api-mygram-go/domain"
 	"fmt"
 	"log"
-	"api-mygram-go/domain"
-	"os"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-func StartDB() *gorm.DB {
+func StartDB(cfg config.Database, gormLogger logger.Interface) *gorm.DB {
 	var (
-		env      = os.Getenv("ENV")
-		host     = os.Getenv("PGHOST")
-		user     = os.Getenv("PGUSER")
-		password = os.Getenv("PGPASSWORD")
-		dbname   = os.Getenv("PGDBNAME")
-		port     = os.Getenv("PGPORT")
-		timeZone = os.Getenv("TIMEZONE")
-		dsn      = ""
-		db       *gorm.DB
-		err      error
+		db  *gorm.DB
+		err error
 	)
 
-	if env == "production" {
-		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=require TimeZone=%s", host, user, password, dbname, port, timeZone)
-	} else {
-		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=%s", host, user, password, dbname, port, timeZone)
-	}
-
-	if db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{FullSaveAssociations: true}); err != nil {
+	if db, err = Open(cfg, &gorm.Config{FullSaveAssociations: true, Logger: gormLogger}); err != nil {
 		log.Fatal("Error connecting to database: ", err)
 	}
 
-	if err = db.AutoMigrate(&domain.User{}, &domain.Photo{}, &domain.Comment{}, &domain.SocialMedia{}); err != nil {
+	if err = db.AutoMigrate(Models()...); err != nil {
 		log.Fatal("Error migrating database: ", err.Error())
 	}
 
 	return db
 }
+
+// Open connects to the database of the configured driver without migrating
+// it.
+func Open(cfg config.Database, gormConfig *gorm.Config) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case "postgres":
+		return gorm.Open(postgres.Open(cfg.DSN()), gormConfig)
+	case "sqlite":
+		db, err := gorm.Open(sqlite.Open(SQLiteDSN(cfg.SQLitePath)), gormConfig)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sqlDB, err := db.DB()
+
+		if err != nil {
+			return nil, err
+		}
+
+		// SQLite allows a single writer, and every connection to :memory:
+		// opens a database of its own, so all queries share one connection.
+		sqlDB.SetMaxOpenConns(1)
+
+		return db, nil
+	}
+
+	return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+}
+
+// SQLiteDSN returns the connection string of the SQLite database at path,
+// enforcing foreign keys so cascading deletes behave as they do on postgres.
+func SQLiteDSN(path string) string {
+	return path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
+
+// Models lists every model migrated at startup.
+func Models() []interface{} {
+	return []interface{}{
+		&domain.User{},
+		&domain.Photo{},
+		&domain.Comment{},
+		&domain.SocialMedia{},
+		&domain.LoginThrottle{},
+		&domain.LoginEvent{},
+		&domain.UserMFA{},
+		&domain.RecoveryCode{},
+		&domain.Identity{},
+		&domain.APIKey{},
+		&domain.Session{},
+		&domain.AccountDeletion{},
+		&domain.AuditEvent{},
+		&domain.DataExport{},
+		&domain.EmailChange{},
+	}
+}
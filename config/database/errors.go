@@ -0,0 +1,17 @@
+package database
+
+import "strings"
+
+// UniqueViolation reports whether err rejected a duplicate value of the
+// uniquely indexed column of table. Postgres names the index and SQLite the
+// column, so the check works with either driver.
+func UniqueViolation(err error, table string, column string) bool {
+	if err == nil {
+		return false
+	}
+
+	message := err.Error()
+
+	return strings.Contains(message, "idx_"+table+"_"+column) ||
+		strings.Contains(message, "UNIQUE constraint failed: "+table+"."+column)
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package database_test
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/config/database/databasetest"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	userRepository "api-mygram-go/user/repository/postgres"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	databasetest.Main(m)
+}
+
+func TestTxManagerRollsBackFailedUnitsOfWork(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewUserRepository(db, helpers.NewBcryptHasher(4))
+	txManager := database.NewTxManager(db)
+	failed := errors.New("failed")
+	user := domain.User{Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}
+
+	err := txManager.Do(ctx, func(ctx context.Context) error {
+		if err := repository.Register(ctx, &user); err != nil {
+			return err
+		}
+
+		return txManager.Do(ctx, func(ctx context.Context) error {
+			return failed
+		})
+	})
+
+	if !errors.Is(err, failed) {
+		t.Fatalf("Do returned %v, want the error of the unit of work", err)
+	}
+
+	if err = repository.GetByID(ctx, &domain.User{}, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("the user registered in the rolled back transaction was found: %v", err)
+	}
+
+	if err = txManager.Do(ctx, func(ctx context.Context) error { return repository.Register(ctx, &user) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repository.GetByID(ctx, &domain.User{}, user.ID); err != nil {
+		t.Errorf("the user registered in the committed transaction wasn't found: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultEnvFile  = ".env"
+	DefaultYAMLFile = "config.yaml"
+)
+
+// Load reads the configuration the way the server does at startup: the YAML
+// file named by CONFIG_FILE (config.yaml by default), then .env, then the
+// process environment. Missing files are skipped.
+func Load() (Config, error) {
+	yamlFile := os.Getenv("CONFIG_FILE")
+
+	if yamlFile == "" {
+		yamlFile = DefaultYAMLFile
+	}
+
+	return LoadFrom(yamlFile, DefaultEnvFile, os.LookupEnv)
+}
+
+// LoadFrom reads yamlFile and envFile, both optional, and overrides them with
+// lookupEnv. The result is validated and every problem is reported at once.
+func LoadFrom(yamlFile string, envFile string, lookupEnv func(string) (string, bool)) (Config, error) {
+	config := Default()
+
+	var problems []string
+
+	if raw, err := os.ReadFile(yamlFile); err == nil {
+		if err = yaml.Unmarshal(raw, &config); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", yamlFile, err))
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, err.Error())
+	}
+
+	dotenv, err := godotenv.Read(envFile)
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, fmt.Sprintf("%s: %s", envFile, err))
+	}
+
+	lookup := func(key string) (string, bool) {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			return value, true
+		}
+
+		value, ok := dotenv[key]
+
+		return value, ok && value != ""
+	}
+
+	problems = append(problems, bind(reflect.ValueOf(&config).Elem(), "", lookup)...)
+	problems = append(problems, config.bindOIDC(lookup)...)
+
+	if config.Database.SSLMode == "" {
+		config.Database.SSLMode = "disable"
+
+		if config.Production() {
+			config.Database.SSLMode = "require"
+		}
+	}
+
+	problems = append(problems, config.problems()...)
+
+	if len(problems) > 0 {
+		return config, &ValidationError{problems}
+	}
+
+	return config, nil
+}
+
+// bindOIDC builds the provider list from OIDC_PROVIDERS, keeping providers
+// already described in YAML, and applies their OIDC_<NAME>_ variables.
+func (config *Config) bindOIDC(lookup func(string) (string, bool)) (problems []string) {
+	if names, ok := lookup("OIDC_PROVIDERS"); ok {
+		providers := []OIDCProvider{}
+
+		for _, name := range splitList(names) {
+			provider := OIDCProvider{Name: strings.ToLower(name)}
+
+			for _, existing := range config.OIDC.Providers {
+				if existing.Name == provider.Name {
+					provider = existing
+				}
+			}
+
+			providers = append(providers, provider)
+		}
+
+		config.OIDC.Providers = providers
+	}
+
+	for i := range config.OIDC.Providers {
+		provider := &config.OIDC.Providers[i]
+		problems = append(problems, bind(reflect.ValueOf(provider).Elem(), provider.EnvPrefix(), lookup)...)
+	}
+
+	return problems
+}
+
+type envDecoder interface {
+	DecodeEnv(string) error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bind sets every field tagged env from lookup, prefixing the variable names
+// with prefix, and returns the values that failed to parse.
+func bind(value reflect.Value, prefix string, lookup func(string) (string, bool)) (problems []string) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		key, tagged := value.Type().Field(i).Tag.Lookup("env")
+
+		if !tagged {
+			if field.Kind() == reflect.Struct {
+				problems = append(problems, bind(field, prefix, lookup)...)
+			}
+
+			continue
+		}
+
+		raw, ok := lookup(prefix + key)
+
+		if !ok {
+			continue
+		}
+
+		if err := setField(field, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s%s: %s", prefix, key, err))
+		}
+	}
+
+	return problems
+}
+
+func setField(field reflect.Value, raw string) error {
+	if decoder, ok := field.Addr().Interface().(envDecoder); ok {
+		return decoder.DecodeEnv(raw)
+	}
+
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+
+		if err != nil {
+			return fmt.Errorf("%q isn't a duration like 24h or 15m", raw)
+		}
+
+		field.SetInt(int64(duration))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("%q isn't an integer", raw)
+		}
+
+		field.SetInt(number)
+	case reflect.Uint8, reflect.Uint32:
+		number, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("%q isn't an integer between 0 and %d", raw, uint64(1)<<field.Type().Bits()-1)
+		}
+
+		field.SetUint(number)
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+
+		if err != nil {
+			return fmt.Errorf("%q isn't a number", raw)
+		}
+
+		field.SetFloat(number)
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+
+		if err != nil {
+			return fmt.Errorf("%q isn't true or false", raw)
+		}
+
+		field.SetBool(flag)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+
+	return nil
+}
+
+// splitList splits a comma or space separated list.
+func splitList(raw string) []string {
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (err *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(err.Problems, "\n  - ")
+}
+
+// Validate reports every problem in config at once.
+func (config Config) Validate() error {
+	if problems := config.problems(); len(problems) > 0 {
+		return &ValidationError{problems}
+	}
+
+	return nil
+}
+
+func (config Config) problems() (problems []string) {
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	required := func(key string, value string) {
+		if strings.TrimSpace(value) == "" {
+			problem("%s is required", key)
+		}
+	}
+
+	if config.HTTP.Port < 1 || config.HTTP.Port > 65535 {
+		problem("PORT must be between 1 and 65535, got %d", config.HTTP.Port)
+	}
+
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", config.HTTP.ReadTimeout},
+		{"HTTP_READ_HEADER_TIMEOUT", config.HTTP.ReadHeaderTimeout},
+		{"HTTP_WRITE_TIMEOUT", config.HTTP.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", config.HTTP.IdleTimeout},
+	}
+
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			problem("%s can't be negative, got %s", timeout.key, timeout.value)
+		}
+	}
+
+	if config.HTTP.ShutdownTimeout <= 0 {
+		problem("HTTP_SHUTDOWN_TIMEOUT must be positive, got %s", config.HTTP.ShutdownTimeout)
+	}
+
+	if (config.HTTP.TLSCertFile == "") != (config.HTTP.TLSKeyFile == "") {
+		problem("HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together")
+	}
+
+	switch config.Database.Driver {
+	case "postgres":
+		required("PGHOST", config.Database.Host)
+		required("PGUSER", config.Database.User)
+		required("PGDBNAME", config.Database.Name)
+
+		if config.Database.Port < 1 || config.Database.Port > 65535 {
+			problem("PGPORT must be between 1 and 65535, got %d", config.Database.Port)
+		}
+
+		switch config.Database.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			problem("PGSSLMODE %q isn't a postgres sslmode", config.Database.SSLMode)
+		}
+	case "sqlite":
+		required("SQLITE_PATH", config.Database.SQLitePath)
+	default:
+		problem("DB_DRIVER must be postgres or sqlite, got %q", config.Database.Driver)
+	}
+
+	switch config.JWT.Algorithm {
+	case "HS256":
+		required("TOKEN_KEY", config.JWT.TokenKey)
+	case "RS256", "EdDSA":
+		if len(config.JWT.Keys) == 0 {
+			problem("JWT_KEYS is required to sign %s tokens", config.JWT.Algorithm)
+		}
+
+		if config.JWT.ActiveKID != "" && !config.JWT.Keys.has(config.JWT.ActiveKID) {
+			problem("JWT_ACTIVE_KID %q isn't listed in JWT_KEYS", config.JWT.ActiveKID)
+		}
+	default:
+		problem("JWT_ALGORITHM must be HS256, RS256 or EdDSA, got %q", config.JWT.Algorithm)
+	}
+
+	required("JWT_ISSUER", config.JWT.Issuer)
+	required("JWT_AUDIENCE", config.JWT.Audience)
+
+	if config.JWT.TTL <= 0 {
+		problem("JWT_TTL must be positive, got %s", config.JWT.TTL)
+	}
+
+	switch config.Password.Hasher {
+	case "bcrypt":
+		if config.Password.BcryptCost < 4 || config.Password.BcryptCost > 31 {
+			problem("BCRYPT_COST must be between 4 and 31, got %d", config.Password.BcryptCost)
+		}
+	case "argon2id":
+		if config.Password.Argon2Time < 1 {
+			problem("ARGON2_TIME must be at least 1")
+		}
+
+		if config.Password.Argon2Threads < 1 {
+			problem("ARGON2_THREADS must be at least 1")
+		}
+
+		if config.Password.Argon2Memory < 8*uint32(config.Password.Argon2Threads) {
+			problem("ARGON2_MEMORY must be at least 8 KiB per thread, got %d", config.Password.Argon2Memory)
+		}
+	default:
+		problem("PASSWORD_HASHER must be bcrypt or argon2id, got %q", config.Password.Hasher)
+	}
+
+	required("TOTP_ISSUER", config.TOTP.Issuer)
+	required("TOTP_ENCRYPTION_KEY", config.TOTP.EncryptionKey)
+
+	if config.Tracing.Enabled() {
+		required("OTEL_SERVICE_NAME", config.Tracing.ServiceName)
+	}
+
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		problem("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %g", config.Tracing.SampleRatio)
+	}
+
+	switch config.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		problem("LOG_LEVEL must be debug, info, warn or error, got %q", config.Logging.Level)
+	}
+
+	if config.Logging.SlowQueryThreshold < 0 {
+		problem("LOG_SLOW_QUERY_THRESHOLD can't be negative, got %s", config.Logging.SlowQueryThreshold)
+	}
+
+	required("STORAGE_DIR", config.Storage.Dir)
+
+	if config.Accounts.DeletionGracePeriod < 0 {
+		problem("ACCOUNT_DELETION_GRACE_PERIOD can't be negative, got %s", config.Accounts.DeletionGracePeriod)
+	}
+
+	if config.Accounts.PurgeInterval <= 0 {
+		problem("ACCOUNT_PURGE_INTERVAL must be positive, got %s", config.Accounts.PurgeInterval)
+	}
+
+	if config.Accounts.EmailVerificationTTL <= 0 {
+		problem("EMAIL_VERIFICATION_TTL must be positive, got %s", config.Accounts.EmailVerificationTTL)
+	}
+
+	if config.Accounts.EmailVerificationURL != "" {
+		if link, err := url.Parse(config.Accounts.EmailVerificationURL); err != nil || !link.IsAbs() {
+			problem("EMAIL_VERIFICATION_URL must be an absolute URL, got %q", config.Accounts.EmailVerificationURL)
+		}
+	}
+
+	if config.Exports.Retention <= 0 {
+		problem("EXPORT_RETENTION must be positive, got %s", config.Exports.Retention)
+	}
+
+	if config.Exports.LinkTTL <= 0 {
+		problem("EXPORT_LINK_TTL must be positive, got %s", config.Exports.LinkTTL)
+	}
+
+	if _, err := mail.ParseAddress(config.Mail.From); err != nil {
+		problem("MAIL_FROM must be an email address, got %q", config.Mail.From)
+	}
+
+	if config.Mail.SMTPHost != "" {
+		if config.Mail.SMTPPort < 1 || config.Mail.SMTPPort > 65535 {
+			problem("SMTP_PORT must be between 1 and 65535, got %d", config.Mail.SMTPPort)
+		}
+	} else if config.Production() {
+		problem("SMTP_HOST is required in production, emails are only logged without it")
+	}
+
+	if config.Photos.FetchTimeout <= 0 {
+		problem("PHOTO_FETCH_TIMEOUT must be positive, got %s", config.Photos.FetchTimeout)
+	}
+
+	seen := map[string]bool{}
+
+	for _, provider := range config.OIDC.Providers {
+		if provider.Name == "" {
+			problem("every oidc provider needs a name")
+
+			continue
+		}
+
+		if seen[provider.Name] {
+			problem("oidc provider %s is listed twice", provider.Name)
+		}
+
+		seen[provider.Name] = true
+		prefix := provider.EnvPrefix()
+
+		required(prefix+"ISSUER", provider.Issuer)
+		required(prefix+"CLIENT_ID", provider.ClientID)
+		required(prefix+"REDIRECT_URL", provider.RedirectURL)
+	}
+
+	return problems
+}
+
+func (keys JWTKeys) has(kid string) bool {
+	for _, key := range keys {
+		if key.ID == kid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -23,6 +23,196 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public keys, identified by kid, that verify tokens issued by MyGram",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Fetch token signing keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/helpers.JSONWebKeySet"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/providers": {
+            "get": {
+                "description": "List the OpenID Connect providers users can sign in with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Fetch sign in providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataProviders"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/callback": {
+            "get": {
+                "description": "Exchange the authorization code, verify the id token and retrieve a token for the linked account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete a provider sign in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api-mygram-go_auth_utils.ResponseDataLoggedinUser"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api-mygram-go_auth_utils.ResponseDataMFAChallenge"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/login": {
+            "get": {
+                "description": "Redirect to the provider to start an authorization code flow with PKCE. Pass age to create an account for a new identity.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in with a provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Age used when a new account is created",
+                        "name": "age",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/avatars/{userId}/{file}": {
+            "get": {
+                "description": "Download an avatar file from the URL it was given when uploaded. Files never change, so they can be cached for good.",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get an avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Avatar file",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
         "/comments": {
             "get": {
                 "security": [
@@ -51,13 +241,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -100,13 +296,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -158,19 +360,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -211,24 +419,112 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Report that the process is up. No dependency is checked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{userId}/{file}": {
+            "get": {
+                "description": "Download the image of an uploaded photo from its photo_url. Files never change, so they can be cached for good.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Get an uploaded photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image file",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_comment_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
             }
         },
+        "/metrics": {
+            "get": {
+                "description": "HTTP, database and domain metrics in the Prometheus text format",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Prometheus metrics",
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
         "/photos": {
             "get": {
                 "security": [
@@ -236,7 +532,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Get all photos with authentication user",
+                "description": "Get all photos with authentication user. With near, only the photos whose owners made their location public and that were taken within radius kilometers of near are listed, closest first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -247,6 +543,22 @@ const docTemplate = `{
                     "photos"
                 ],
                 "summary": "Fetch all photos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "-6.175392,106.827153",
+                        "description": "Latitude and longitude, comma separated",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 10,
+                        "description": "Radius around near, in kilometers, up to 500",
+                        "name": "radius",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -257,13 +569,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -274,7 +592,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Create and store a photo with authentication user",
+                "description": "Create and store a photo with authentication user. Its metadata is read from the image at photo_url shortly after; where it was taken is only shown to others with location_public.",
                 "consumes": [
                     "application/json"
                 ],
@@ -306,28 +624,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
             }
         },
-        "/photos/{id}": {
-            "put": {
+        "/photos/import": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Update a photo by id with authentication user",
+                "description": "Store the photos listed in a CSV or JSON Lines manifest with authentication user. A CSV manifest starts with a header naming its title, caption and photo_url columns, and every line of a JSON Lines manifest is an object with those fields. The format is read from the format query parameter, or else from the Content-Type. Invalid rows are reported and skipped; with dry_run nothing is stored.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "produces": [
                     "application/json"
@@ -335,22 +660,31 @@ const docTemplate = `{
                 "tags": [
                     "photos"
                 ],
-                "summary": "Update a photo",
+                "summary": "Import photos",
                 "parameters": [
                     {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
                         "type": "string",
-                        "description": "Photo ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Format of the manifest",
+                        "name": "format",
+                        "in": "query"
                     },
                     {
-                        "description": "Photo",
-                        "name": "json",
+                        "type": "boolean",
+                        "description": "Validate the manifest without storing anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Manifest",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/utils.UpdatePhoto"
+                            "type": "string"
                         }
                     }
                 ],
@@ -358,38 +692,52 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.ResponseDataUpdatedPhoto"
+                            "$ref": "#/definitions/utils.ResponseDataImportedPhotos"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataImportedPhotos"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/photos/upload": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Delete a photo by id with authentication user",
+                "description": "Store a photo of a JPEG, PNG, GIF or WebP image of up to 20 MB with authentication user. Its dimensions, type, size and, from EXIF, camera, time and place are read right away. Unless keep_location is set, location data is stripped from the stored image and where it was taken is only shown to its owner.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -397,13 +745,217 @@ const docTemplate = `{
                 "tags": [
                     "photos"
                 ],
-                "summary": "Delete a photo",
+                "summary": "Upload a photo",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Photo ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Image",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Title",
+                        "name": "title",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caption",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep where the photo was taken in the image and share it",
+                        "name": "keep_location",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataPhoto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/photos/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get a photo by id with its metadata, read from the image once it is uploaded or fetched from its URL. Where the photo was taken is only shown to others when its owner made it public.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Get a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Photo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataPhoto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update a photo by id with authentication user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Update a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Photo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Photo",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/utils.UpdatePhoto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataUpdatedPhoto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a photo by id with authentication user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Delete a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Photo ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -417,19 +969,51 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_photo_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check every dependency and report its status and latency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
                         }
                     }
                 }
@@ -463,13 +1047,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -512,13 +1102,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -570,19 +1166,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -623,19 +1225,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_socialmedia_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -648,7 +1256,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Update a user with authentication user",
+                "description": "Update the profile of the authentication user, fields left out are kept. A new email address replaces the current one once it is confirmed with the link mailed to it, and can only be set with a login token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -680,19 +1288,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "409": {
                         "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
@@ -703,7 +1317,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Delete a user with authentication user",
+                "description": "Schedule the deletion of the authentication user. The account, its photos, comments, social medias and files are erased once the grace period ends, and comments on other people's photos are kept without their author.",
                 "consumes": [
                     "application/json"
                 ],
@@ -724,27 +1338,32 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "description": "Authentication a user and retrieve a token",
+        "/users/apikeys": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the API keys of the authentication user, including revoked and expired ones",
                 "consumes": [
                     "application/json"
                 ],
@@ -754,43 +1373,41 @@ const docTemplate = `{
                 "tags": [
                     "users"
                 ],
-                "summary": "Login a user",
-                "parameters": [
-                    {
-                        "description": "Login User",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/utils.LoginUser"
-                        }
-                    }
-                ],
+                "summary": "Fetch API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.ResponseDataLoggedinUser"
+                            "$ref": "#/definitions/utils.ResponseDataFetchedAPIKeys"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
-            }
-        },
-        "/users/register": {
+            },
             "post": {
-                "description": "create and store a user",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create an API key with the given scopes and optional expiry. The key is only returned once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -800,15 +1417,15 @@ const docTemplate = `{
                 "tags": [
                     "users"
                 ],
-                "summary": "Register a user",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Register User",
+                        "description": "Create API Key",
                         "name": "json",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/utils.RegisterUser"
+                            "$ref": "#/definitions/utils.CreateAPIKey"
                         }
                     }
                 ],
@@ -816,75 +1433,1109 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/utils.ResponseDataRegisteredUser"
+                            "$ref": "#/definitions/utils.ResponseDataCreatedAPIKey"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/mygram-api_user_utils.ResponseMessage"
+                            "$ref": "#/definitions/utils.ResponseMessage"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "mygram-api_comment_utils.ResponseMessage": {
+        },
+        "/users/apikeys/{apiKeyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke an API key of the authentication user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Revoke API key by id",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessageRevokedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/deletion": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get when the account of the authentication user is going to be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the pending deletion",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataAccountDeletion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Keep the account of the authentication user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Cancel the pending deletion",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessageCanceledDeletion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/email/verify": {
+            "post": {
+                "description": "Confirm a new email address with the token of the link mailed to it, which makes it the email of its user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verify a new email address",
+                "parameters": [
+                    {
+                        "description": "Verify Email",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/utils.VerifyEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataUpdatedUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Authentication a user and retrieve a token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Login a user",
+                "parameters": [
+                    {
+                        "description": "Login User",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/utils.LoginUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api-mygram-go_user_utils.ResponseDataLoggedinUser"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api-mygram-go_user_utils.ResponseDataMFAChallenge"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/mfa": {
+            "post": {
+                "description": "Exchange the mfa_required challenge token and a TOTP or recovery code for a token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Complete a two-factor login",
+                "parameters": [
+                    {
+                        "description": "Login MFA",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/utils.LoginMFA"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api-mygram-go_user_utils.ResponseDataLoggedinUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/avatar": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Set the avatar of the authentication user from a JPEG, PNG, GIF or WebP image of up to 10 MB. The image is cropped to a centered square and stored in several sizes, profileImageUrl points at the 256 pixels one.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Upload an avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataAvatar"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Queue a ZIP archive of the profile, photos, comments, social medias and stored files of the authentication user. Poll the export until it is ready to get its download link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Export my data",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataDataExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export/{exportId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the status of an export of the authentication user, with a time-limited download link once it is ready",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get an export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Get export by id",
+                        "name": "exportId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataDataExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export/{exportId}/download": {
+            "get": {
+                "description": "Download the ZIP archive of an export. The link returned with a ready export authorizes the download, so no credentials are needed.",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Download an export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Download export by id",
+                        "name": "exportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry of the link, in seconds since the epoch",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the link",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa/totp": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Generate a TOTP secret and provisioning URI for the authentication user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Start TOTP enrollment",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataTOTPEnrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with a code from the authenticator app and retrieve recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirm TOTP enrollment",
+                "parameters": [
+                    {
+                        "description": "Confirm TOTP",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/utils.ConfirmTOTP"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataRecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "create and store a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register a user",
+                "parameters": [
+                    {
+                        "description": "Register User",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/utils.RegisterUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataRegisteredUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get the devices the authentication user is signed in on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetch sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseDataFetchedSessions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Sign the authentication user out of a device. Tokens issued for the session stop working immediately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Revoke session by id",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessageRevokedSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api-mygram-go_auth_utils.LoggedinUser": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "the token generated here"
+                }
+            }
+        },
+        "api-mygram-go_auth_utils.MFAChallenge": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "the challenge token generated here"
+                }
+            }
+        },
+        "api-mygram-go_auth_utils.ResponseDataLoggedinUser": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/api-mygram-go_auth_utils.LoggedinUser"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "api-mygram-go_auth_utils.ResponseDataMFAChallenge": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/api-mygram-go_auth_utils.MFAChallenge"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "mfa_required"
+                }
+            }
+        },
+        "api-mygram-go_comment_utils.Photo": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api-mygram-go_photo_utils.Photo": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/utils.PhotoMetadata"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api-mygram-go_user_utils.LoggedinUser": {
             "type": "object",
             "properties": {
-                "data": {
+                "token": {
                     "type": "string",
-                    "example": "the error explained here"
+                    "example": "the token generated here"
+                }
+            }
+        },
+        "api-mygram-go_user_utils.MFAChallenge": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 300
                 },
-                "status": {
+                "mfa_token": {
                     "type": "string",
-                    "example": "fail"
+                    "example": "the challenge token generated here"
                 }
             }
         },
-        "mygram-api_photo_utils.ResponseMessage": {
+        "api-mygram-go_user_utils.ResponseDataLoggedinUser": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "string",
-                    "example": "the error explained here"
+                    "$ref": "#/definitions/api-mygram-go_user_utils.LoggedinUser"
                 },
                 "status": {
                     "type": "string",
-                    "example": "fail"
+                    "example": "success"
                 }
             }
         },
-        "mygram-api_socialmedia_utils.ResponseMessage": {
+        "api-mygram-go_user_utils.ResponseDataMFAChallenge": {
             "type": "object",
             "properties": {
                 "data": {
+                    "$ref": "#/definitions/api-mygram-go_user_utils.MFAChallenge"
+                },
+                "status": {
                     "type": "string",
-                    "example": "the error explained here"
+                    "example": "mfa_required"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Result"
+                    }
                 },
                 "status": {
                     "type": "string",
-                    "example": "fail"
+                    "example": "ok"
                 }
             }
         },
-        "mygram-api_user_utils.ResponseMessage": {
+        "health.Result": {
             "type": "object",
             "properties": {
-                "data": {
+                "error": {
                     "type": "string",
-                    "example": "the error explained here"
+                    "example": "dial tcp 127.0.0.1:5432: connect: connection refused"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
                 },
                 "status": {
                     "type": "string",
-                    "example": "fail"
+                    "example": "ok"
+                }
+            }
+        },
+        "helpers.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "helpers.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/helpers.JSONWebKey"
+                    }
+                }
+            }
+        },
+        "utils.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "the created at generated here"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "here is the generated api key id"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "the last used at generated here"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "deploy script"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "1a2b3c4d"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "the revoked at generated here"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "photos:read",
+                        "photos:write"
+                    ]
+                }
+            }
+        },
+        "utils.AccountDeletion": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "the created at generated here"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "here is the generated deletion id"
+                },
+                "purge_after": {
+                    "type": "string",
+                    "example": "2030-01-31T00:00:00Z"
                 }
             }
         },
@@ -908,6 +2559,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "A caption"
                 },
+                "location_public": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "photo_url": {
                     "type": "string",
                     "example": "https://www.example.com/image.jpg"
@@ -1004,6 +2659,136 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.Avatar": {
+            "type": "object",
+            "properties": {
+                "profileImageUrl": {
+                    "type": "string",
+                    "example": "/avatars/user-1/k3j5h2g8d9s1-256.jpg"
+                },
+                "sizes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "utils.ConfirmTOTP": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "utils.CreateAPIKey": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "deploy script"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "photos:read",
+                        "photos:write"
+                    ]
+                }
+            }
+        },
+        "utils.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "the created at generated here"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "here is the generated api key id"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "mygram_1a2b3c4d_the secret shown once here"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "deploy script"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "1a2b3c4d"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "photos:read",
+                        "photos:write"
+                    ]
+                }
+            }
+        },
+        "utils.DataExport": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string",
+                    "example": "the completed at generated here"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "the created at generated here"
+                },
+                "download_expires_at": {
+                    "type": "string",
+                    "example": "the download link expires at generated here"
+                },
+                "download_url": {
+                    "type": "string",
+                    "example": "/users/me/export/export-1/download?expires=1893456000\u0026signature=the signature generated here"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "the reason the export failed"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "the expires at generated here"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "here is the generated export id"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
         "utils.FetchedComment": {
             "type": "object",
             "properties": {
@@ -1017,7 +2802,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "photo": {
-                    "$ref": "#/definitions/utils.Photo"
+                    "$ref": "#/definitions/api-mygram-go_comment_utils.Photo"
                 },
                 "photo_id": {
                     "type": "string"
@@ -1042,6 +2827,11 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "distance_km": {
+                    "description": "DistanceKm is set when photos near a place are listed.",
+                    "type": "number",
+                    "example": 1.25
+                },
                 "id": {
                     "type": "string"
                 },
@@ -1062,45 +2852,166 @@ const docTemplate = `{
                 }
             }
         },
-        "utils.LoggedinUser": {
+        "utils.ImportedPhotoRow": {
             "type": "object",
             "properties": {
-                "token": {
+                "error": {
+                    "type": "string",
+                    "example": "photo_url: non zero value required"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "here is the generated photo id"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "type": "string",
+                    "example": "imported"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "A Title"
+                }
+            }
+        },
+        "utils.ImportedPhotos": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.ImportedPhotoRow"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "utils.Location": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number",
+                    "example": -6.175392
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": 106.827153
+                }
+            }
+        },
+        "utils.LoginMFA": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "the challenge token generated here"
+                }
+            }
+        },
+        "utils.LoginUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johndoe@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "secret"
+                }
+            }
+        },
+        "utils.PhotoMetadata": {
+            "type": "object",
+            "properties": {
+                "camera_model": {
+                    "type": "string",
+                    "example": "Pixel 8"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "the server answered 404 Not Found"
+                },
+                "file_size": {
+                    "type": "integer",
+                    "example": 2483911
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 3024
+                },
+                "location": {
+                    "description": "Location is only shown to others when the owner made it public.",
+                    "$ref": "#/definitions/utils.Location"
+                },
+                "location_public": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "mime_type": {
                     "type": "string",
-                    "example": "the token generated here"
+                    "example": "image/jpeg"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                },
+                "taken_at": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 4032
                 }
             }
         },
-        "utils.LoginUser": {
+        "utils.Providers": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "johndoe@example.com"
-                },
-                "password": {
-                    "type": "string",
-                    "example": "secret"
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "google"
+                    ]
                 }
             }
         },
-        "utils.Photo": {
+        "utils.RecoveryCodes": {
             "type": "object",
             "properties": {
-                "caption": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "photo_url": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcd2345-efgh6789"
+                    ]
                 }
             }
         },
@@ -1146,6 +3057,18 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.ResponseDataAccountDeletion": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.AccountDeletion"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
         "utils.ResponseDataAddedComment": {
             "type": "object",
             "properties": {
@@ -1182,6 +3105,57 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.ResponseDataAvatar": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.Avatar"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseDataCreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.CreatedAPIKey"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseDataDataExport": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.DataExport"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseDataFetchedAPIKeys": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.APIKey"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
         "utils.ResponseDataFetchedComment": {
             "type": "object",
             "properties": {
@@ -1212,6 +3186,21 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.ResponseDataFetchedSessions": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.Session"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
         "utils.ResponseDataFetchedSocialMedia": {
             "type": "object",
             "properties": {
@@ -1224,11 +3213,47 @@ const docTemplate = `{
                 }
             }
         },
-        "utils.ResponseDataLoggedinUser": {
+        "utils.ResponseDataImportedPhotos": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.ImportedPhotos"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseDataPhoto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/api-mygram-go_photo_utils.Photo"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseDataProviders": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.Providers"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseDataRecoveryCodes": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/utils.LoggedinUser"
+                    "$ref": "#/definitions/utils.RecoveryCodes"
                 },
                 "status": {
                     "type": "string",
@@ -1248,6 +3273,18 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.ResponseDataTOTPEnrollment": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/utils.TOTPEnrollment"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
         "utils.ResponseDataUpdatedComment": {
             "type": "object",
             "properties": {
@@ -1296,6 +3333,32 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.ResponseMessage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "string",
+                    "example": "the error explained here"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "fail"
+                }
+            }
+        },
+        "utils.ResponseMessageCanceledDeletion": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "your account will no longer be deleted"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
         "utils.ResponseMessageDeletedComment": {
             "type": "object",
             "properties": {
@@ -1340,7 +3403,20 @@ const docTemplate = `{
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "your account has been successfully deleted"
+                    "example": "your account will be deleted after 2030-01-31, cancel the deletion before then to keep it"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.ResponseMessageRevokedAPIKey": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "your api key has been successfully revoked"
                 },
                 "status": {
                     "type": "string",
@@ -1348,6 +3424,56 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.ResponseMessageRevokedSession": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "the session has been successfully signed out"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "the created at generated here"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "device_name": {
+                    "type": "string",
+                    "example": "Firefox on Linux"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "the expires at generated here"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "here is the generated session id"
+                },
+                "ip_address": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "the last seen at generated here"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/119.0"
+                }
+            }
+        },
         "utils.SocialMedia": {
             "type": "object",
             "properties": {
@@ -1391,6 +3517,19 @@ const docTemplate = `{
                 }
             }
         },
+        "utils.TOTPEnrollment": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/MyGram:johndoe@example.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=MyGram"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
         "utils.UpdateComment": {
             "type": "object",
             "properties": {
@@ -1433,10 +3572,26 @@ const docTemplate = `{
         "utils.UpdateUser": {
             "type": "object",
             "properties": {
+                "age": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Sunsets and street photography"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
                 "email": {
                     "type": "string",
                     "example": "newjohndoe@example.com"
                 },
+                "profileImageUrl": {
+                    "type": "string",
+                    "example": "https://www.example.com/image.jpg"
+                },
                 "username": {
                     "type": "string",
                     "example": "newjohndoe"
@@ -1519,16 +3674,36 @@ const docTemplate = `{
             "properties": {
                 "age": {
                     "type": "integer",
-                    "example": 8
+                    "example": 9
+                },
+                "bio": {
+                    "type": "string",
+                    "example": "Sunsets and street photography"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "John Doe"
                 },
                 "email": {
                     "type": "string",
-                    "example": "newjohndoe@example.com"
+                    "example": "johndoe@example.com"
                 },
                 "id": {
                     "type": "string",
                     "example": "here is the generated user id"
                 },
+                "pending_email": {
+                    "type": "string",
+                    "example": "newjohndoe@example.com"
+                },
+                "pending_email_expires_at": {
+                    "type": "string",
+                    "example": "the verification link expires at generated here"
+                },
+                "profileImageUrl": {
+                    "type": "string",
+                    "example": "https://www.example.com/image.jpg"
+                },
                 "updated_at": {
                     "type": "string",
                     "example": "the updated at generated here"
@@ -1543,16 +3718,29 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "email": {
-                    "type": "string",
-                    "example": "johndoe@example.com"
+                    "type": "string"
                 },
                 "id": {
+                    "type": "string"
+                },
+                "profileImageUrl": {
                     "type": "string",
-                    "example": "here is the generated user id"
+                    "example": "/avatars/user-1/k3j5h2g8d9s1-256.jpg"
                 },
                 "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.VerifyEmail": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
                     "type": "string",
-                    "example": "johndoe"
+                    "example": "the token of the verification link"
                 }
             }
         }
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"gorm.io/gorm"
+)
+
+const (
+	ScopePhotosRead        = "photos:read"
+	ScopePhotosWrite       = "photos:write"
+	ScopeCommentsRead      = "comments:read"
+	ScopeCommentsWrite     = "comments:write"
+	ScopeSocialMediasRead  = "socialmedias:read"
+	ScopeSocialMediasWrite = "socialmedias:write"
+	ScopeUsersWrite        = "users:write"
+)
+
+// Scopes lists every scope an API key can be granted. Tokens from an
+// interactive login carry all of them.
+var Scopes = []string{
+	ScopePhotosRead,
+	ScopePhotosWrite,
+	ScopeCommentsRead,
+	ScopeCommentsWrite,
+	ScopeSocialMediasRead,
+	ScopeSocialMediasWrite,
+	ScopeUsersWrite,
+}
+
+var (
+	ErrInvalidAPIKey = errors.New("the api key is invalid, expired or revoked")
+	// ErrAPIKeyPrefixTaken reports that a new key drew the prefix of an
+	// existing one, so another key must be generated.
+	ErrAPIKeyPrefixTaken = errors.New("the api key prefix is already in use")
+)
+
+type APIKey struct {
+	ID         string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID     string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	Name       string     `gorm:"type:VARCHAR(50);not null" valid:"required" json:"name"`
+	Prefix     string     `gorm:"type:VARCHAR(16);uniqueIndex;not null" json:"prefix"`
+	KeyHash    string     `gorm:"type:VARCHAR(64);not null" json:"-"`
+	Scopes     string     `gorm:"not null" valid:"required" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	User       *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+func (apiKey *APIKey) BeforeCreate(db *gorm.DB) (err error) {
+	if _, err := govalidator.ValidateStruct(apiKey); err != nil {
+		return err
+	}
+
+	return
+}
+
+func (apiKey *APIKey) ScopeList() []string {
+	return strings.Fields(apiKey.Scopes)
+}
+
+func (apiKey *APIKey) Active(now time.Time) bool {
+	return apiKey.RevokedAt == nil && (apiKey.ExpiresAt == nil || apiKey.ExpiresAt.After(now))
+}
+
+type APIKeyUseCase interface {
+	Create(context.Context, *APIKey, []string) (string, error)
+	Fetch(context.Context, *[]APIKey, string) error
+	Revoke(context.Context, string, string) error
+	Authenticate(context.Context, string) (APIKey, error)
+}
+
+type APIKeyRepository interface {
+	Store(context.Context, *APIKey) error
+	Fetch(context.Context, *[]APIKey, string) error
+	GetByPrefix(context.Context, *APIKey, string) error
+	Revoke(context.Context, string, string) error
+	Touch(context.Context, string, time.Time) error
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AuditAccountDeletionScheduled = "account.deletion_scheduled"
+	AuditAccountDeletionCanceled  = "account.deletion_canceled"
+	AuditAccountPurged            = "account.purged"
+)
+
+// AuditEvent records an action taken on an account. Events outlive the
+// accounts they describe, so they reference them without foreign keys.
+// Details holds a JSON object specific to the action.
+type AuditEvent struct {
+	ID        string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	Action    string     `gorm:"type:VARCHAR(100);not null;index" json:"action"`
+	ActorID   string     `gorm:"type:VARCHAR(50)" json:"actor_id"`
+	SubjectID string     `gorm:"type:VARCHAR(50);not null;index" json:"subject_id"`
+	Details   string     `json:"details"`
+	CreatedAt *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+}
+
+type AuditRepository interface {
+	Store(context.Context, *AuditEvent) error
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidImage = errors.New("the avatar must be a JPEG, PNG, GIF or WebP image of at least 64x64 pixels")
+
+// AvatarSizes lists the square sizes, in pixels, every avatar is stored in.
+// ProfileImageUrl points at the ProfileAvatarSize one.
+var AvatarSizes = []int{64, 256, 512}
+
+const ProfileAvatarSize = 256
+
+// AvatarPathPrefix starts the paths avatars are served from.
+const AvatarPathPrefix = "/avatars/"
+
+// AvatarKey returns the blob key of an avatar. Every upload gets a new
+// version, so its URLs can be cached for good.
+func AvatarKey(userID string, version string, size int) string {
+	return fmt.Sprintf("%savatar/%s-%d.jpg", UserBlobPrefix(userID), version, size)
+}
+
+// AvatarPath returns the path an avatar is served from.
+func AvatarPath(userID string, version string, size int) string {
+	return fmt.Sprintf("%s%s/%s-%d.jpg", AvatarPathPrefix, userID, version, size)
+}
+
+type AvatarUseCase interface {
+	Set(context.Context, string, io.Reader) (User, error)
+	Open(context.Context, string, string) (io.ReadCloser, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("the file doesn't exist")
+
+// BlobStore keeps files under slash separated keys. Files belonging to a user
+// are kept under UserBlobPrefix, so they can be erased with the account.
+type BlobStore interface {
+	Put(context.Context, string, io.Reader) error
+	Open(context.Context, string) (io.ReadCloser, error)
+	List(context.Context, string) ([]string, error)
+	Delete(context.Context, string) error
+	DeletePrefix(context.Context, string) (int, error)
+}
+
+// UserBlobPrefix returns the prefix of the keys of the files of a user.
+func UserBlobPrefix(userID string) string {
+	return "users/" + userID + "/"
+}
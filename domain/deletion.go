@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// GhostUserID owns the comments deleted accounts left on other people's
+// photos, so the conversations stay readable without naming their author.
+const GhostUserID = "user-ghost"
+
+// GhostUsername is the name of the ghost user. Usernames starting with it
+// are reserved, so no account can take it.
+const GhostUsername = "[deleted]"
+
+// AccountDeletion is a request to erase an account. The account is purged
+// once PurgeAfter has passed unless the request is canceled first. Requests
+// outlive the account, so they keep no foreign key to it.
+type AccountDeletion struct {
+	ID          string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID      string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	PurgeAfter  *time.Time `gorm:"not null;index" json:"purge_after"`
+	CanceledAt  *time.Time `json:"canceled_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+}
+
+// AccountPurge counts what purging an account erased.
+type AccountPurge struct {
+	Photos             int64 `json:"photos"`
+	Comments           int64 `json:"comments"`
+	AnonymizedComments int64 `json:"anonymized_comments"`
+	LoginEvents        int64 `json:"login_events"`
+	Files              int   `json:"files"`
+}
+
+type AccountDeletionUseCase interface {
+	Schedule(context.Context, string) (AccountDeletion, error)
+	GetPending(context.Context, *AccountDeletion, string) error
+	Cancel(context.Context, string) error
+	PurgeDue(context.Context, time.Time) (int, error)
+}
+
+type AccountDeletionRepository interface {
+	Store(context.Context, *AccountDeletion) error
+	GetPending(context.Context, *AccountDeletion, string) error
+	FetchDue(context.Context, *[]AccountDeletion, time.Time, int) error
+	Cancel(context.Context, string, time.Time) error
+	Complete(context.Context, string, time.Time) error
+	PurgeContent(context.Context, string) (AccountPurge, error)
+}
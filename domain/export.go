@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrExportLinkInvalid = errors.New("the download link is invalid or has expired")
+
+const (
+	DataExportPending = "pending"
+	DataExportRunning = "running"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+	DataExportExpired = "expired"
+)
+
+// DataExport is a ZIP archive of everything MyGram holds about a user. It is
+// assembled in the background and kept in the blob store under BlobKey until
+// ExpiresAt.
+type DataExport struct {
+	ID          string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID      string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	Status      string     `gorm:"type:VARCHAR(20);not null;index" json:"status"`
+	BlobKey     string     `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	ClaimedAt   *time.Time `json:"-"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	User        *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+// DataExportContent is the data of a user that goes into their export.
+type DataExportContent struct {
+	User         User
+	Photos       []Photo
+	Comments     []Comment
+	SocialMedias []SocialMedia
+}
+
+// DataExportLink authorizes downloading an export until Expires without
+// credentials, so the link can be opened in a browser.
+type DataExportLink struct {
+	Expires   time.Time
+	Signature string
+}
+
+type DataExportUseCase interface {
+	Request(context.Context, string) (DataExport, error)
+	GetByID(context.Context, *DataExport, string, string) error
+	Link(DataExport) DataExportLink
+	Open(context.Context, string, DataExportLink) (io.ReadCloser, error)
+	Process(context.Context) (int, error)
+}
+
+type DataExportRepository interface {
+	Store(context.Context, *DataExport) error
+	GetByID(context.Context, *DataExport, string) error
+	GetActive(context.Context, *DataExport, string) error
+	FetchPending(context.Context, *[]DataExport, int) error
+	FetchExpired(context.Context, *[]DataExport, time.Time, int) error
+	FetchStale(context.Context, *[]DataExport, time.Time, int) error
+	Claim(context.Context, string, time.Time) error
+	Update(context.Context, DataExport) error
+	Collect(context.Context, string) (DataExportContent, error)
+}
@@ -0,0 +1,152 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	Err     error
+	apiKeys table[domain.APIKey]
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{}
+}
+
+func (repository *APIKeyRepository) Store(ctx context.Context, apiKey *domain.APIKey) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if _, taken := repository.apiKeys.find(func(stored domain.APIKey) bool { return stored.Prefix == apiKey.Prefix }); taken {
+		return domain.ErrAPIKeyPrefixTaken
+	}
+
+	apiKey.ID = newID("apikey")
+	apiKey.CreatedAt = now()
+	repository.apiKeys.put(apiKey.ID, *apiKey)
+
+	return nil
+}
+
+func (repository *APIKeyRepository) Fetch(ctx context.Context, apiKeys *[]domain.APIKey, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	found := repository.apiKeys.list(func(apiKey domain.APIKey) bool { return apiKey.UserID == userID })
+
+	// Newest first, like the postgres repository.
+	for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+		found[i], found[j] = found[j], found[i]
+	}
+
+	*apiKeys = found
+
+	return nil
+}
+
+func (repository *APIKeyRepository) GetByPrefix(ctx context.Context, apiKey *domain.APIKey, prefix string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.apiKeys.find(func(apiKey domain.APIKey) bool { return apiKey.Prefix == prefix })
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*apiKey = stored
+
+	return nil
+}
+
+func (repository *APIKeyRepository) Revoke(ctx context.Context, id string, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	revoked := false
+
+	repository.apiKeys.update(id, func(apiKey *domain.APIKey) {
+		if apiKey.UserID == userID && apiKey.RevokedAt == nil {
+			apiKey.RevokedAt = now()
+			revoked = true
+		}
+	})
+
+	if !revoked {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repository *APIKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.apiKeys.update(id, func(apiKey *domain.APIKey) {
+		apiKey.LastUsedAt = &usedAt
+	})
+
+	return nil
+}
+
+type APIKeyUseCase struct {
+	CreateFunc       func(context.Context, *domain.APIKey, []string) (string, error)
+	FetchFunc        func(context.Context, *[]domain.APIKey, string) error
+	RevokeFunc       func(context.Context, string, string) error
+	AuthenticateFunc func(context.Context, string) (domain.APIKey, error)
+}
+
+// AuthenticatingAPIKeys returns an APIKeyUseCase that accepts any key as
+// belonging to userID with scopes. Use it to authenticate handler tests
+// through the X-API-Key header.
+func AuthenticatingAPIKeys(userID string, scopes ...string) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		AuthenticateFunc: func(ctx context.Context, key string) (domain.APIKey, error) {
+			return domain.APIKey{ID: "apikey-test", UserID: userID, Scopes: strings.Join(scopes, " ")}, nil
+		},
+	}
+}
+
+func (useCase *APIKeyUseCase) Create(ctx context.Context, apiKey *domain.APIKey, scopes []string) (string, error) {
+	if useCase.CreateFunc == nil {
+		return "", nil
+	}
+
+	return useCase.CreateFunc(ctx, apiKey, scopes)
+}
+
+func (useCase *APIKeyUseCase) Fetch(ctx context.Context, apiKeys *[]domain.APIKey, userID string) error {
+	if useCase.FetchFunc == nil {
+		return nil
+	}
+
+	return useCase.FetchFunc(ctx, apiKeys, userID)
+}
+
+func (useCase *APIKeyUseCase) Revoke(ctx context.Context, id string, userID string) error {
+	if useCase.RevokeFunc == nil {
+		return nil
+	}
+
+	return useCase.RevokeFunc(ctx, id, userID)
+}
+
+// Authenticate rejects every key unless AuthenticateFunc is set.
+func (useCase *APIKeyUseCase) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	if useCase.AuthenticateFunc == nil {
+		return domain.APIKey{}, domain.ErrInvalidAPIKey
+	}
+
+	return useCase.AuthenticateFunc(ctx, key)
+}
@@ -0,0 +1,115 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type BlobStore struct {
+	Err   error
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func NewBlobStore() *BlobStore {
+	return &BlobStore{blobs: map[string][]byte{}}
+}
+
+func (store *BlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	if store.Err != nil {
+		return store.Err
+	}
+
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.blobs[key] = content
+
+	return nil
+}
+
+func (store *BlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if store.Err != nil {
+		return nil, store.Err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	content, ok := store.blobs[key]
+
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (store *BlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	if store.Err != nil {
+		return nil, store.Err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	keys := []string{}
+
+	for key := range store.blobs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (store *BlobStore) Delete(ctx context.Context, key string) error {
+	if store.Err != nil {
+		return store.Err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.blobs[key]; !ok {
+		return domain.ErrBlobNotFound
+	}
+
+	delete(store.blobs, key)
+
+	return nil
+}
+
+func (store *BlobStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if store.Err != nil {
+		return 0, store.Err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	deleted := 0
+
+	for key := range store.blobs {
+		if strings.HasPrefix(key, prefix) {
+			delete(store.blobs, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,167 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// CommentRepository preloads the User and Photo of fetched comments from
+// Users and Photos.
+type CommentRepository struct {
+	Err      error
+	Users    *UserRepository
+	Photos   *PhotoRepository
+	comments table[domain.Comment]
+}
+
+func NewCommentRepository(users *UserRepository, photos *PhotoRepository, comments ...domain.Comment) *CommentRepository {
+	repository := &CommentRepository{Users: users, Photos: photos}
+
+	for _, comment := range comments {
+		repository.Put(comment)
+	}
+
+	return repository
+}
+
+func (repository *CommentRepository) Put(comment domain.Comment) {
+	repository.comments.put(comment.ID, comment)
+}
+
+func (repository *CommentRepository) Fetch(ctx context.Context, comments *[]domain.Comment, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*comments = repository.comments.list(func(c domain.Comment) bool { return c.UserID == userID })
+
+	for i := range *comments {
+		comment := &(*comments)[i]
+		comment.User = preloadUser(ctx, repository.Users, comment.UserID)
+		comment.Photo = &domain.Photo{ID: comment.PhotoID}
+
+		if repository.Photos != nil {
+			_ = repository.Photos.GetByID(ctx, comment.Photo, comment.PhotoID)
+		}
+	}
+
+	return nil
+}
+
+func (repository *CommentRepository) Store(ctx context.Context, comment *domain.Comment) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	comment.ID = newID("comment")
+	comment.CreatedAt, comment.UpdatedAt = now(), now()
+	repository.Put(*comment)
+
+	return nil
+}
+
+func (repository *CommentRepository) GetByID(ctx context.Context, comment *domain.Comment, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.comments.get(id)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*comment = stored
+
+	return nil
+}
+
+// Update changes the message of the comment and returns the photo named by
+// comment.PhotoID, like the postgres repository.
+func (repository *CommentRepository) Update(ctx context.Context, comment domain.Comment, id string) (photo domain.Photo, err error) {
+	if repository.Err != nil {
+		return photo, repository.Err
+	}
+
+	updated := repository.comments.update(id, func(stored *domain.Comment) {
+		if comment.Message != "" {
+			stored.Message = comment.Message
+		}
+
+		stored.UpdatedAt = now()
+	})
+
+	if !updated {
+		return photo, gorm.ErrRecordNotFound
+	}
+
+	if repository.Photos == nil {
+		return photo, gorm.ErrRecordNotFound
+	}
+
+	err = repository.Photos.GetByID(ctx, &photo, comment.PhotoID)
+
+	return photo, err
+}
+
+func (repository *CommentRepository) Delete(ctx context.Context, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if repository.comments.delete(func(c domain.Comment) bool { return c.ID == id }) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type CommentUseCase struct {
+	FetchFunc   func(context.Context, *[]domain.Comment, string) error
+	StoreFunc   func(context.Context, *domain.Comment) error
+	GetByIDFunc func(context.Context, *domain.Comment, string) error
+	UpdateFunc  func(context.Context, domain.Comment, string) (domain.Photo, error)
+	DeleteFunc  func(context.Context, string) error
+}
+
+func (useCase *CommentUseCase) Fetch(ctx context.Context, comments *[]domain.Comment, userID string) error {
+	if useCase.FetchFunc == nil {
+		return nil
+	}
+
+	return useCase.FetchFunc(ctx, comments, userID)
+}
+
+func (useCase *CommentUseCase) Store(ctx context.Context, comment *domain.Comment) error {
+	if useCase.StoreFunc == nil {
+		return nil
+	}
+
+	return useCase.StoreFunc(ctx, comment)
+}
+
+func (useCase *CommentUseCase) GetByID(ctx context.Context, comment *domain.Comment, id string) error {
+	if useCase.GetByIDFunc == nil {
+		return nil
+	}
+
+	return useCase.GetByIDFunc(ctx, comment, id)
+}
+
+func (useCase *CommentUseCase) Update(ctx context.Context, comment domain.Comment, id string) (domain.Photo, error) {
+	if useCase.UpdateFunc == nil {
+		return domain.Photo{}, nil
+	}
+
+	return useCase.UpdateFunc(ctx, comment, id)
+}
+
+func (useCase *CommentUseCase) Delete(ctx context.Context, id string) error {
+	if useCase.DeleteFunc == nil {
+		return nil
+	}
+
+	return useCase.DeleteFunc(ctx, id)
+}
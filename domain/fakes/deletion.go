@@ -0,0 +1,177 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AccountDeletionRepository keeps deletions in memory. It has no photos or
+// comments to purge, so PurgeContent only records the user in Purged and
+// returns Purge.
+type AccountDeletionRepository struct {
+	Err       error
+	Purge     domain.AccountPurge
+	Purged    []string
+	deletions table[domain.AccountDeletion]
+}
+
+func NewAccountDeletionRepository() *AccountDeletionRepository {
+	return &AccountDeletionRepository{}
+}
+
+func pending(deletion domain.AccountDeletion) bool {
+	return deletion.CanceledAt == nil && deletion.CompletedAt == nil
+}
+
+func (repository *AccountDeletionRepository) Store(ctx context.Context, deletion *domain.AccountDeletion) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	deletion.ID = newID("deletion")
+	deletion.CreatedAt = now()
+	repository.deletions.put(deletion.ID, *deletion)
+
+	return nil
+}
+
+func (repository *AccountDeletionRepository) GetPending(ctx context.Context, deletion *domain.AccountDeletion, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.deletions.find(func(deletion domain.AccountDeletion) bool {
+		return deletion.UserID == userID && pending(deletion)
+	})
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*deletion = stored
+
+	return nil
+}
+
+func (repository *AccountDeletionRepository) FetchDue(ctx context.Context, deletions *[]domain.AccountDeletion, at time.Time, limit int) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*deletions = repository.deletions.list(func(deletion domain.AccountDeletion) bool {
+		return pending(deletion) && !deletion.PurgeAfter.After(at)
+	})
+
+	if len(*deletions) > limit {
+		*deletions = (*deletions)[:limit]
+	}
+
+	return nil
+}
+
+func (repository *AccountDeletionRepository) Cancel(ctx context.Context, id string, canceledAt time.Time) error {
+	return repository.close(id, func(deletion *domain.AccountDeletion) { deletion.CanceledAt = &canceledAt })
+}
+
+func (repository *AccountDeletionRepository) Complete(ctx context.Context, id string, completedAt time.Time) error {
+	return repository.close(id, func(deletion *domain.AccountDeletion) { deletion.CompletedAt = &completedAt })
+}
+
+func (repository *AccountDeletionRepository) close(id string, change func(*domain.AccountDeletion)) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	closed := false
+
+	repository.deletions.update(id, func(deletion *domain.AccountDeletion) {
+		if pending(*deletion) {
+			change(deletion)
+			closed = true
+		}
+	})
+
+	if !closed {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repository *AccountDeletionRepository) PurgeContent(ctx context.Context, userID string) (domain.AccountPurge, error) {
+	if repository.Err != nil {
+		return domain.AccountPurge{}, repository.Err
+	}
+
+	repository.Purged = append(repository.Purged, userID)
+
+	return repository.Purge, nil
+}
+
+type AuditRepository struct {
+	Err    error
+	events table[domain.AuditEvent]
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+func (repository *AuditRepository) Store(ctx context.Context, event *domain.AuditEvent) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	event.ID = newID("audit")
+	event.CreatedAt = now()
+	repository.events.put(event.ID, *event)
+
+	return nil
+}
+
+// Events returns the stored events in the order they were recorded.
+func (repository *AuditRepository) Events() []domain.AuditEvent {
+	return repository.events.list(nil)
+}
+
+type AccountDeletionUseCase struct {
+	ScheduleFunc   func(context.Context, string) (domain.AccountDeletion, error)
+	GetPendingFunc func(context.Context, *domain.AccountDeletion, string) error
+	CancelFunc     func(context.Context, string) error
+	PurgeDueFunc   func(context.Context, time.Time) (int, error)
+}
+
+func (useCase *AccountDeletionUseCase) Schedule(ctx context.Context, userID string) (domain.AccountDeletion, error) {
+	if useCase.ScheduleFunc == nil {
+		return domain.AccountDeletion{UserID: userID}, nil
+	}
+
+	return useCase.ScheduleFunc(ctx, userID)
+}
+
+func (useCase *AccountDeletionUseCase) GetPending(ctx context.Context, deletion *domain.AccountDeletion, userID string) error {
+	if useCase.GetPendingFunc == nil {
+		return nil
+	}
+
+	return useCase.GetPendingFunc(ctx, deletion, userID)
+}
+
+func (useCase *AccountDeletionUseCase) Cancel(ctx context.Context, userID string) error {
+	if useCase.CancelFunc == nil {
+		return nil
+	}
+
+	return useCase.CancelFunc(ctx, userID)
+}
+
+func (useCase *AccountDeletionUseCase) PurgeDue(ctx context.Context, at time.Time) (int, error) {
+	if useCase.PurgeDueFunc == nil {
+		return 0, nil
+	}
+
+	return useCase.PurgeDueFunc(ctx, at)
+}
@@ -0,0 +1,69 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EmailChangeRepository struct {
+	Err     error
+	changes table[domain.EmailChange]
+}
+
+func NewEmailChangeRepository() *EmailChangeRepository {
+	return &EmailChangeRepository{}
+}
+
+func (repository *EmailChangeRepository) Store(ctx context.Context, change *domain.EmailChange) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.changes.delete(func(c domain.EmailChange) bool { return c.UserID == change.UserID && c.ConfirmedAt == nil })
+
+	change.ID = newID("email")
+	change.CreatedAt = now()
+	repository.changes.put(change.ID, *change)
+
+	return nil
+}
+
+func (repository *EmailChangeRepository) GetPending(ctx context.Context, change *domain.EmailChange, tokenHash string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.changes.find(func(c domain.EmailChange) bool { return c.TokenHash == tokenHash && c.ConfirmedAt == nil })
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*change = stored
+
+	return nil
+}
+
+func (repository *EmailChangeRepository) Confirm(ctx context.Context, id string, at time.Time) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	confirmed := false
+
+	repository.changes.update(id, func(change *domain.EmailChange) {
+		if change.ConfirmedAt == nil {
+			change.ConfirmedAt = &at
+			confirmed = true
+		}
+	})
+
+	if !confirmed {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
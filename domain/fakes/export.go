@@ -0,0 +1,194 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataExportRepository keeps exports in memory. Collect returns Content for
+// every user.
+type DataExportRepository struct {
+	Err     error
+	Content domain.DataExportContent
+	exports table[domain.DataExport]
+}
+
+func NewDataExportRepository() *DataExportRepository {
+	return &DataExportRepository{}
+}
+
+func (repository *DataExportRepository) Store(ctx context.Context, export *domain.DataExport) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	export.ID = newID("export")
+	export.CreatedAt = now()
+	repository.exports.put(export.ID, *export)
+
+	return nil
+}
+
+func (repository *DataExportRepository) GetByID(ctx context.Context, export *domain.DataExport, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.exports.get(id)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*export = stored
+
+	return nil
+}
+
+func (repository *DataExportRepository) GetActive(ctx context.Context, export *domain.DataExport, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.exports.find(func(export domain.DataExport) bool {
+		return export.UserID == userID && (export.Status == domain.DataExportPending || export.Status == domain.DataExportRunning)
+	})
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*export = stored
+
+	return nil
+}
+
+func (repository *DataExportRepository) FetchPending(ctx context.Context, exports *[]domain.DataExport, limit int) error {
+	return repository.fetch(exports, limit, func(export domain.DataExport) bool {
+		return export.Status == domain.DataExportPending
+	})
+}
+
+func (repository *DataExportRepository) FetchExpired(ctx context.Context, exports *[]domain.DataExport, at time.Time, limit int) error {
+	return repository.fetch(exports, limit, func(export domain.DataExport) bool {
+		return export.Status == domain.DataExportReady && !export.ExpiresAt.After(at)
+	})
+}
+
+func (repository *DataExportRepository) FetchStale(ctx context.Context, exports *[]domain.DataExport, before time.Time, limit int) error {
+	return repository.fetch(exports, limit, func(export domain.DataExport) bool {
+		return export.Status == domain.DataExportRunning && (export.ClaimedAt == nil || export.ClaimedAt.Before(before))
+	})
+}
+
+func (repository *DataExportRepository) fetch(exports *[]domain.DataExport, limit int, match func(domain.DataExport) bool) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*exports = repository.exports.list(match)
+
+	if len(*exports) > limit {
+		*exports = (*exports)[:limit]
+	}
+
+	return nil
+}
+
+func (repository *DataExportRepository) Claim(ctx context.Context, id string, at time.Time) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	claimed := false
+
+	repository.exports.update(id, func(export *domain.DataExport) {
+		if export.Status == domain.DataExportPending {
+			export.Status = domain.DataExportRunning
+			export.ClaimedAt = &at
+			claimed = true
+		}
+	})
+
+	if !claimed {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repository *DataExportRepository) Update(ctx context.Context, export domain.DataExport) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.exports.update(export.ID, func(stored *domain.DataExport) {
+		stored.Status = export.Status
+		stored.BlobKey = export.BlobKey
+		stored.Error = export.Error
+		stored.CompletedAt = export.CompletedAt
+		stored.ExpiresAt = export.ExpiresAt
+	})
+
+	return nil
+}
+
+func (repository *DataExportRepository) Collect(ctx context.Context, userID string) (domain.DataExportContent, error) {
+	if repository.Err != nil {
+		return domain.DataExportContent{}, repository.Err
+	}
+
+	return repository.Content, nil
+}
+
+type DataExportUseCase struct {
+	RequestFunc func(context.Context, string) (domain.DataExport, error)
+	GetByIDFunc func(context.Context, *domain.DataExport, string, string) error
+	LinkFunc    func(domain.DataExport) domain.DataExportLink
+	OpenFunc    func(context.Context, string, domain.DataExportLink) (io.ReadCloser, error)
+	ProcessFunc func(context.Context) (int, error)
+}
+
+func (useCase *DataExportUseCase) Request(ctx context.Context, userID string) (domain.DataExport, error) {
+	if useCase.RequestFunc == nil {
+		return domain.DataExport{UserID: userID, Status: domain.DataExportPending}, nil
+	}
+
+	return useCase.RequestFunc(ctx, userID)
+}
+
+func (useCase *DataExportUseCase) GetByID(ctx context.Context, export *domain.DataExport, id string, userID string) error {
+	if useCase.GetByIDFunc == nil {
+		return nil
+	}
+
+	return useCase.GetByIDFunc(ctx, export, id, userID)
+}
+
+func (useCase *DataExportUseCase) Link(export domain.DataExport) domain.DataExportLink {
+	if useCase.LinkFunc == nil {
+		return domain.DataExportLink{}
+	}
+
+	return useCase.LinkFunc(export)
+}
+
+func (useCase *DataExportUseCase) Open(ctx context.Context, id string, link domain.DataExportLink) (io.ReadCloser, error) {
+	if useCase.OpenFunc == nil {
+		return nil, domain.ErrExportLinkInvalid
+	}
+
+	return useCase.OpenFunc(ctx, id, link)
+}
+
+func (useCase *DataExportUseCase) Process(ctx context.Context) (int, error) {
+	if useCase.ProcessFunc == nil {
+		return 0, nil
+	}
+
+	return useCase.ProcessFunc(ctx)
+}
@@ -0,0 +1,162 @@
+// Package fakes provides in-memory implementations of the domain
+// repositories and stub implementations of the domain usecases for tests.
+//
+// Repositories behave like their postgres counterparts: they assign prefixed
+// ids, set timestamps and report gorm.ErrRecordNotFound for missing rows.
+// Every repository has an Err field that, when set, is returned by every
+// method instead. Usecase stubs call the function field named after the
+// method, and succeed with zero values when it is nil.
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"fmt"
+	"sync"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+func newID(prefix string) string {
+	ID, _ := gonanoid.New(16)
+
+	return fmt.Sprintf("%s-%s", prefix, ID)
+}
+
+func now() *time.Time {
+	now := time.Now()
+
+	return &now
+}
+
+// table stores rows by id and remembers the order they were inserted in.
+type table[T any] struct {
+	mu    sync.Mutex
+	rows  map[string]T
+	order []string
+}
+
+func (table *table[T]) put(id string, row T) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	if table.rows == nil {
+		table.rows = map[string]T{}
+	}
+
+	if _, ok := table.rows[id]; !ok {
+		table.order = append(table.order, id)
+	}
+
+	table.rows[id] = row
+}
+
+func (table *table[T]) get(id string) (T, bool) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	row, ok := table.rows[id]
+
+	return row, ok
+}
+
+// find returns the first row matching match.
+func (table *table[T]) find(match func(T) bool) (T, bool) {
+	for _, row := range table.list(match) {
+		return row, true
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// list returns the rows matching match, or every row when match is nil, in
+// insertion order.
+func (table *table[T]) list(match func(T) bool) []T {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	rows := []T{}
+
+	for _, id := range table.order {
+		if row := table.rows[id]; match == nil || match(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// update applies change to the row with id and reports whether it exists.
+func (table *table[T]) update(id string, change func(*T)) bool {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	row, ok := table.rows[id]
+
+	if !ok {
+		return false
+	}
+
+	change(&row)
+	table.rows[id] = row
+
+	return true
+}
+
+func (table *table[T]) delete(match func(T) bool) int {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	deleted := 0
+	order := table.order[:0]
+
+	for _, id := range table.order {
+		if match(table.rows[id]) {
+			delete(table.rows, id)
+			deleted++
+
+			continue
+		}
+
+		order = append(order, id)
+	}
+
+	table.order = order
+
+	return deleted
+}
+
+var (
+	_ domain.UserRepository            = (*UserRepository)(nil)
+	_ domain.LoginAttemptRepository    = (*LoginAttemptRepository)(nil)
+	_ domain.MFARepository             = (*MFARepository)(nil)
+	_ domain.APIKeyRepository          = (*APIKeyRepository)(nil)
+	_ domain.SessionRepository         = (*SessionRepository)(nil)
+	_ domain.IdentityRepository        = (*IdentityRepository)(nil)
+	_ domain.PhotoRepository           = (*PhotoRepository)(nil)
+	_ domain.CommentRepository         = (*CommentRepository)(nil)
+	_ domain.SocialMediaRepository     = (*SocialMediaRepository)(nil)
+	_ domain.AccountDeletionRepository = (*AccountDeletionRepository)(nil)
+	_ domain.DataExportRepository      = (*DataExportRepository)(nil)
+	_ domain.AuditRepository           = (*AuditRepository)(nil)
+	_ domain.EmailChangeRepository     = (*EmailChangeRepository)(nil)
+	_ domain.BlobStore                 = (*BlobStore)(nil)
+	_ domain.Mailer                    = (*Mailer)(nil)
+	_ domain.ImageFetcher              = ImageFetcher{}
+
+	_ domain.UserUseCase            = (*UserUseCase)(nil)
+	_ domain.MFAUseCase             = (*MFAUseCase)(nil)
+	_ domain.APIKeyUseCase          = (*APIKeyUseCase)(nil)
+	_ domain.SessionUseCase         = (*SessionUseCase)(nil)
+	_ domain.AuthUseCase            = (*AuthUseCase)(nil)
+	_ domain.PhotoUseCase           = (*PhotoUseCase)(nil)
+	_ domain.CommentUseCase         = (*CommentUseCase)(nil)
+	_ domain.SocialMediaUseCase     = (*SocialMediaUseCase)(nil)
+	_ domain.AccountDeletionUseCase = (*AccountDeletionUseCase)(nil)
+	_ domain.DataExportUseCase      = (*DataExportUseCase)(nil)
+	_ domain.AvatarUseCase          = (*AvatarUseCase)(nil)
+
+	_ domain.TxManager = TxManager{}
+)
@@ -0,0 +1,59 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type IdentityRepository struct {
+	Err        error
+	identities table[domain.Identity]
+}
+
+func NewIdentityRepository() *IdentityRepository {
+	return &IdentityRepository{}
+}
+
+func (repository *IdentityRepository) GetByProviderSubject(ctx context.Context, identity *domain.Identity, provider string, subject string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.identities.find(func(identity domain.Identity) bool {
+		return identity.Provider == provider && identity.Subject == subject
+	})
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*identity = stored
+
+	return nil
+}
+
+func (repository *IdentityRepository) Store(ctx context.Context, identity *domain.Identity) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	identity.ID = newID("identity")
+	identity.CreatedAt, identity.UpdatedAt = now(), now()
+	repository.identities.put(identity.ID, *identity)
+
+	return nil
+}
+
+type AuthUseCase struct {
+	SignInWithIdentityFunc func(context.Context, domain.ExternalIdentity, *domain.User) error
+}
+
+func (useCase *AuthUseCase) SignInWithIdentity(ctx context.Context, identity domain.ExternalIdentity, user *domain.User) error {
+	if useCase.SignInWithIdentityFunc == nil {
+		return nil
+	}
+
+	return useCase.SignInWithIdentityFunc(ctx, identity, user)
+}
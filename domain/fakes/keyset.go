@@ -0,0 +1,24 @@
+package fakes
+
+import (
+	"api-mygram-go/helpers"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// KeySet returns a key set that signs HS256 tokens with a fixed test key.
+func KeySet() *helpers.KeySet {
+	keySet, err := helpers.NewKeySet(helpers.TokenOptions{Issuer: "mygram", Audience: "mygram-api", TTL: time.Hour}, "test", helpers.SigningKey{
+		ID:         "test",
+		Method:     jwt.SigningMethodHS256,
+		PrivateKey: []byte("test-token-key"),
+		PublicKey:  []byte("test-token-key"),
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	return keySet
+}
@@ -0,0 +1,86 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"time"
+)
+
+type LoginAttemptRepository struct {
+	Err       error
+	throttles table[domain.LoginThrottle]
+	events    table[domain.LoginEvent]
+}
+
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{}
+}
+
+// Events returns the recorded login events, oldest first.
+func (repository *LoginAttemptRepository) Events() []domain.LoginEvent {
+	return repository.events.list(nil)
+}
+
+func (repository *LoginAttemptRepository) GetThrottle(ctx context.Context, throttle *domain.LoginThrottle, key string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.throttles.get(key)
+
+	if !ok {
+		stored = domain.LoginThrottle{Key: key}
+	}
+
+	*throttle = stored
+
+	return nil
+}
+
+func (repository *LoginAttemptRepository) RecordFailure(ctx context.Context, key string) (int, error) {
+	if repository.Err != nil {
+		return 0, repository.Err
+	}
+
+	throttle, _ := repository.throttles.get(key)
+	throttle.Key = key
+	throttle.Failures++
+	throttle.UpdatedAt = now()
+	repository.throttles.put(key, throttle)
+
+	return throttle.Failures, nil
+}
+
+func (repository *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.throttles.update(key, func(throttle *domain.LoginThrottle) {
+		throttle.LockedUntil = &until
+	})
+
+	return nil
+}
+
+func (repository *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.throttles.delete(func(throttle domain.LoginThrottle) bool { return throttle.Key == key })
+
+	return nil
+}
+
+func (repository *LoginAttemptRepository) StoreEvent(ctx context.Context, event *domain.LoginEvent) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	event.ID = newID("loginevent")
+	event.CreatedAt = now()
+	repository.events.put(event.ID, *event)
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"sync"
+)
+
+// Mailer keeps the mails it is asked to send.
+type Mailer struct {
+	Err   error
+	mu    sync.Mutex
+	mails []domain.Mail
+}
+
+func (mailer *Mailer) Send(ctx context.Context, mail domain.Mail) error {
+	if mailer.Err != nil {
+		return mailer.Err
+	}
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	mailer.mails = append(mailer.mails, mail)
+
+	return nil
+}
+
+// Mails returns the mails sent so far.
+func (mailer *Mailer) Mails() []domain.Mail {
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	return append([]domain.Mail(nil), mailer.mails...)
+}
@@ -0,0 +1,143 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MFARepository struct {
+	Err           error
+	mfas          table[domain.UserMFA]
+	recoveryCodes table[domain.RecoveryCode]
+}
+
+func NewMFARepository() *MFARepository {
+	return &MFARepository{}
+}
+
+func (repository *MFARepository) GetByUserID(ctx context.Context, mfa *domain.UserMFA, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.mfas.get(userID)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*mfa = stored
+
+	return nil
+}
+
+func (repository *MFARepository) Save(ctx context.Context, mfa *domain.UserMFA) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if _, ok := repository.mfas.get(mfa.UserID); !ok {
+		mfa.CreatedAt = now()
+	}
+
+	mfa.UpdatedAt = now()
+	repository.mfas.put(mfa.UserID, *mfa)
+
+	return nil
+}
+
+func (repository *MFARepository) UseStep(ctx context.Context, userID string, step int64) (used bool, err error) {
+	if repository.Err != nil {
+		return false, repository.Err
+	}
+
+	repository.mfas.update(userID, func(mfa *domain.UserMFA) {
+		if mfa.LastUsedStep < step {
+			mfa.LastUsedStep = step
+			used = true
+		}
+	})
+
+	return used, nil
+}
+
+func (repository *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []domain.RecoveryCode) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.recoveryCodes.delete(func(code domain.RecoveryCode) bool { return code.UserID == userID })
+
+	for i := range codes {
+		codes[i].ID = newID("recoverycode")
+		codes[i].UserID = userID
+		codes[i].CreatedAt = now()
+		repository.recoveryCodes.put(codes[i].ID, codes[i])
+	}
+
+	return nil
+}
+
+func (repository *MFARepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (used bool, err error) {
+	if repository.Err != nil {
+		return false, repository.Err
+	}
+
+	code, ok := repository.recoveryCodes.find(func(code domain.RecoveryCode) bool {
+		return code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil
+	})
+
+	if !ok {
+		return false, nil
+	}
+
+	usedAt := time.Now()
+
+	repository.recoveryCodes.update(code.ID, func(code *domain.RecoveryCode) {
+		code.UsedAt = &usedAt
+	})
+
+	return true, nil
+}
+
+type MFAUseCase struct {
+	EnrollTOTPFunc  func(context.Context, string, string) (domain.TOTPEnrollment, error)
+	ConfirmTOTPFunc func(context.Context, string, string) ([]string, error)
+	IsEnabledFunc   func(context.Context, string) (bool, error)
+	VerifyFunc      func(context.Context, string, string) error
+}
+
+func (useCase *MFAUseCase) EnrollTOTP(ctx context.Context, userID string, email string) (domain.TOTPEnrollment, error) {
+	if useCase.EnrollTOTPFunc == nil {
+		return domain.TOTPEnrollment{}, nil
+	}
+
+	return useCase.EnrollTOTPFunc(ctx, userID, email)
+}
+
+func (useCase *MFAUseCase) ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	if useCase.ConfirmTOTPFunc == nil {
+		return nil, nil
+	}
+
+	return useCase.ConfirmTOTPFunc(ctx, userID, code)
+}
+
+func (useCase *MFAUseCase) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	if useCase.IsEnabledFunc == nil {
+		return false, nil
+	}
+
+	return useCase.IsEnabledFunc(ctx, userID)
+}
+
+func (useCase *MFAUseCase) Verify(ctx context.Context, userID string, code string) error {
+	if useCase.VerifyFunc == nil {
+		return nil
+	}
+
+	return useCase.VerifyFunc(ctx, userID, code)
+}
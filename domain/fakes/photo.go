@@ -0,0 +1,290 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"errors"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// PhotoRepository preloads the User of fetched photos from Users, falling
+// back to a user with only an id when Users is nil or doesn't know them.
+type PhotoRepository struct {
+	Err    error
+	Users  *UserRepository
+	photos table[domain.Photo]
+}
+
+func NewPhotoRepository(users *UserRepository, photos ...domain.Photo) *PhotoRepository {
+	repository := &PhotoRepository{Users: users}
+
+	for _, photo := range photos {
+		repository.Put(photo)
+	}
+
+	return repository
+}
+
+func (repository *PhotoRepository) Put(photo domain.Photo) {
+	repository.photos.put(photo.ID, photo)
+}
+
+func (repository *PhotoRepository) Fetch(ctx context.Context, photos *[]domain.Photo, filter domain.PhotoFilter) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*photos = repository.photos.list(nil)
+
+	if filter.Near != nil {
+		*photos = domain.PhotosNear(*photos, *filter.Near, filter.RadiusKm)
+	}
+
+	for i := range *photos {
+		(*photos)[i].User = preloadUser(ctx, repository.Users, (*photos)[i].UserID)
+	}
+
+	return nil
+}
+
+func (repository *PhotoRepository) Store(ctx context.Context, photo *domain.Photo) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	photo.ID = newID("photo")
+	photo.CreatedAt, photo.UpdatedAt = now(), now()
+
+	if photo.MetadataStatus == "" {
+		photo.MetadataStatus = domain.PhotoMetadataPending
+	}
+	repository.Put(*photo)
+
+	return nil
+}
+
+func (repository *PhotoRepository) StoreBatch(ctx context.Context, photos []domain.Photo) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	for i := range photos {
+		if err := photos[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for i := range photos {
+		if err := repository.Store(ctx, &photos[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *PhotoRepository) GetByID(ctx context.Context, photo *domain.Photo, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.photos.get(id)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*photo = stored
+
+	return nil
+}
+
+func (repository *PhotoRepository) Update(ctx context.Context, photo domain.Photo, id string) (p domain.Photo, err error) {
+	if repository.Err != nil {
+		return p, repository.Err
+	}
+
+	updated := repository.photos.update(id, func(stored *domain.Photo) {
+		if photo.Title != "" {
+			stored.Title = photo.Title
+		}
+
+		if photo.Caption != "" {
+			stored.Caption = photo.Caption
+		}
+
+		if photo.PhotoUrl != "" {
+			stored.PhotoUrl = photo.PhotoUrl
+		}
+
+		stored.UpdatedAt = now()
+		p = *stored
+	})
+
+	if !updated {
+		return p, gorm.ErrRecordNotFound
+	}
+
+	return p, nil
+}
+
+func (repository *PhotoRepository) Delete(ctx context.Context, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if repository.photos.delete(func(p domain.Photo) bool { return p.ID == id }) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repository *PhotoRepository) FetchMetadataPending(ctx context.Context, photos *[]domain.Photo, limit int) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*photos = repository.photos.list(func(p domain.Photo) bool { return p.MetadataStatus == domain.PhotoMetadataPending })
+
+	if len(*photos) > limit {
+		*photos = (*photos)[:limit]
+	}
+
+	return nil
+}
+
+func (repository *PhotoRepository) UpdateMetadata(ctx context.Context, photo domain.Photo) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	updated := false
+
+	repository.photos.update(photo.ID, func(stored *domain.Photo) {
+		if stored.PhotoUrl != photo.PhotoUrl {
+			return
+		}
+
+		stored.PhotoMetadata = photo.PhotoMetadata
+		stored.MetadataStatus = photo.MetadataStatus
+		stored.MetadataError = photo.MetadataError
+		updated = true
+	})
+
+	if !updated {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func preloadUser(ctx context.Context, users *UserRepository, id string) *domain.User {
+	user := domain.User{ID: id}
+
+	if users != nil {
+		_ = users.GetByID(ctx, &user, id)
+	}
+
+	return &domain.User{ID: user.ID, Username: user.Username, Email: user.Email, ProfileImageUrl: user.ProfileImageUrl}
+}
+
+type PhotoUseCase struct {
+	FetchFunc           func(context.Context, *[]domain.Photo, domain.PhotoFilter) error
+	StoreFunc           func(context.Context, *domain.Photo) error
+	GetByIDFunc         func(context.Context, *domain.Photo, string) error
+	UpdateFunc          func(context.Context, domain.Photo, string) (domain.Photo, error)
+	DeleteFunc          func(context.Context, string) error
+	ImportFunc          func(context.Context, string, []domain.PhotoImportRow, bool) (domain.PhotoImportReport, error)
+	UploadFunc          func(context.Context, *domain.PhotoUpload) error
+	OpenFunc            func(context.Context, string, string) (io.ReadCloser, error)
+	ProcessMetadataFunc func(context.Context) (int, error)
+}
+
+func (useCase *PhotoUseCase) Fetch(ctx context.Context, photos *[]domain.Photo, filter domain.PhotoFilter) error {
+	if useCase.FetchFunc == nil {
+		return nil
+	}
+
+	return useCase.FetchFunc(ctx, photos, filter)
+}
+
+func (useCase *PhotoUseCase) Store(ctx context.Context, photo *domain.Photo) error {
+	if useCase.StoreFunc == nil {
+		return nil
+	}
+
+	return useCase.StoreFunc(ctx, photo)
+}
+
+func (useCase *PhotoUseCase) GetByID(ctx context.Context, photo *domain.Photo, id string) error {
+	if useCase.GetByIDFunc == nil {
+		return nil
+	}
+
+	return useCase.GetByIDFunc(ctx, photo, id)
+}
+
+func (useCase *PhotoUseCase) Update(ctx context.Context, photo domain.Photo, id string) (domain.Photo, error) {
+	if useCase.UpdateFunc == nil {
+		return photo, nil
+	}
+
+	return useCase.UpdateFunc(ctx, photo, id)
+}
+
+func (useCase *PhotoUseCase) Delete(ctx context.Context, id string) error {
+	if useCase.DeleteFunc == nil {
+		return nil
+	}
+
+	return useCase.DeleteFunc(ctx, id)
+}
+
+func (useCase *PhotoUseCase) Import(ctx context.Context, userID string, rows []domain.PhotoImportRow, dryRun bool) (domain.PhotoImportReport, error) {
+	if useCase.ImportFunc == nil {
+		return domain.PhotoImportReport{DryRun: dryRun}, nil
+	}
+
+	return useCase.ImportFunc(ctx, userID, rows, dryRun)
+}
+
+func (useCase *PhotoUseCase) Upload(ctx context.Context, upload *domain.PhotoUpload) error {
+	if useCase.UploadFunc == nil {
+		return nil
+	}
+
+	return useCase.UploadFunc(ctx, upload)
+}
+
+func (useCase *PhotoUseCase) Open(ctx context.Context, userID string, file string) (io.ReadCloser, error) {
+	if useCase.OpenFunc == nil {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return useCase.OpenFunc(ctx, userID, file)
+}
+
+func (useCase *PhotoUseCase) ProcessMetadata(ctx context.Context) (int, error) {
+	if useCase.ProcessMetadataFunc == nil {
+		return 0, nil
+	}
+
+	return useCase.ProcessMetadataFunc(ctx)
+}
+
+// ImageFetcher serves the images of Images by URL, and fails for any other
+// URL.
+type ImageFetcher struct {
+	Images map[string][]byte
+}
+
+func (fetcher ImageFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	if data, ok := fetcher.Images[url]; ok {
+		return data, nil
+	}
+
+	return nil, errors.New("the server answered 404 Not Found")
+}
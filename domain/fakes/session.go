@@ -0,0 +1,132 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	Err      error
+	sessions table[domain.Session]
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+func (repository *SessionRepository) Store(ctx context.Context, session *domain.Session) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	session.ID = newID("session")
+	session.CreatedAt = now()
+	repository.sessions.put(session.ID, *session)
+
+	return nil
+}
+
+func (repository *SessionRepository) Fetch(ctx context.Context, sessions *[]domain.Session, userID string, at time.Time) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*sessions = repository.sessions.list(func(session domain.Session) bool {
+		return session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(at)
+	})
+
+	return nil
+}
+
+func (repository *SessionRepository) GetByID(ctx context.Context, session *domain.Session, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.sessions.get(id)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*session = stored
+
+	return nil
+}
+
+func (repository *SessionRepository) Revoke(ctx context.Context, id string, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	revoked := false
+
+	repository.sessions.update(id, func(session *domain.Session) {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = now()
+			revoked = true
+		}
+	})
+
+	if !revoked {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repository *SessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	repository.sessions.update(id, func(session *domain.Session) {
+		session.LastSeenAt = &seenAt
+	})
+
+	return nil
+}
+
+type SessionUseCase struct {
+	StartFunc    func(context.Context, string, domain.LoginInfo) (domain.Session, error)
+	FetchFunc    func(context.Context, *[]domain.Session, string) error
+	RevokeFunc   func(context.Context, string, string) error
+	ValidateFunc func(context.Context, string) error
+}
+
+// Start returns a session with the id "session-test" unless StartFunc is
+// set.
+func (useCase *SessionUseCase) Start(ctx context.Context, userID string, info domain.LoginInfo) (domain.Session, error) {
+	if useCase.StartFunc == nil {
+		return domain.Session{ID: "session-test", UserID: userID, DeviceName: info.DeviceName}, nil
+	}
+
+	return useCase.StartFunc(ctx, userID, info)
+}
+
+func (useCase *SessionUseCase) Fetch(ctx context.Context, sessions *[]domain.Session, userID string) error {
+	if useCase.FetchFunc == nil {
+		return nil
+	}
+
+	return useCase.FetchFunc(ctx, sessions, userID)
+}
+
+func (useCase *SessionUseCase) Revoke(ctx context.Context, id string, userID string) error {
+	if useCase.RevokeFunc == nil {
+		return nil
+	}
+
+	return useCase.RevokeFunc(ctx, id, userID)
+}
+
+func (useCase *SessionUseCase) Validate(ctx context.Context, id string) error {
+	if useCase.ValidateFunc == nil {
+		return nil
+	}
+
+	return useCase.ValidateFunc(ctx, id)
+}
@@ -0,0 +1,157 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// SocialMediaRepository preloads the User of fetched social medias from
+// Users.
+type SocialMediaRepository struct {
+	Err          error
+	Users        *UserRepository
+	socialMedias table[domain.SocialMedia]
+}
+
+func NewSocialMediaRepository(users *UserRepository, socialMedias ...domain.SocialMedia) *SocialMediaRepository {
+	repository := &SocialMediaRepository{Users: users}
+
+	for _, socialMedia := range socialMedias {
+		repository.Put(socialMedia)
+	}
+
+	return repository
+}
+
+func (repository *SocialMediaRepository) Put(socialMedia domain.SocialMedia) {
+	repository.socialMedias.put(socialMedia.ID, socialMedia)
+}
+
+func (repository *SocialMediaRepository) Fetch(ctx context.Context, socialMedias *[]domain.SocialMedia, userID string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	*socialMedias = repository.socialMedias.list(func(s domain.SocialMedia) bool { return s.UserID == userID })
+
+	for i := range *socialMedias {
+		(*socialMedias)[i].User = preloadUser(ctx, repository.Users, (*socialMedias)[i].UserID)
+	}
+
+	return nil
+}
+
+func (repository *SocialMediaRepository) Store(ctx context.Context, socialMedia *domain.SocialMedia) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	socialMedia.ID = newID("socialmedia")
+	socialMedia.CreatedAt, socialMedia.UpdatedAt = now(), now()
+	repository.Put(*socialMedia)
+
+	return nil
+}
+
+func (repository *SocialMediaRepository) GetByID(ctx context.Context, socialMedia *domain.SocialMedia, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.socialMedias.get(id)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*socialMedia = stored
+
+	return nil
+}
+
+func (repository *SocialMediaRepository) Update(ctx context.Context, socialMedia domain.SocialMedia, id string) (s domain.SocialMedia, err error) {
+	if repository.Err != nil {
+		return s, repository.Err
+	}
+
+	updated := repository.socialMedias.update(id, func(stored *domain.SocialMedia) {
+		if socialMedia.Name != "" {
+			stored.Name = socialMedia.Name
+		}
+
+		if socialMedia.SocialMediaUrl != "" {
+			stored.SocialMediaUrl = socialMedia.SocialMediaUrl
+		}
+
+		stored.UpdatedAt = now()
+		s = *stored
+	})
+
+	if !updated {
+		return s, gorm.ErrRecordNotFound
+	}
+
+	return s, nil
+}
+
+func (repository *SocialMediaRepository) Delete(ctx context.Context, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if repository.socialMedias.delete(func(s domain.SocialMedia) bool { return s.ID == id }) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type SocialMediaUseCase struct {
+	FetchFunc   func(context.Context, *[]domain.SocialMedia, string) error
+	StoreFunc   func(context.Context, *domain.SocialMedia) error
+	GetByIDFunc func(context.Context, *domain.SocialMedia, string) error
+	UpdateFunc  func(context.Context, domain.SocialMedia, string) (domain.SocialMedia, error)
+	DeleteFunc  func(context.Context, string) error
+}
+
+func (useCase *SocialMediaUseCase) Fetch(ctx context.Context, socialMedias *[]domain.SocialMedia, userID string) error {
+	if useCase.FetchFunc == nil {
+		return nil
+	}
+
+	return useCase.FetchFunc(ctx, socialMedias, userID)
+}
+
+func (useCase *SocialMediaUseCase) Store(ctx context.Context, socialMedia *domain.SocialMedia) error {
+	if useCase.StoreFunc == nil {
+		return nil
+	}
+
+	return useCase.StoreFunc(ctx, socialMedia)
+}
+
+func (useCase *SocialMediaUseCase) GetByID(ctx context.Context, socialMedia *domain.SocialMedia, id string) error {
+	if useCase.GetByIDFunc == nil {
+		return nil
+	}
+
+	return useCase.GetByIDFunc(ctx, socialMedia, id)
+}
+
+func (useCase *SocialMediaUseCase) Update(ctx context.Context, socialMedia domain.SocialMedia, id string) (domain.SocialMedia, error) {
+	if useCase.UpdateFunc == nil {
+		return socialMedia, nil
+	}
+
+	return useCase.UpdateFunc(ctx, socialMedia, id)
+}
+
+func (useCase *SocialMediaUseCase) Delete(ctx context.Context, id string) error {
+	if useCase.DeleteFunc == nil {
+		return nil
+	}
+
+	return useCase.DeleteFunc(ctx, id)
+}
@@ -0,0 +1,11 @@
+package fakes
+
+import "context"
+
+// TxManager runs units of work directly, as the in-memory repositories have
+// nothing to roll back.
+type TxManager struct{}
+
+func (TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
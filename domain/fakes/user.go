@@ -0,0 +1,211 @@
+package fakes
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"context"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// passwordHasher hashes with the lowest bcrypt cost to keep tests fast.
+var passwordHasher = helpers.NewBcryptHasher(4)
+
+type UserRepository struct {
+	Err   error
+	users table[domain.User]
+}
+
+func NewUserRepository(users ...domain.User) *UserRepository {
+	repository := &UserRepository{}
+
+	for _, user := range users {
+		repository.Put(user)
+	}
+
+	return repository
+}
+
+// Put stores user as is, keeping its id and password.
+func (repository *UserRepository) Put(user domain.User) {
+	repository.users.put(user.ID, user)
+}
+
+func (repository *UserRepository) Register(ctx context.Context, user *domain.User) (err error) {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if _, taken := repository.users.find(func(u domain.User) bool { return u.Username == user.Username }); taken {
+		return domain.ErrUsernameTaken
+	}
+
+	if _, taken := repository.users.find(func(u domain.User) bool { return strings.EqualFold(u.Email, user.Email) }); taken {
+		return domain.ErrEmailTaken
+	}
+
+	if user.Password, err = passwordHasher.Hash(user.Password); err != nil {
+		return err
+	}
+
+	user.ID = newID("user")
+	user.CreatedAt, user.UpdatedAt = now(), now()
+	repository.Put(*user)
+
+	return nil
+}
+
+func (repository *UserRepository) Login(ctx context.Context, user *domain.User) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.users.find(func(u domain.User) bool { return strings.EqualFold(u.Email, strings.TrimSpace(user.Email)) })
+
+	if !ok || !passwordHasher.Compare([]byte(stored.Password), []byte(user.Password)) {
+		return domain.ErrInvalidCredentials
+	}
+
+	*user = stored
+
+	return nil
+}
+
+func (repository *UserRepository) GetByID(ctx context.Context, user *domain.User, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.users.get(id)
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*user = stored
+
+	return nil
+}
+
+func (repository *UserRepository) GetByEmail(ctx context.Context, user *domain.User, email string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	stored, ok := repository.users.find(func(u domain.User) bool { return strings.EqualFold(u.Email, email) })
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*user = stored
+
+	return nil
+}
+
+// Update writes the profile fields of the user with user.ID, including the
+// empty ones.
+func (repository *UserRepository) Update(ctx context.Context, user domain.User) (u domain.User, err error) {
+	if repository.Err != nil {
+		return u, repository.Err
+	}
+
+	if _, taken := repository.users.find(func(stored domain.User) bool { return stored.ID != user.ID && stored.Username == user.Username }); taken {
+		return u, domain.ErrUsernameTaken
+	}
+
+	if _, taken := repository.users.find(func(stored domain.User) bool { return stored.ID != user.ID && strings.EqualFold(stored.Email, user.Email) }); taken {
+		return u, domain.ErrEmailTaken
+	}
+
+	updated := repository.users.update(user.ID, func(stored *domain.User) {
+		stored.Username = user.Username
+		stored.Email = user.Email
+		stored.Age = user.Age
+		stored.ProfileImageUrl = user.ProfileImageUrl
+		stored.DisplayName = user.DisplayName
+		stored.Bio = user.Bio
+		stored.UpdatedAt = now()
+		u = *stored
+	})
+
+	if !updated {
+		return u, gorm.ErrRecordNotFound
+	}
+
+	return u, nil
+}
+
+func (repository *UserRepository) Delete(ctx context.Context, id string) error {
+	if repository.Err != nil {
+		return repository.Err
+	}
+
+	if repository.users.delete(func(u domain.User) bool { return u.ID == id }) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type UserUseCase struct {
+	RegisterFunc     func(context.Context, *domain.User) error
+	LoginFunc        func(context.Context, *domain.User, domain.LoginInfo) error
+	UpdateFunc       func(context.Context, string, domain.UserUpdate) (domain.User, *domain.EmailChange, error)
+	ConfirmEmailFunc func(context.Context, string) (domain.User, error)
+}
+
+func (useCase *UserUseCase) Register(ctx context.Context, user *domain.User) error {
+	if useCase.RegisterFunc == nil {
+		return nil
+	}
+
+	return useCase.RegisterFunc(ctx, user)
+}
+
+func (useCase *UserUseCase) Login(ctx context.Context, user *domain.User, info domain.LoginInfo) error {
+	if useCase.LoginFunc == nil {
+		return nil
+	}
+
+	return useCase.LoginFunc(ctx, user, info)
+}
+
+func (useCase *UserUseCase) Update(ctx context.Context, userID string, update domain.UserUpdate) (domain.User, *domain.EmailChange, error) {
+	if useCase.UpdateFunc == nil {
+		return domain.User{ID: userID}, nil, nil
+	}
+
+	return useCase.UpdateFunc(ctx, userID, update)
+}
+
+func (useCase *UserUseCase) ConfirmEmail(ctx context.Context, token string) (domain.User, error) {
+	if useCase.ConfirmEmailFunc == nil {
+		return domain.User{}, nil
+	}
+
+	return useCase.ConfirmEmailFunc(ctx, token)
+}
+
+type AvatarUseCase struct {
+	SetFunc  func(context.Context, string, io.Reader) (domain.User, error)
+	OpenFunc func(context.Context, string, string) (io.ReadCloser, error)
+}
+
+func (useCase *AvatarUseCase) Set(ctx context.Context, userID string, r io.Reader) (domain.User, error) {
+	if useCase.SetFunc == nil {
+		return domain.User{ID: userID}, nil
+	}
+
+	return useCase.SetFunc(ctx, userID, r)
+}
+
+func (useCase *AvatarUseCase) Open(ctx context.Context, userID string, file string) (io.ReadCloser, error) {
+	if useCase.OpenFunc == nil {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return useCase.OpenFunc(ctx, userID, file)
+}
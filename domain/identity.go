@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrIdentityAgeRequired = errors.New("no account is linked to this identity yet, sign in again with your age to create one")
+
+type Identity struct {
+	ID        string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID    string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	Provider  string     `gorm:"type:VARCHAR(50);not null;uniqueIndex:idx_identities_provider_subject" json:"provider"`
+	Subject   string     `gorm:"not null;uniqueIndex:idx_identities_provider_subject" json:"subject"`
+	Email     string     `json:"email"`
+	CreatedAt *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt *time.Time `gorm:"not null;autoUpdateTime" json:"updated_at,omitempty"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+type ExternalIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+type AuthUseCase interface {
+	SignInWithIdentity(context.Context, ExternalIdentity, *User) error
+}
+
+type IdentityRepository interface {
+	GetByProviderSubject(context.Context, *Identity, string, string) error
+	Store(context.Context, *Identity) error
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (err *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", int(err.RetryAfter.Seconds()+0.5))
+}
+
+type LoginInfo struct {
+	IPAddress  string
+	UserAgent  string
+	DeviceName string
+}
+
+type LoginThrottle struct {
+	Key         string     `gorm:"primaryKey;type:VARCHAR(120)" json:"key"`
+	Failures    int        `gorm:"not null;default:0" json:"failures"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt   *time.Time `gorm:"not null;autoUpdateTime" json:"updated_at,omitempty"`
+}
+
+// EmailThrottleKey is the key the failed logins to the account with email
+// are counted under.
+func EmailThrottleKey(email string) string {
+	return throttleKey("email", strings.ToLower(strings.TrimSpace(email)))
+}
+
+// IPThrottleKey is the key the failed logins from ipAddress are counted
+// under.
+func IPThrottleKey(ipAddress string) string {
+	return throttleKey("ip", ipAddress)
+}
+
+// throttleKey hashes value, which comes from the client, so the key fits its
+// column whatever its length.
+func throttleKey(kind string, value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
+
+type LoginEvent struct {
+	ID        string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID    string     `gorm:"type:VARCHAR(50);index" json:"user_id,omitempty"`
+	Email     string     `gorm:"type:VARCHAR(50);index" json:"email"`
+	IPAddress string     `gorm:"type:VARCHAR(64)" json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	Success   bool       `gorm:"not null" json:"success"`
+	Reason    string     `gorm:"type:VARCHAR(50)" json:"reason,omitempty"`
+	CreatedAt *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+}
+
+type LoginAttemptRepository interface {
+	GetThrottle(context.Context, *LoginThrottle, string) error
+	RecordFailure(context.Context, string) (int, error)
+	Lock(context.Context, string, time.Time) error
+	Reset(context.Context, string) error
+	StoreEvent(context.Context, *LoginEvent) error
+}
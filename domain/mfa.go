@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("start two-factor enrollment before confirming it")
+	ErrInvalidMFACode    = errors.New("the authentication code you entered is invalid")
+)
+
+type UserMFA struct {
+	UserID       string     `gorm:"primaryKey;type:VARCHAR(50)" json:"user_id"`
+	TOTPSecret   string     `gorm:"type:VARCHAR(128);not null" json:"-"`
+	Enabled      bool       `gorm:"not null;default:false" json:"enabled"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `gorm:"not null;autoUpdateTime" json:"updated_at,omitempty"`
+	User         *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+type RecoveryCode struct {
+	ID        string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID    string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:VARCHAR(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+type MFAUseCase interface {
+	EnrollTOTP(context.Context, string, string) (TOTPEnrollment, error)
+	ConfirmTOTP(context.Context, string, string) ([]string, error)
+	IsEnabled(context.Context, string) (bool, error)
+	Verify(context.Context, string, string) error
+}
+
+type MFARepository interface {
+	GetByUserID(context.Context, *UserMFA, string) error
+	Save(context.Context, *UserMFA) error
+	UseStep(context.Context, string, int64) (bool, error)
+	ReplaceRecoveryCodes(context.Context, string, []RecoveryCode) error
+	UseRecoveryCode(context.Context, string, string) (bool, error)
+}
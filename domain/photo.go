@@ -1,16 +1,59 @@
 package domain
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/asaskevich/govalidator"
 	"gorm.io/gorm"
 )
 
+var ErrInvalidPhoto = errors.New("the photo must be a JPEG, PNG, GIF or WebP image")
+
+// Metadata statuses of a photo. The metadata of an uploaded photo is read
+// right away, that of a photo given by URL once a worker fetches it.
+const (
+	PhotoMetadataPending = "pending"
+	PhotoMetadataReady   = "ready"
+	PhotoMetadataFailed  = "failed"
+)
+
+// PhotoPathPrefix starts the paths uploaded photos are served from.
+const PhotoPathPrefix = "/images/"
+
+// PhotoKey returns the blob key of an uploaded photo file.
+func PhotoKey(userID string, file string) string {
+	return fmt.Sprintf("%sphotos/%s", UserBlobPrefix(userID), file)
+}
+
+// PhotoPath returns the path an uploaded photo file is served from.
+func PhotoPath(userID string, file string) string {
+	return fmt.Sprintf("%s%s/%s", PhotoPathPrefix, userID, file)
+}
+
+// PhotoMetadata is what the image of a photo says about itself. CameraModel,
+// TakenAt and the coordinates come from EXIF and are only known for some
+// JPEG images.
+type PhotoMetadata struct {
+	Width       int        `json:"width,omitempty"`
+	Height      int        `json:"height,omitempty"`
+	MimeType    string     `gorm:"type:VARCHAR(50)" json:"mime_type,omitempty"`
+	FileSize    int64      `json:"file_size,omitempty"`
+	CameraModel string     `gorm:"type:VARCHAR(100)" json:"camera_model,omitempty"`
+	TakenAt     *time.Time `json:"taken_at,omitempty"`
+	Latitude    *float64   `gorm:"index:idx_photos_location" json:"latitude,omitempty"`
+	Longitude   *float64   `gorm:"index:idx_photos_location" json:"longitude,omitempty"`
+}
+
 type Photo struct {
 	ID        string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
-	Title     string     `gorm:"type:VARCHAR(50);not null" valid:"required" form:"title" json:"title" example:"A Photo Title"`
+	Title     string     `gorm:"type:VARCHAR(50);not null" valid:"required,maxstringlength(50)" form:"title" json:"title" example:"A Photo Title"`
 	Caption   string     `form:"caption" json:"caption"`
 	PhotoUrl  string     `gorm:"not null" valid:"required" form:"photo_url" json:"photo_url" example:"https://www.example.com/image.jpg"`
 	UserID    string     `gorm:"type:VARCHAR(50);not null" json:"user_id"`
@@ -18,9 +61,107 @@ type Photo struct {
 	CreatedAt *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
 	UpdatedAt *time.Time `gorm:"not null;autoCreateTime" json:"updated_at,omitempty"`
 	Comment   *Comment   `json:"-"`
+
+	PhotoMetadata  `gorm:"embedded"`
+	MetadataStatus string `gorm:"type:VARCHAR(20);not null;default:pending;index" json:"metadata_status,omitempty"`
+	MetadataError  string `json:"metadata_error,omitempty"`
+
+	// LocationPublic is set when the owner chose to share where the photo
+	// was taken. Only then are its coordinates shown to others and matched
+	// by PhotoFilter.Near.
+	LocationPublic bool `gorm:"not null;default:false" json:"location_public"`
+}
+
+// GeoPoint is a place on Earth, in degrees.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// earthRadiusKm is the mean radius of the Earth.
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between point and other.
+func (point GeoPoint) DistanceKm(other GeoPoint) float64 {
+	lat1, lat2 := point.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (other.Longitude - point.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox returns the smallest latitude and longitude ranges holding
+// every point within radiusKm of point. wraps is set when the longitudes
+// can't be bounded, near the poles or across the antimeridian.
+func (point GeoPoint) BoundingBox(radiusKm float64) (minLat, maxLat, minLng, maxLng float64, wraps bool) {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat, maxLat = math.Max(-90, point.Latitude-dLat), math.Min(90, point.Latitude+dLat)
+
+	if minLat == -90 || maxLat == 90 {
+		return minLat, maxLat, -180, 180, true
+	}
+
+	dLng := dLat / math.Cos(point.Latitude*math.Pi/180)
+	minLng, maxLng = point.Longitude-dLng, point.Longitude+dLng
+
+	if minLng < -180 || maxLng > 180 {
+		return minLat, maxLat, -180, 180, true
+	}
+
+	return minLat, maxLat, minLng, maxLng, false
+}
+
+// PhotoFilter narrows a photo listing. When Near is set, only photos whose
+// owners made their location public and that were taken within RadiusKm of
+// Near are listed, closest first.
+type PhotoFilter struct {
+	Near     *GeoPoint
+	RadiusKm float64
+}
+
+// PhotosNear returns the photos of photos with a public location within
+// radiusKm of point, closest first.
+func PhotosNear(photos []Photo, point GeoPoint, radiusKm float64) []Photo {
+	near := []Photo{}
+	distances := map[string]float64{}
+
+	for _, photo := range photos {
+		location, ok := photo.Location()
+
+		if !ok || !photo.LocationPublic {
+			continue
+		}
+
+		if distance := point.DistanceKm(location); distance <= radiusKm {
+			near = append(near, photo)
+			distances[photo.ID] = distance
+		}
+	}
+
+	slices.SortStableFunc(near, func(a, b Photo) int {
+		return cmp.Compare(distances[a.ID], distances[b.ID])
+	})
+
+	return near
+}
+
+// Location returns where the photo was taken, if known.
+func (photo *Photo) Location() (GeoPoint, bool) {
+	if photo.Latitude == nil || photo.Longitude == nil {
+		return GeoPoint{}, false
+	}
+
+	return GeoPoint{*photo.Latitude, *photo.Longitude}, true
 }
 
 func (photo *Photo) BeforeCreate(db *gorm.DB) (err error) {
+	return photo.Validate()
+}
+
+// Validate checks the photo against the rules enforced when it is created.
+func (photo *Photo) Validate() (err error) {
 	if _, err := govalidator.ValidateStruct(photo); err != nil {
 		return err
 	}
@@ -28,6 +169,32 @@ func (photo *Photo) BeforeCreate(db *gorm.DB) (err error) {
 	return
 }
 
+// PhotoImportRow is a photo read from line Line of an import manifest. Err
+// is set when the line couldn't be read.
+type PhotoImportRow struct {
+	Line  int
+	Photo Photo
+	Err   error
+}
+
+// PhotoImportResult reports what happened to a row of an import manifest:
+// PhotoID is set when it was imported, and Error when it was rejected.
+type PhotoImportResult struct {
+	Line    int
+	Title   string
+	PhotoID string
+	Error   string
+}
+
+// PhotoImportReport sums up an import. In a dry run nothing is stored, and
+// Succeeded counts the rows that would have been imported.
+type PhotoImportReport struct {
+	DryRun    bool
+	Succeeded int
+	Failed    int
+	Results   []PhotoImportResult
+}
+
 func (photo *Photo) BeforeUpdate(db *gorm.DB) (err error) {
 	if _, err := govalidator.ValidateStruct(photo); err != nil {
 		return err
@@ -35,18 +202,38 @@ func (photo *Photo) BeforeUpdate(db *gorm.DB) (err error) {
 	return
 }
 
+// PhotoUpload is an image uploaded as a photo. Unless KeepLocation is set,
+// location data is stripped from the image before it's stored.
+type PhotoUpload struct {
+	Photo        Photo
+	Image        io.Reader
+	KeepLocation bool
+}
+
+// ImageFetcher downloads the image a photo URL points at.
+type ImageFetcher interface {
+	Fetch(context.Context, string) ([]byte, error)
+}
+
 type PhotoUseCase interface {
-	Fetch(context.Context, *[]Photo) error
+	Fetch(context.Context, *[]Photo, PhotoFilter) error
 	Store(context.Context, *Photo) error
 	GetByID(context.Context, *Photo, string) error
 	Update(context.Context, Photo, string) (Photo, error)
 	Delete(context.Context, string) error
+	Import(context.Context, string, []PhotoImportRow, bool) (PhotoImportReport, error)
+	Upload(context.Context, *PhotoUpload) error
+	Open(context.Context, string, string) (io.ReadCloser, error)
+	ProcessMetadata(context.Context) (int, error)
 }
 
 type PhotoRepository interface {
-	Fetch(context.Context, *[]Photo) error
+	Fetch(context.Context, *[]Photo, PhotoFilter) error
 	Store(context.Context, *Photo) error
+	StoreBatch(context.Context, []Photo) error
 	GetByID(context.Context, *Photo, string) error
 	Update(context.Context, Photo, string) (Photo, error)
 	Delete(context.Context, string) error
+	FetchMetadataPending(context.Context, *[]Photo, int) error
+	UpdateMetadata(context.Context, Photo) error
 }
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSessionRevoked = errors.New("the session has been revoked or has expired, sign in again")
+
+// Session is a signed in device. Access tokens carry the session id in their
+// "sid" claim, so revoking the session invalidates them.
+type Session struct {
+	ID         string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID     string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	DeviceName string     `gorm:"type:VARCHAR(100)" json:"device_name"`
+	IPAddress  string     `gorm:"type:VARCHAR(64)" json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	ExpiresAt  *time.Time `gorm:"not null" json:"expires_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	User       *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+func (session *Session) Active(now time.Time) bool {
+	return session.RevokedAt == nil && session.ExpiresAt != nil && session.ExpiresAt.After(now)
+}
+
+type SessionUseCase interface {
+	Start(context.Context, string, LoginInfo) (Session, error)
+	Fetch(context.Context, *[]Session, string) error
+	Revoke(context.Context, string, string) error
+	Validate(context.Context, string) error
+}
+
+type SessionRepository interface {
+	Store(context.Context, *Session) error
+	Fetch(context.Context, *[]Session, string, time.Time) error
+	GetByID(context.Context, *Session, string) error
+	Revoke(context.Context, string, string) error
+	Touch(context.Context, string, time.Time) error
+}
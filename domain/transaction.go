@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// TxManager runs units of work atomically. Repositories called with the
+// context passed to fn join the transaction, which is committed when fn
+// returns nil and rolled back otherwise. Calls nested in fn join the outer
+// transaction.
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
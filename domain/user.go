@@ -2,20 +2,41 @@ package domain
 
 import (
 	"context"
-	"api-mygram-go/helpers"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/asaskevich/govalidator"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrUsernameTaken = errors.New("the username you entered has been used")
+	ErrEmailTaken    = errors.New("the email you entered has been used")
+
+	ErrEmailChangeInvalid = errors.New("the email verification link is invalid or has expired")
+)
+
+func init() {
+	// imageurl accepts web addresses and the paths of uploaded avatars.
+	govalidator.TagMap["imageurl"] = govalidator.Validator(func(value string) bool {
+		if strings.HasPrefix(value, AvatarPathPrefix) {
+			return govalidator.IsRequestURI(value)
+		}
+
+		return govalidator.IsURL(value)
+	})
+}
+
 type User struct {
 	ID              string         `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
 	Username        string         `gorm:"type:VARCHAR(50);uniqueIndex;not null" valid:"required" form:"username" json:"username" example:"johndoe"`
-	Email           string         `gorm:"type:VARCHAR(50);uniqueIndex;not null" valid:"email,required" form:"email" json:"email" example:"johndoe@example.com"`
+	Email           string         `gorm:"type:VARCHAR(50);uniqueIndex;index:idx_users_email_lower,unique,expression:LOWER(email);not null" valid:"email,required" form:"email" json:"email" example:"johndoe@example.com"`
 	Password        string         `gorm:"not null" valid:"required,minstringlength(6)" form:"password" json:"password,omitempty" example:"secret"`
 	Age             uint           `gorm:"not null" valid:"required,range(8|63)" form:"age" json:"age,omitempty" example:"8"`
-	ProfileImageUrl string         `json:"profileImageUrl,omitempty" example:"https://www.example.com/image.jpg"`
+	ProfileImageUrl string         `valid:"imageurl" json:"profileImageUrl,omitempty" example:"https://www.example.com/image.jpg"`
+	DisplayName     string         `gorm:"type:VARCHAR(50)" valid:"maxstringlength(50)" form:"display_name" json:"display_name,omitempty" example:"John Doe"`
+	Bio             string         `gorm:"type:VARCHAR(300)" valid:"maxstringlength(300)" form:"bio" json:"bio,omitempty" example:"Sunsets and street photography"`
 	CreatedAt       *time.Time     `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
 	UpdatedAt       *time.Time     `gorm:"not null;autocreateTime" json:"updated_at,omitempty"`
 	Photos          *[]Photo       `json:"-"`
@@ -23,17 +44,53 @@ type User struct {
 }
 
 func (user *User) BeforeCreate(db *gorm.DB) (err error) {
+	return user.Validate()
+}
+
+func (user *User) BeforeUpdate(db *gorm.DB) (err error) {
+	return user.Validate()
+}
+
+// Validate checks the fields of user. Repositories call it before hashing the
+// password, since the hooks only see the hash.
+func (user *User) Validate() error {
 	if _, err := govalidator.ValidateStruct(user); err != nil {
 		return err
 	}
 
-	user.Password = helpers.Hash(user.Password)
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(user.Username)), GhostUsername) {
+		return ErrUsernameTaken
+	}
+
+	return nil
+}
 
-	return
+// UserUpdate lists the profile fields a user changes, nil fields are kept.
+// A new email address only replaces the current one once it is confirmed.
+type UserUpdate struct {
+	Username        *string `json:"username"`
+	Email           *string `json:"email"`
+	Age             *uint   `json:"age"`
+	ProfileImageUrl *string `json:"profileImageUrl"`
+	DisplayName     *string `json:"display_name"`
+	Bio             *string `json:"bio"`
 }
 
-func (user *User) BeforeUpdate(db *gorm.DB) (err error) {
-	if _, err := govalidator.ValidateStruct(user); err != nil {
+// EmailChange holds a new email address of a user until they follow the link
+// sent to it. Only the hash of the link's token is kept.
+type EmailChange struct {
+	ID          string     `gorm:"primaryKey;type:VARCHAR(50)" json:"id"`
+	UserID      string     `gorm:"type:VARCHAR(50);not null;index" json:"user_id"`
+	Email       string     `gorm:"type:VARCHAR(50);not null" valid:"email,required" json:"email"`
+	TokenHash   string     `gorm:"type:VARCHAR(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt   *time.Time `gorm:"not null" json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt   *time.Time `gorm:"not null;autoCreateTime" json:"created_at,omitempty"`
+	User        *User      `gorm:"foreignKey:UserID;constraint:onUpdate:CASCADE,onDelete:CASCADE" json:"-"`
+}
+
+func (change *EmailChange) BeforeCreate(db *gorm.DB) (err error) {
+	if _, err := govalidator.ValidateStruct(change); err != nil {
 		return err
 	}
 
@@ -42,14 +99,22 @@ func (user *User) BeforeUpdate(db *gorm.DB) (err error) {
 
 type UserUseCase interface {
 	Register(context.Context, *User) error
-	Login(context.Context, *User) error
-	Update(context.Context, User) (User, error)
-	Delete(context.Context, string) error
+	Login(context.Context, *User, LoginInfo) error
+	Update(context.Context, string, UserUpdate) (User, *EmailChange, error)
+	ConfirmEmail(context.Context, string) (User, error)
 }
 
 type UserRepository interface {
 	Register(context.Context, *User) error
 	Login(context.Context, *User) error
+	GetByID(context.Context, *User, string) error
+	GetByEmail(context.Context, *User, string) error
 	Update(context.Context, User) (User, error)
 	Delete(context.Context, string) error
 }
+
+type EmailChangeRepository interface {
+	Store(context.Context, *EmailChange) error
+	GetPending(context.Context, *EmailChange, string) error
+	Confirm(context.Context, string, time.Time) error
+}
@@ -0,0 +1,10 @@
+// Package e2e replays the acceptance flows of the Postman collection in
+// postman/ against the full router, served in-process from a database of
+// its own for every scenario. They run with the unit tests, against
+// in-memory SQLite:
+//
+//	go test ./e2e
+//
+// Set TEST_DATABASE_DRIVER=postgres to run them against an embedded
+// PostgreSQL server, or TEST_DATABASE_DSN to use a running one.
+package e2e
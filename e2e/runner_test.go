@@ -0,0 +1,200 @@
+package e2e_test
+
+import (
+	"api-mygram-go/app"
+	"api-mygram-go/config"
+	"api-mygram-go/config/database/databasetest"
+	"api-mygram-go/worker"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scenario is a Postman folder: steps run in order against one database, so
+// later steps see the users and resources created by earlier ones.
+type scenario struct {
+	name  string
+	steps []step
+}
+
+// step is a single request. Path and body may reference variables as
+// {{name}}, and {{$timestamp}} expands to a value unique to the step.
+type step struct {
+	name   string
+	method string
+	path   string
+	body   string
+	// token names the variable holding the bearer token, none when empty.
+	token string
+	// wantCode is the expected HTTP status code and wantStatus the expected
+	// "status" field of the body, which isn't checked when empty.
+	wantCode   int
+	wantStatus string
+	// wantFields are dotted paths into the body that must be present.
+	wantFields []string
+	// save stores the values at dotted paths of the body into variables.
+	save map[string]string
+}
+
+var variable = regexp.MustCompile(`{{\s*([$\w]+)\s*}}`)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	databasetest.Main(m)
+}
+
+func run(t *testing.T, sc scenario) {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.JWT.TokenKey = "e2e-token-key"
+	cfg.TOTP.EncryptionKey = "e2e-totp-key"
+	cfg.Password.BcryptCost = 4
+
+	cfg.Storage.Dir = t.TempDir()
+
+	db := databasetest.Open(t)
+
+	// Cleanups run last first, so the workers stop before the database
+	// is closed.
+	workers := worker.NewGroup(context.Background())
+	t.Cleanup(func() { workers.Stop(context.Background()) })
+
+	router, err := app.NewRouter(cfg, db, workers)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]string{
+		"newAge":                   "8",
+		"newEmail":                 "johndoe@example.com",
+		"newPassword":              "secret",
+		"newUsername":              "johndoe",
+		"newTitle":                 "A Title",
+		"newCaption":               "A caption",
+		"newPhotoUrl":              "https://www.example.com/image.jpg",
+		"newComment":               "A comment",
+		"newSocialMedia":           "Example",
+		"newSocialMediaUrl":        "https://www.example.com/johndoe",
+		"anotherNewAge":            "9",
+		"anotherNewEmail":          "lorem@example.com",
+		"anotherNewPassword":       "secret",
+		"anotherNewUsername":       "lorem",
+		"anotherNewComment":        "A different comment",
+		"anotherNewSocialMedia":    "Ipsum",
+		"anotherNewSocialMediaUrl": "https://www.ipsum.com/johndoe",
+		"dummyPhotoId":             "photo-123",
+		"dummyCommentId":           "comment-123",
+		"dummySocialMediaId":       "socialmedia-123",
+	}
+
+	for i, st := range sc.steps {
+		if !t.Run(fmt.Sprintf("%02d %s", i+1, st.name), func(t *testing.T) {
+			replay(t, router, vars, st)
+		}) {
+			t.FailNow()
+		}
+	}
+}
+
+func replay(t *testing.T, router http.Handler, vars map[string]string, st step) {
+	timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+	expand := func(s string) string {
+		return variable.ReplaceAllStringFunc(s, func(match string) string {
+			name := variable.FindStringSubmatch(match)[1]
+
+			if name == "$timestamp" {
+				return timestamp
+			}
+
+			value, ok := vars[name]
+
+			if !ok {
+				t.Fatalf("variable %s isn't set", name)
+			}
+
+			return value
+		})
+	}
+
+	req := httptest.NewRequest(st.method, expand(st.path), strings.NewReader(expand(st.body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	if st.token != "" {
+		req.Header.Set("Authorization", "Bearer "+vars[st.token])
+	}
+
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != st.wantCode {
+		t.Fatalf("%s %s = %d, want %d: %s", req.Method, req.URL.Path, res.Code, st.wantCode, res.Body)
+	}
+
+	if !strings.Contains(res.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", res.Header().Get("Content-Type"))
+	}
+
+	var body map[string]any
+
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response isn't a JSON object: %v: %s", err, res.Body)
+	}
+
+	if status, _ := body["status"].(string); st.wantStatus != "" && status != st.wantStatus {
+		t.Errorf("status = %q, want %q", status, st.wantStatus)
+	}
+
+	if st.wantCode >= http.StatusBadRequest {
+		if message, _ := body["message"].(string); message == "" {
+			t.Errorf("error response has no message: %s", res.Body)
+		}
+	}
+
+	for _, path := range st.wantFields {
+		if _, ok := lookup(body, path); !ok {
+			t.Errorf("response has no %s: %s", path, res.Body)
+		}
+	}
+
+	for name, path := range st.save {
+		value, ok := lookup(body, path)
+
+		if !ok {
+			t.Fatalf("response has no %s to save as %s: %s", path, name, res.Body)
+		}
+
+		vars[name] = fmt.Sprint(value)
+	}
+}
+
+// lookup returns the value at a dotted path such as "data.id".
+func lookup(body map[string]any, path string) (any, bool) {
+	var value any = body
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = object[key]; !ok || value == nil {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,726 @@
+package e2e_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func register(prefix string) step {
+	return step{
+		name:       "register " + prefix + " user",
+		method:     http.MethodPost,
+		path:       "/users/register",
+		body:       `{"age": {{` + prefix + `Age}}, "username": "{{` + prefix + `Username}}", "password": "{{` + prefix + `Password}}", "email": "{{` + prefix + `Email}}"}`,
+		wantCode:   http.StatusCreated,
+		wantFields: []string{"data.id"},
+	}
+}
+
+func login(prefix string, token string) step {
+	return step{
+		name:     "login " + prefix + " user",
+		method:   http.MethodPost,
+		path:     "/users/login",
+		body:     `{"email": "{{` + prefix + `Email}}", "password": "{{` + prefix + `Password}}"}`,
+		wantCode: http.StatusOK,
+		save:     map[string]string{token: "data.token"},
+	}
+}
+
+func badPayloads(name string, method string, path string, token string, wantStatus string, payloads ...string) []step {
+	steps := make([]step, len(payloads))
+
+	for i, payload := range payloads {
+		steps[i] = step{
+			name:       name + " " + payload,
+			method:     method,
+			path:       path,
+			body:       payload,
+			token:      token,
+			wantCode:   http.StatusBadRequest,
+			wantStatus: wantStatus,
+		}
+	}
+
+	return steps
+}
+
+func steps(groups ...any) []step {
+	var all []step
+
+	for _, group := range groups {
+		switch group := group.(type) {
+		case step:
+			all = append(all, group)
+		case []step:
+			all = append(all, group...)
+		}
+	}
+
+	return all
+}
+
+var scenarios = []scenario{
+	{
+		name: "register",
+		steps: steps(
+			step{
+				name:       "register user with valid payload",
+				method:     http.MethodPost,
+				path:       "/users/register",
+				body:       `{"age": {{newAge}}, "username": "{{newUsername}}_{{$timestamp}}", "password": "{{newPassword}}", "email": "{{$timestamp}}_{{newEmail}}"}`,
+				wantCode:   http.StatusCreated,
+				wantFields: []string{"data.age", "data.email", "data.id", "data.username"},
+			},
+			badPayloads("register user with bad payload", http.MethodPost, "/users/register", "", "fail",
+				`{}`,
+				`{"age": 8, "username": "johndoe"}`,
+				`{"age": 8, "username": 123, "email": "johndoe@example.com", "password": "secret"}`,
+				`{"age": 7, "username": "johndoe", "email": "johndoe@example.com", "password": "secret"}`,
+				`{"age": 8, "username": "johndoe", "email": "johndoe@example.com", "password": "scrt"}`,
+			),
+			register("new"),
+			step{
+				name:       "register user with exist username",
+				method:     http.MethodPost,
+				path:       "/users/register",
+				body:       `{"age": {{newAge}}, "username": "{{newUsername}}", "password": "{{newPassword}}", "email": "{{$timestamp}}_{{newEmail}}"}`,
+				wantCode:   http.StatusConflict,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "register user with exist email",
+				method:     http.MethodPost,
+				path:       "/users/register",
+				body:       `{"age": {{newAge}}, "username": "{{newUsername}}_{{$timestamp}}", "password": "{{newPassword}}", "email": "{{newEmail}}"}`,
+				wantCode:   http.StatusConflict,
+				wantStatus: "fail",
+			},
+		),
+	},
+	{
+		name: "login",
+		steps: steps(
+			register("new"),
+			login("new", "token"),
+			badPayloads("login user with bad payload", http.MethodPost, "/users/login", "", "",
+				`{}`,
+				`{"email": "johndoe@example.com"}`,
+				`{"password": "secret"}`,
+				`{"email": 8, "password": "secret"}`,
+			),
+			step{
+				name:     "login user with invalid email",
+				method:   http.MethodPost,
+				path:     "/users/login",
+				body:     `{"email": "xxx@example.com", "password": "{{newPassword}}"}`,
+				wantCode: http.StatusBadRequest,
+			},
+			step{
+				name:     "login user with invalid password",
+				method:   http.MethodPost,
+				path:     "/users/login",
+				body:     `{"email": "{{newEmail}}", "password": "xxx"}`,
+				wantCode: http.StatusBadRequest,
+			},
+		),
+	},
+	{
+		name: "users",
+		steps: steps(
+			register("new"),
+			login("new", "token"),
+			step{
+				name:       "update user with authentication user and valid payload",
+				method:     http.MethodPut,
+				path:       "/users",
+				body:       `{"email": "{{$timestamp}}_johndoe@example.com", "username": "newjohndoe_{{$timestamp}}"}`,
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"data.id", "data.email", "data.pending_email", "data.pending_email_expires_at", "data.username", "data.age", "data.updated_at"},
+			},
+			step{
+				name:       "update user profile with authentication user",
+				method:     http.MethodPut,
+				path:       "/users",
+				body:       `{"display_name": "John Doe", "bio": "Sunsets and street photography", "age": 30}`,
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantStatus: "success",
+				wantFields: []string{"data.display_name", "data.bio", "data.age"},
+			},
+			step{
+				name:       "update user with an invalid profile image url",
+				method:     http.MethodPut,
+				path:       "/users",
+				body:       `{"profileImageUrl": "not a url"}`,
+				token:      "token",
+				wantCode:   http.StatusBadRequest,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "get an avatar that doesn't exist",
+				method:     http.MethodGet,
+				path:       "/avatars/user-missing/abcdefghijkl-256.jpg",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "verify email with an unknown token",
+				method:     http.MethodPost,
+				path:       "/users/email/verify",
+				body:       `{"token": "unknown"}`,
+				wantCode:   http.StatusBadRequest,
+				wantStatus: "fail",
+			},
+			badPayloads("update user with authentication user and bad payload", http.MethodPut, "/users", "token", "fail",
+				`{"email": 8, "username": "newjohndoe"}`,
+				`{"email": "newjohndoe@example.com", "username": 8}`,
+			),
+			step{
+				name:       "update user without authentication user",
+				method:     http.MethodPut,
+				path:       "/users",
+				body:       `{"email": "newjohndoe@example.com", "username": "newjohndoe"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "request a data export",
+				method:     http.MethodPost,
+				path:       "/users/me/export",
+				token:      "token",
+				wantCode:   http.StatusAccepted,
+				wantStatus: "success",
+				wantFields: []string{"data.id", "data.status"},
+				save:       map[string]string{"exportId": "data.id"},
+			},
+			step{
+				name:       "get the data export",
+				method:     http.MethodGet,
+				path:       "/users/me/export/{{exportId}}",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantStatus: "success",
+				wantFields: []string{"data.status"},
+			},
+			step{
+				name:       "download the data export with a forged link",
+				method:     http.MethodGet,
+				path:       "/users/me/export/{{exportId}}/download?expires=4102444800&signature=forged",
+				wantCode:   http.StatusForbidden,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "delete user without authentication user",
+				method:     http.MethodDelete,
+				path:       "/users",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "delete user with authentication user",
+				method:     http.MethodDelete,
+				path:       "/users",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"message"},
+			},
+			step{
+				name:       "get the pending deletion",
+				method:     http.MethodGet,
+				path:       "/users/deletion",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantStatus: "success",
+				wantFields: []string{"data.id", "data.purge_after"},
+			},
+			step{
+				name:       "cancel the pending deletion",
+				method:     http.MethodDelete,
+				path:       "/users/deletion",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantStatus: "success",
+			},
+			step{
+				name:       "cancel a deletion that isn't pending",
+				method:     http.MethodDelete,
+				path:       "/users/deletion",
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+		),
+	},
+	{
+		name: "photos",
+		steps: steps(
+			register("new"),
+			login("new", "token"),
+			register("anotherNew"),
+			login("anotherNew", "anotherToken"),
+			step{
+				name:     "get all photos with authentication user",
+				method:   http.MethodGet,
+				path:     "/photos",
+				token:    "token",
+				wantCode: http.StatusOK,
+			},
+			step{
+				name:       "get all photos without authentication user",
+				method:     http.MethodGet,
+				path:       "/photos",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "add photo with authentication user and valid payload",
+				method:     http.MethodPost,
+				path:       "/photos",
+				body:       `{"title": "{{newTitle}}", "caption": "{{newCaption}}", "photo_url": "{{newPhotoUrl}}"}`,
+				token:      "token",
+				wantCode:   http.StatusCreated,
+				wantFields: []string{"data.id", "data.title", "data.caption", "data.photo_url", "data.user_id", "data.created_at"},
+				save:       map[string]string{"addedPhoto": "data.id"},
+			},
+			badPayloads("add photo with authentication user and bad payload", http.MethodPost, "/photos", "token", "fail",
+				`{}`,
+				`{"caption": "A caption"}`,
+				`{"caption": "A caption", "photoUrl": "https://www.example.com/image.jpg"}`,
+				`{"title": "A title", "caption": "A caption", "photoUrl": true}`,
+			),
+			step{
+				name:       "add photo without authentication user",
+				method:     http.MethodPost,
+				path:       "/photos",
+				body:       `{"title": "{{newTitle}}", "caption": "{{newUsername}}", "photo_url": "{{newPhotoUrl}}"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "get photo with its metadata",
+				method:     http.MethodGet,
+				path:       "/photos/{{addedPhoto}}",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"data.id", "data.photo_url", "data.metadata.status"},
+			},
+			step{
+				name:       "get photo with authentication and unavailable photo",
+				method:     http.MethodGet,
+				path:       "/photos/{{dummyPhotoId}}",
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "get photos near a place",
+				method:     http.MethodGet,
+				path:       "/photos?near=-6.175392,106.827153&radius=5",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantStatus: "success",
+			},
+			step{
+				name:       "get photos near an invalid place",
+				method:     http.MethodGet,
+				path:       "/photos?near=somewhere",
+				token:      "token",
+				wantCode:   http.StatusBadRequest,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "upload photo without an image",
+				method:     http.MethodPost,
+				path:       "/photos/upload",
+				body:       `{"title": "{{newTitle}}"}`,
+				token:      "token",
+				wantCode:   http.StatusBadRequest,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "get an uploaded image that doesn't exist",
+				method:     http.MethodGet,
+				path:       "/images/user-missing/abcdefghijkl.jpg",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "update photo with authentication user and valid payload",
+				method:     http.MethodPut,
+				path:       "/photos/{{addedPhoto}}",
+				body:       `{"title": "New a title", "caption": "New a caption", "photo_url": "https://www.example.com/new-image.jpg"}`,
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"data.id", "data.title", "data.caption", "data.photo_url", "data.user_id", "data.updated_at"},
+			},
+			step{
+				name:       "update photo with authentication and unavailable photo",
+				method:     http.MethodPut,
+				path:       "/photos/{{dummyPhotoId}}",
+				body:       `{"title": "New a title", "caption": "New a caption", "photoUrl": "https://www.example.com/new-image.jpg"}`,
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			badPayloads("update photo with authentication user and bad payload", http.MethodPut, "/photos/{{addedPhoto}}", "token", "fail",
+				`{"title": true, "caption": "New a caption", "photoUrl": "https://www.example.com/new-image.jpg"}`,
+				`{"title": "New a title", "caption": "New a caption", "photo_url": 123}`,
+			),
+			step{
+				name:       "update photo without authentication user",
+				method:     http.MethodPut,
+				path:       "/photos/{{addedPhoto}}",
+				body:       `{"title": "New a title", "caption": "New a caption", "photoUrl": "https://www.example.com/new-image.jpg"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "update photo without authorization",
+				method:     http.MethodPut,
+				path:       "/photos/{{addedPhoto}}",
+				body:       `{"title": "New a title", "caption": "New a caption", "photoUrl": "https://www.example.com/new-image.jpg"}`,
+				token:      "anotherToken",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthorized",
+			},
+			step{
+				name:       "delete photo with authentication user",
+				method:     http.MethodDelete,
+				path:       "/photos/{{addedPhoto}}",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"message"},
+			},
+			step{
+				name:       "delete photo with authentication user and not found photo",
+				method:     http.MethodDelete,
+				path:       "/photos/{{dummyPhotoId}}",
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "delete photo without authentication user",
+				method:     http.MethodDelete,
+				path:       "/photos/{{addedPhoto}}",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:     "add another user's photo",
+				method:   http.MethodPost,
+				path:     "/photos",
+				body:     `{"title": "{{newTitle}}", "caption": "{{newCaption}}", "photo_url": "{{newPhotoUrl}}"}`,
+				token:    "anotherToken",
+				wantCode: http.StatusCreated,
+				save:     map[string]string{"anotherAddedPhoto": "data.id"},
+			},
+			step{
+				name:       "delete photo without authorization",
+				method:     http.MethodDelete,
+				path:       "/photos/{{anotherAddedPhoto}}",
+				token:      "token",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthorized",
+			},
+			step{
+				name:       "import photos from a csv manifest in a dry run",
+				method:     http.MethodPost,
+				path:       "/photos/import?format=csv&dry_run=true",
+				body:       "title,caption,photo_url\n{{newTitle}},{{newCaption}},{{newPhotoUrl}}\n,,\n",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantStatus: "success",
+				wantFields: []string{"data.rows"},
+			},
+			step{
+				name:       "import photos from a json lines manifest",
+				method:     http.MethodPost,
+				path:       "/photos/import?format=jsonl",
+				body:       `{"title": "{{newTitle}}", "caption": "{{newCaption}}", "photo_url": "{{newPhotoUrl}}"}` + "\n" + `{"title": "{{newTitle}}", "photo_url": "{{newPhotoUrl}}"}`,
+				token:      "token",
+				wantCode:   http.StatusCreated,
+				wantStatus: "success",
+				wantFields: []string{"data.succeeded", "data.rows"},
+			},
+			step{
+				name:       "import photos without authentication user",
+				method:     http.MethodPost,
+				path:       "/photos/import?format=jsonl",
+				body:       `{"title": "{{newTitle}}", "photo_url": "{{newPhotoUrl}}"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+		),
+	},
+	{
+		name: "comments",
+		steps: steps(
+			register("new"),
+			login("new", "token"),
+			register("anotherNew"),
+			login("anotherNew", "anotherToken"),
+			step{
+				name:     "add another user's photo",
+				method:   http.MethodPost,
+				path:     "/photos",
+				body:     `{"title": "{{newTitle}}", "caption": "{{newCaption}}", "photo_url": "{{newPhotoUrl}}"}`,
+				token:    "anotherToken",
+				wantCode: http.StatusCreated,
+				save:     map[string]string{"anotherAddedPhoto": "data.id"},
+			},
+			step{
+				name:     "get all comments with authentication user",
+				method:   http.MethodGet,
+				path:     "/comments",
+				token:    "token",
+				wantCode: http.StatusOK,
+			},
+			step{
+				name:       "get all comments without authentication user",
+				method:     http.MethodGet,
+				path:       "/comments",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "add comment with authentication user and valid payload",
+				method:     http.MethodPost,
+				path:       "/comments",
+				body:       `{"message": "{{newComment}}", "photo_id": "{{anotherAddedPhoto}}"}`,
+				token:      "token",
+				wantCode:   http.StatusCreated,
+				wantFields: []string{"data.id", "data.user_id", "data.photo_id", "data.message", "data.created_at"},
+				save:       map[string]string{"addedComment": "data.id"},
+			},
+			step{
+				name:       "add comment with authentication user and not found photo",
+				method:     http.MethodPost,
+				path:       "/comments",
+				body:       `{"message": "{{newComment}}", "photoId": "{{dummyPhotoId}}"}`,
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			badPayloads("add comment with authentication user and bad payload", http.MethodPost, "/comments", "token", "fail",
+				`{"message": 8, "photoId": "{{anotherAddedPhoto}}"}`,
+			),
+			step{
+				name:       "add comment without authentication user",
+				method:     http.MethodPost,
+				path:       "/comments",
+				body:       `{"message": "{{newComment}}", "photoId": "{{anotherAddedPhoto}}"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "update comment with authentication user and valid payload",
+				method:     http.MethodPut,
+				path:       "/comments/{{addedComment}}",
+				body:       `{"message": "A new comment"}`,
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"data.id", "data.title", "data.caption", "data.photo_url", "data.user_id", "data.updated_at"},
+			},
+			step{
+				name:       "update comment with authentication user and not found comment",
+				method:     http.MethodPut,
+				path:       "/comments/{{dummyCommentId}}",
+				body:       `{"message": "A new comment"}`,
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			badPayloads("update comment with authentication user and bad payload", http.MethodPut, "/comments/{{addedComment}}", "token", "fail",
+				`{"message": true}`,
+			),
+			step{
+				name:       "update comment without authentication user",
+				method:     http.MethodPut,
+				path:       "/comments/{{addedComment}}",
+				body:       `{"message": "A new comment"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "update comment without authorization",
+				method:     http.MethodPut,
+				path:       "/comments/{{addedComment}}",
+				body:       `{"message": "A new comment"}`,
+				token:      "anotherToken",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthorized",
+			},
+			step{
+				name:       "delete comment with authentication user",
+				method:     http.MethodDelete,
+				path:       "/comments/{{addedComment}}",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"message"},
+			},
+			step{
+				name:       "delete comment with authentication user and not found comment",
+				method:     http.MethodDelete,
+				path:       "/comments/{{dummyCommentId}}",
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "delete comment without authentication user",
+				method:     http.MethodDelete,
+				path:       "/comments/{{addedComment}}",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:     "add another user's comment",
+				method:   http.MethodPost,
+				path:     "/comments",
+				body:     `{"message": "{{anotherNewComment}}", "photo_id": "{{anotherAddedPhoto}}"}`,
+				token:    "anotherToken",
+				wantCode: http.StatusCreated,
+				save:     map[string]string{"anotherAddedComment": "data.id"},
+			},
+			step{
+				name:       "delete comment without authorization",
+				method:     http.MethodDelete,
+				path:       "/comments/{{anotherAddedComment}}",
+				token:      "token",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthorized",
+			},
+		),
+	},
+	{
+		name: "social medias",
+		steps: steps(
+			register("new"),
+			login("new", "token"),
+			register("anotherNew"),
+			login("anotherNew", "anotherToken"),
+			step{
+				name:     "get all social medias with authentication user",
+				method:   http.MethodGet,
+				path:     "/socialmedias",
+				token:    "token",
+				wantCode: http.StatusOK,
+			},
+			step{
+				name:       "get all social medias without authentication user",
+				method:     http.MethodGet,
+				path:       "/socialmedias",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "add social media with authentication and valid payload",
+				method:     http.MethodPost,
+				path:       "/socialmedias",
+				body:       `{"name": "{{newSocialMedia}}", "social_media_url": "{{newSocialMediaUrl}}"}`,
+				token:      "token",
+				wantCode:   http.StatusCreated,
+				wantFields: []string{"data.id", "data.name", "data.social_media_url", "data.user_id", "data.created_at"},
+				save:       map[string]string{"addedSocialMedia": "data.id"},
+			},
+			badPayloads("add social media with authentication and bad payload", http.MethodPost, "/socialmedias", "token", "fail",
+				`{}`,
+				`{"socialMediaUrl": "https://www.example.com/johndoe"}`,
+				`{"name": 8, "socialMediaUrl": "https://www.example.com/johndoe"}`,
+			),
+			step{
+				name:       "add social media without authentication user",
+				method:     http.MethodPost,
+				path:       "/socialmedias",
+				body:       `{"name": "{{newSocialMedia}}", "socialMediaUrl": "{{newSocialMediaUrl}}"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "update social media with authentication user",
+				method:     http.MethodPut,
+				path:       "/socialmedias/{{addedSocialMedia}}",
+				body:       `{"name": "newjohndoe", "social_media_url": "https://www.example.com/newjohndoe"}`,
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"id", "name", "social_media_url", "user_id", "updated_at"},
+			},
+			step{
+				name:       "update social media with authentication user and not found social media",
+				method:     http.MethodPut,
+				path:       "/socialmedias/{{dummySocialMediaId}}",
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "update social media without authentication user",
+				method:     http.MethodPut,
+				path:       "/socialmedias/{{addedSocialMedia}}",
+				body:       `{"name": "newjohndoe", "socialMediaUrl": "https://www.example.com/newjohndoe"}`,
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:       "update social media without authorization",
+				method:     http.MethodPut,
+				path:       "/socialmedias/{{addedSocialMedia}}",
+				body:       `{"name": "newjohndoe", "social_media_url": "https://www.example.com/newjohndoe"}`,
+				token:      "anotherToken",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthorized",
+			},
+			step{
+				name:       "delete social media with authentication user",
+				method:     http.MethodDelete,
+				path:       "/socialmedias/{{addedSocialMedia}}",
+				token:      "token",
+				wantCode:   http.StatusOK,
+				wantFields: []string{"message"},
+			},
+			step{
+				name:       "delete social media with authentication user and not found social media",
+				method:     http.MethodDelete,
+				path:       "/socialmedias/{{dummySocialMediaId}}",
+				token:      "token",
+				wantCode:   http.StatusNotFound,
+				wantStatus: "fail",
+			},
+			step{
+				name:       "delete social media without authentication user",
+				method:     http.MethodDelete,
+				path:       "/socialmedias/{{addedSocialMedia}}",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthenticated",
+			},
+			step{
+				name:     "add another user's social media",
+				method:   http.MethodPost,
+				path:     "/socialmedias",
+				body:     `{"name": "{{anotherNewSocialMedia}}", "social_media_url": "{{anotherNewSocialMediaUrl}}"}`,
+				token:    "anotherToken",
+				wantCode: http.StatusCreated,
+				save:     map[string]string{"anotherAddedSocialMedia": "data.id"},
+			},
+			step{
+				name:       "delete social media without authorization",
+				method:     http.MethodDelete,
+				path:       "/socialmedias/{{anotherAddedSocialMedia}}",
+				token:      "token",
+				wantCode:   http.StatusUnauthorized,
+				wantStatus: "unauthorized",
+			},
+		),
+	},
+}
+
+func TestPostmanScenarios(t *testing.T) {
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			run(t, sc)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Database pings the connection pool behind db.
+func Database(db *gorm.DB) Checker {
+	return CheckFunc{"database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+
+		if err != nil {
+			return err
+		}
+
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+// Migrations reports the tables of models that don't exist yet.
+func Migrations(db *gorm.DB, models ...interface{}) Checker {
+	return CheckFunc{"migrations", func(ctx context.Context) error {
+		var missing []string
+
+		migrator := db.WithContext(ctx).Migrator()
+
+		for _, model := range models {
+			if !migrator.HasTable(model) {
+				statement := &gorm.Statement{DB: db}
+
+				if err := statement.Parse(model); err != nil {
+					return err
+				}
+
+				missing = append(missing, statement.Schema.Table)
+			}
+		}
+
+		if len(missing) > 0 {
+			return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+		}
+
+		return nil
+	}}
+}
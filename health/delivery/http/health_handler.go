@@ -0,0 +1,55 @@
+package delivery
+
+import (
+	"api-mygram-go/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthHandler struct {
+	checkers []health.Checker
+}
+
+func NewHealthHandler(routers *gin.Engine, checkers ...health.Checker) {
+	handler := &healthHandler{checkers}
+
+	routers.GET("/healthz", handler.Liveness)
+	routers.GET("/readyz", handler.Readiness)
+}
+
+// Liveness godoc
+// @Summary			Liveness probe
+// @Description	Report that the process is up. No dependency is checked.
+// @Tags				health
+// @Produce			json
+// @Success			200		{object}	health.Report
+// @Router			/healthz	[get]
+func (handler *healthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, health.Report{
+		Status: "ok",
+		Checks: map[string]health.Result{},
+	})
+}
+
+// Readiness godoc
+// @Summary			Readiness probe
+// @Description	Check every dependency and report its status and latency
+// @Tags				health
+// @Produce			json
+// @Success			200		{object}	health.Report
+// @Failure			503		{object}	health.Report
+// @Router			/readyz	[get]
+func (handler *healthHandler) Readiness(ctx *gin.Context) {
+	report := health.Run(ctx.Request.Context(), handler.checkers)
+
+	ctx.Header("Cache-Control", "no-store")
+
+	if !report.Ready() {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
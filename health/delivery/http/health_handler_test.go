@@ -0,0 +1,56 @@
+package delivery_test
+
+import (
+	"api-mygram-go/health"
+	delivery "api-mygram-go/health/delivery/http"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	down := false
+	router := gin.New()
+
+	delivery.NewHealthHandler(router, health.CheckFunc{CheckName: "database", Func: func(context.Context) error {
+		if down {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	}})
+
+	tests := []struct {
+		path     string
+		down     bool
+		wantCode int
+		want     string
+	}{
+		{"/healthz", true, http.StatusOK, "ok"},
+		{"/readyz", false, http.StatusOK, "ok"},
+		{"/readyz", true, http.StatusServiceUnavailable, "unavailable"},
+	}
+
+	for _, test := range tests {
+		down = test.down
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		var report health.Report
+
+		if err := json.Unmarshal(res.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Code != test.wantCode || report.Status != test.want {
+			t.Errorf("%s (database down: %t) = %d %s, want %d %s", test.path, test.down, res.Code, report.Status, test.wantCode, test.want)
+		}
+	}
+}
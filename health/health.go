@@ -0,0 +1,94 @@
+// Package health checks the dependencies the API needs to serve requests.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CheckTimeout bounds each readiness check, so one hung dependency can't
+// stall the probe.
+const CheckTimeout = 2 * time.Second
+
+// Checker probes one dependency, such as the database or a blob store.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to a Checker.
+type CheckFunc struct {
+	CheckName string
+	Func      func(ctx context.Context) error
+}
+
+func (check CheckFunc) Name() string {
+	return check.CheckName
+}
+
+func (check CheckFunc) Check(ctx context.Context) error {
+	return check.Func(ctx)
+}
+
+type Result struct {
+	Status    string  `json:"status" example:"ok"`
+	LatencyMS float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty" example:"dial tcp 127.0.0.1:5432: connect: connection refused"`
+}
+
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]Result `json:"checks"`
+}
+
+func (report Report) Ready() bool {
+	return report.Status == "ok"
+}
+
+// Run runs every checker concurrently and reports "ok" when all pass.
+func Run(ctx context.Context, checkers []Checker) Report {
+	report := Report{Status: "ok", Checks: map[string]Result{}}
+
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+	)
+
+	for _, checker := range checkers {
+		wg.Add(1)
+
+		go func(checker Checker) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Check(checkCtx)
+			result := Result{
+				Status:    "ok",
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+
+			if err != nil {
+				result.Status = "fail"
+				result.Error = err.Error()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.Checks[checker.Name()] = result
+
+			if err != nil {
+				report.Status = "unavailable"
+			}
+		}(checker)
+	}
+
+	wg.Wait()
+
+	return report
+}
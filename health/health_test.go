@@ -0,0 +1,84 @@
+package health_test
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"api-mygram-go/health"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRun(t *testing.T) {
+	passing := health.CheckFunc{CheckName: "passing", Func: func(context.Context) error { return nil }}
+	failing := health.CheckFunc{CheckName: "failing", Func: func(context.Context) error { return errors.New("connection refused") }}
+	hanging := health.CheckFunc{CheckName: "hanging", Func: func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}}
+
+	if report := health.Run(context.Background(), []health.Checker{passing}); !report.Ready() || report.Checks["passing"].Status != "ok" {
+		t.Errorf("report = %+v, want ready", report)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+	defer cancel()
+
+	report := health.Run(ctx, []health.Checker{passing, failing, hanging})
+
+	if report.Ready() || report.Status != "unavailable" {
+		t.Errorf("status = %s, want unavailable", report.Status)
+	}
+
+	if result := report.Checks["failing"]; result.Status != "fail" || result.Error != "connection refused" {
+		t.Errorf("failing check = %+v, want its error", result)
+	}
+
+	if result := report.Checks["hanging"]; result.Status != "fail" || result.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("hanging check = %+v, want it timed out", result)
+	}
+
+	if result := report.Checks["passing"]; result.Status != "ok" {
+		t.Errorf("passing check = %+v, want ok", result)
+	}
+}
+
+func TestDatabaseChecks(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Open(config.Database{Driver: "sqlite", SQLitePath: ":memory:"}, &gorm.Config{Logger: logger.Discard})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.AutoMigrate(&domain.User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = health.Database(db).Check(ctx); err != nil {
+		t.Errorf("pinging the database returned %v", err)
+	}
+
+	if err = health.Migrations(db, &domain.User{}).Check(ctx); err != nil {
+		t.Errorf("checking migrated tables returned %v", err)
+	}
+
+	if err = health.Migrations(db, &domain.User{}, &domain.Photo{}).Check(ctx); err == nil || !strings.Contains(err.Error(), "photos") {
+		t.Errorf("checking a missing table returned %v, want it named", err)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	if err = health.Database(db).Check(ctx); err == nil {
+		t.Error("pinging a closed database succeeded")
+	}
+}
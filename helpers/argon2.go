@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+type Argon2idParams struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+var DefaultArgon2idParams = Argon2idParams{
+	Time:    1,
+	Memory:  64 * 1024,
+	Threads: 4,
+	KeyLen:  32,
+	SaltLen: 16,
+}
+
+var errInvalidArgon2idHash = errors.New("invalid argon2id hash")
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+func NewArgon2idHasher(params Argon2idParams) *argon2idHasher {
+	return &argon2idHasher{params}
+}
+
+// Hash encodes the hash in the PHC string format:
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>
+func (hasher *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, hasher.params.SaltLen)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := hasher.params
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *argon2idHasher) Compare(hashedPassword, password []byte) bool {
+	p, salt, key, err := decodeArgon2idHash(hashedPassword)
+
+	if err != nil {
+		return false
+	}
+
+	other := argon2.IDKey(password, salt, p.Time, p.Memory, p.Threads, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+func (hasher *argon2idHasher) NeedsRehash(hashedPassword []byte) bool {
+	p, _, key, err := decodeArgon2idHash(hashedPassword)
+
+	if err != nil {
+		return true
+	}
+
+	return p.Time < hasher.params.Time ||
+		p.Memory < hasher.params.Memory ||
+		p.Threads < hasher.params.Threads ||
+		uint32(len(key)) < hasher.params.KeyLen
+}
+
+func (hasher *argon2idHasher) Supports(hashedPassword []byte) bool {
+	return bytes.HasPrefix(hashedPassword, []byte("$argon2id$"))
+}
+
+func decodeArgon2idHash(hashedPassword []byte) (p Argon2idParams, salt, key []byte, err error) {
+	parts := strings.Split(string(hashedPassword), "$")
+
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+
+	var version int
+
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+
+	return p, salt, key, nil
+}
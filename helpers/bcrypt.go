@@ -1,18 +1,51 @@
 package helpers
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"bytes"
 
-func Hash(password string) string {
-	salt := 8
-	pass := []byte(password)
-	hash, _ := bcrypt.GenerateFromPassword(pass, salt)
+	"golang.org/x/crypto/bcrypt"
+)
 
-	return string(hash)
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) *bcryptHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DefaultBcryptCost
+	}
+
+	return &bcryptHasher{cost}
+}
+
+func (hasher *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), hasher.cost)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
 }
 
-func Compare(hashedPassword, password []byte) bool {
-	hash, pass := []byte(hashedPassword), []byte(password)
-	err := bcrypt.CompareHashAndPassword(hash, pass)
+func (hasher *bcryptHasher) Compare(hashedPassword, password []byte) bool {
+	err := bcrypt.CompareHashAndPassword(hashedPassword, password)
 
 	return err == nil
 }
+
+func (hasher *bcryptHasher) NeedsRehash(hashedPassword []byte) bool {
+	cost, err := bcrypt.Cost(hashedPassword)
+
+	if err != nil {
+		return true
+	}
+
+	return cost < hasher.cost
+}
+
+func (hasher *bcryptHasher) Supports(hashedPassword []byte) bool {
+	return bytes.HasPrefix(hashedPassword, []byte("$2"))
+}
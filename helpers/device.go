@@ -0,0 +1,56 @@
+package helpers
+
+import "strings"
+
+var (
+	deviceBrowsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"PostmanRuntime/", "Postman"},
+		{"curl/", "curl"},
+	}
+	devicePlatforms = []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// DeviceName summarizes a user agent as "<browser> on <platform>" for the
+// session list.
+func DeviceName(userAgent string) string {
+	browser, platform := "", ""
+
+	for _, candidate := range deviceBrowsers {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+
+			break
+		}
+	}
+
+	for _, candidate := range devicePlatforms {
+		if strings.Contains(userAgent, candidate.token) {
+			platform = candidate.name
+
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
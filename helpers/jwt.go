@@ -2,33 +2,75 @@ package helpers
 
 import (
 	"errors"
-	"log"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
-func GenerateToken(id string, email string) string {
+const (
+	MFAChallengeTokenType = "mfa_challenge"
+	MFAChallengeTokenTTL  = 5 * time.Minute
+)
+
+// GenerateToken issues an access token bound to sessionID, which the
+// authentication middleware checks on every request.
+func (set *KeySet) GenerateToken(id string, email string, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"id":    id,
 		"email": email,
+		"sub":   id,
+		"sid":   sessionID,
+		"aud":   set.Options.Audience,
 	}
 
-	parseToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return set.Sign(claims)
+}
+
+// GenerateTypedToken signs claims for a purpose other than API access, such as
+// an MFA challenge. VerifyToken rejects tokens carrying a "typ" claim.
+func (set *KeySet) GenerateTypedToken(typ string, claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	claims["typ"] = typ
+	claims["exp"] = time.Now().Add(ttl).Unix()
+
+	return set.Sign(claims)
+}
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file: ", err)
+func (set *KeySet) VerifyTypedToken(typ string, stringToken string) (jwt.MapClaims, error) {
+	errResponse := errors.New("the token is invalid or has expired")
+
+	claims, err := set.Parse(stringToken)
+
+	if err != nil || claims["typ"] != typ {
+		return nil, errResponse
 	}
 
-	signedToken, _ := parseToken.SignedString([]byte(os.Getenv("TOKEN_KEY")))
+	return claims, nil
+}
+
+// GenerateMFAChallengeToken issues the short-lived token a client exchanges,
+// together with a second factor, for a regular access token.
+func (set *KeySet) GenerateMFAChallengeToken(id string, email string) (string, error) {
+	return set.GenerateTypedToken(MFAChallengeTokenType, jwt.MapClaims{
+		"id":    id,
+		"email": email,
+	}, MFAChallengeTokenTTL)
+}
+
+func (set *KeySet) VerifyMFAChallengeToken(stringToken string) (jwt.MapClaims, error) {
+	claims, err := set.VerifyTypedToken(MFAChallengeTokenType, stringToken)
+
+	if err != nil {
+		return nil, errors.New("the two-factor challenge is invalid or has expired, sign in again")
+	}
 
-	return signedToken
+	return claims, nil
 }
 
-func VerifyToken(ctx *gin.Context) (interface{}, error) {
+// VerifyToken returns the claims of the access token in the Authorization
+// header. Whether its session is still active is for the caller to check.
+func (set *KeySet) VerifyToken(ctx *gin.Context) (jwt.MapClaims, error) {
 	errResponse := errors.New("sign in to proceed")
 	headerToken := ctx.Request.Header.Get("Authorization")
 	bearer := strings.HasPrefix(headerToken, "Bearer")
@@ -37,23 +79,21 @@ func VerifyToken(ctx *gin.Context) (interface{}, error) {
 		return nil, errResponse
 	}
 
-	stringToken := strings.Split(headerToken, " ")[1]
+	stringToken := strings.TrimSpace(strings.TrimPrefix(headerToken, "Bearer"))
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file: ", err)
-	}
+	claims, err := set.Parse(stringToken)
 
-	token, _ := jwt.Parse(stringToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errResponse
-		}
+	if err != nil {
+		return nil, errResponse
+	}
 
-		return []byte(os.Getenv("TOKEN_KEY")), nil
-	})
+	if _, ok := claims["typ"]; ok {
+		return nil, errResponse
+	}
 
-	if _, ok := token.Claims.(jwt.MapClaims); !ok && !token.Valid {
+	if !hasAudience(claims, set.Options.Audience) {
 		return nil, errResponse
 	}
 
-	return token.Claims.(jwt.MapClaims), nil
+	return claims, nil
 }
@@ -0,0 +1,250 @@
+package helpers
+
+import (
+	"api-mygram-go/config"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningKey is one entry of a KeySet. Keys without a private half only
+// verify tokens, which is how a retired key stays valid until its tokens
+// expire.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+type TokenOptions struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+// KeySet signs tokens with its active key and verifies them with whichever
+// key the token's "kid" header names.
+type KeySet struct {
+	Options TokenOptions
+	active  string
+	keys    map[string]SigningKey
+}
+
+func NewKeySet(options TokenOptions, active string, keys ...SigningKey) (*KeySet, error) {
+	set := &KeySet{Options: options, active: active, keys: map[string]SigningKey{}}
+
+	for _, key := range keys {
+		set.keys[key.ID] = key
+	}
+
+	signing, ok := set.keys[active]
+
+	if !ok || signing.PrivateKey == nil {
+		return nil, fmt.Errorf("the active signing key %q has no private key", active)
+	}
+
+	return set, nil
+}
+
+// NewKeySetFromConfig builds the key set for cfg.Algorithm. HS256 signs with
+// cfg.TokenKey. RS256 and EdDSA load the PEM private or public keys listed in
+// cfg.Keys and sign with cfg.ActiveKID, or the first key.
+func NewKeySetFromConfig(cfg config.JWT) (*KeySet, error) {
+	options := TokenOptions{
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+		TTL:      cfg.TTL,
+	}
+
+	if cfg.Algorithm == "HS256" {
+		if cfg.TokenKey == "" {
+			return nil, errors.New("TOKEN_KEY is required to sign HS256 tokens")
+		}
+
+		return NewKeySet(options, "default", SigningKey{
+			ID:         "default",
+			Method:     jwt.SigningMethodHS256,
+			PrivateKey: []byte(cfg.TokenKey),
+			PublicKey:  []byte(cfg.TokenKey),
+		})
+	}
+
+	var keys []SigningKey
+
+	for _, entry := range cfg.Keys {
+		key, err := LoadSigningKey(entry.ID, cfg.Algorithm, entry.Path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	active := cfg.ActiveKID
+
+	if active == "" && len(keys) > 0 {
+		active = keys[0].ID
+	}
+
+	return NewKeySet(options, active, keys...)
+}
+
+// LoadSigningKey reads a PEM encoded PKCS#8, PKCS#1 or PKIX key from path.
+func LoadSigningKey(kid, algorithm, path string) (SigningKey, error) {
+	raw, err := os.ReadFile(path)
+
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	block, _ := pem.Decode(raw)
+
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("%s doesn't contain a PEM block", path)
+	}
+
+	key := SigningKey{ID: kid}
+
+	switch algorithm {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		key.Method = SigningMethodEdDSA
+	default:
+		return key, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	var parsed interface{}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	if err != nil {
+		return key, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch parsed := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.PrivateKey, key.PublicKey = parsed, &parsed.PublicKey
+	case ed25519.PrivateKey:
+		key.PrivateKey, key.PublicKey = parsed, parsed.Public()
+	case *rsa.PublicKey, ed25519.PublicKey:
+		key.PublicKey = parsed
+	default:
+		return key, fmt.Errorf("%s: unsupported key type %T", path, parsed)
+	}
+
+	if _, isRSA := key.PublicKey.(*rsa.PublicKey); isRSA != (algorithm == "RS256") {
+		return key, fmt.Errorf("%s: key doesn't match algorithm %s", path, algorithm)
+	}
+
+	return key, nil
+}
+
+func (set *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	key := set.keys[set.active]
+	now := time.Now()
+
+	claims["iss"] = set.Options.Issuer
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = now.Add(set.Options.TTL).Unix()
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.PrivateKey)
+}
+
+// Parse verifies the signature, issuer, expiry and not-before time of
+// stringToken. Callers check the audience and purpose.
+func (set *KeySet) Parse(stringToken string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(stringToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := set.keys[kid]
+
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		return key.PublicKey, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("token has no expiry")
+	}
+
+	if !claims.VerifyIssuer(set.Options.Issuer, true) {
+		return nil, errors.New("unexpected issuer")
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public keys of the set, sorted by key ID. Symmetric keys
+// are never published.
+func (set *KeySet) JWKS() JSONWebKeySet {
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for _, key := range set.keys {
+		if jwk, ok := NewJSONWebKey(key.ID, key.Method.Alg(), key.PublicKey); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+
+	return jwks
+}
+
+func hasAudience(claims jwt.MapClaims, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, value := range aud {
+			if value == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"api-mygram-go/config"
+)
+
+// PasswordHasher hashes passwords with one algorithm and reports whether an
+// existing hash should be upgraded to its current parameters.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hashedPassword, password []byte) bool
+	NeedsRehash(hashedPassword []byte) bool
+	Supports(hashedPassword []byte) bool
+}
+
+var passwordHashers = []PasswordHasher{
+	NewBcryptHasher(DefaultBcryptCost),
+	NewArgon2idHasher(DefaultArgon2idParams),
+}
+
+// migratingHasher hashes new passwords with current. Hashes made by the
+// bcrypt or argon2id hashers keep verifying so accounts migrate on login.
+type migratingHasher struct {
+	current PasswordHasher
+}
+
+// NewMigratingHasher wraps current so it also verifies the hashes of the other
+// algorithms, and reports them as needing a rehash.
+func NewMigratingHasher(current PasswordHasher) *migratingHasher {
+	return &migratingHasher{current}
+}
+
+// NewPasswordHasher builds the hasher selected by cfg.Hasher ("bcrypt" or
+// "argon2id") with its cost settings.
+func NewPasswordHasher(cfg config.Password) *migratingHasher {
+	if cfg.Hasher == "argon2id" {
+		return NewMigratingHasher(NewArgon2idHasher(Argon2idParams{
+			Time:    cfg.Argon2Time,
+			Memory:  cfg.Argon2Memory,
+			Threads: cfg.Argon2Threads,
+			KeyLen:  DefaultArgon2idParams.KeyLen,
+			SaltLen: DefaultArgon2idParams.SaltLen,
+		}))
+	}
+
+	return NewMigratingHasher(NewBcryptHasher(cfg.BcryptCost))
+}
+
+func (hasher *migratingHasher) hasherFor(hashedPassword []byte) PasswordHasher {
+	if hasher.current.Supports(hashedPassword) {
+		return hasher.current
+	}
+
+	for _, other := range passwordHashers {
+		if other.Supports(hashedPassword) {
+			return other
+		}
+	}
+
+	return nil
+}
+
+func (hasher *migratingHasher) Hash(password string) (string, error) {
+	return hasher.current.Hash(password)
+}
+
+func (hasher *migratingHasher) Compare(hashedPassword, password []byte) bool {
+	other := hasher.hasherFor(hashedPassword)
+
+	if other == nil {
+		return false
+	}
+
+	return other.Compare(hashedPassword, password)
+}
+
+// NeedsRehash reports whether hashedPassword was produced by another algorithm
+// or with weaker parameters than the current hasher.
+func (hasher *migratingHasher) NeedsRehash(hashedPassword []byte) bool {
+	if !hasher.current.Supports(hashedPassword) {
+		return true
+	}
+
+	return hasher.current.NeedsRehash(hashedPassword)
+}
+
+func (hasher *migratingHasher) Supports(hashedPassword []byte) bool {
+	return hasher.hasherFor(hashedPassword) != nil
+}
@@ -0,0 +1,66 @@
+package helpers_test
+
+import (
+	"api-mygram-go/helpers"
+	"testing"
+)
+
+var weakArgon2idParams = helpers.Argon2idParams{Time: 1, Memory: 8 * 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+
+func TestPasswordHashers(t *testing.T) {
+	tests := []struct {
+		name     string
+		hasher   helpers.PasswordHasher
+		stronger helpers.PasswordHasher
+		other    helpers.PasswordHasher
+	}{
+		{"bcrypt", helpers.NewBcryptHasher(4), helpers.NewBcryptHasher(5), helpers.NewArgon2idHasher(weakArgon2idParams)},
+		{"argon2id", helpers.NewArgon2idHasher(weakArgon2idParams), helpers.NewArgon2idHasher(helpers.Argon2idParams{Time: 2, Memory: 8 * 1024, Threads: 1, KeyLen: 32, SaltLen: 16}), helpers.NewBcryptHasher(4)},
+	}
+
+	for _, test := range tests {
+		hash, err := test.hasher.Hash("secret")
+
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if again, _ := test.hasher.Hash("secret"); again == hash {
+			t.Errorf("%s: hashing twice gave the same hash, want a new salt", test.name)
+		}
+
+		if !test.hasher.Compare([]byte(hash), []byte("secret")) || test.hasher.Compare([]byte(hash), []byte("wrong")) {
+			t.Errorf("%s: the hash doesn't tell the password from a wrong one", test.name)
+		}
+
+		if !test.hasher.Supports([]byte(hash)) || test.other.Supports([]byte(hash)) {
+			t.Errorf("%s: the hash isn't recognized as its own", test.name)
+		}
+
+		if test.hasher.NeedsRehash([]byte(hash)) || !test.stronger.NeedsRehash([]byte(hash)) {
+			t.Errorf("%s: needs rehash = %t, %t with stronger parameters, want false, true", test.name, test.hasher.NeedsRehash([]byte(hash)), test.stronger.NeedsRehash([]byte(hash)))
+		}
+	}
+}
+
+func TestMigratingHasherKeepsVerifyingHashesOfTheOtherAlgorithm(t *testing.T) {
+	bcryptHash, err := helpers.NewMigratingHasher(helpers.NewBcryptHasher(4)).Hash("secret")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasher := helpers.NewMigratingHasher(helpers.NewArgon2idHasher(weakArgon2idParams))
+
+	if !hasher.Compare([]byte(bcryptHash), []byte("secret")) {
+		t.Error("the bcrypt hash no longer verifies once argon2id hashes new passwords")
+	}
+
+	if !hasher.NeedsRehash([]byte(bcryptHash)) {
+		t.Error("the bcrypt hash doesn't need a rehash to argon2id")
+	}
+
+	if hasher.Compare([]byte("plain secret"), []byte("plain secret")) {
+		t.Error("a value of no known algorithm verified")
+	}
+}
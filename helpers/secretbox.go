@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// sealedPrefix versions the values sealed by a SecretBox, should their
+// format ever change.
+const sealedPrefix = "v1:"
+
+var errUnsealable = errors.New("the secret can't be decrypted, check the encryption key")
+
+// SecretBox encrypts the secrets kept in the database with AES-256-GCM, under
+// a key derived from a configured one. Each value is bound to the row it
+// belongs to, so it can't be copied over to another one.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(key string) *SecretBox {
+	sum := sha256.Sum256([]byte(key))
+	block, _ := aes.NewCipher(sum[:])
+	aead, _ := cipher.NewGCM(block)
+
+	return &SecretBox{aead}
+}
+
+// Seal encrypts secret for the row identified by owner.
+func (box *SecretBox) Seal(secret string, owner string) (string, error) {
+	nonce := make([]byte, box.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := box.aead.Seal(nonce, nonce, []byte(secret), []byte(owner))
+
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret sealed for owner.
+func (box *SecretBox) Open(stored string, owner string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, sealedPrefix)
+
+	if !ok {
+		return "", errUnsealable
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+
+	if err != nil || len(sealed) < box.aead.NonceSize() {
+		return "", errUnsealable
+	}
+
+	nonce, ciphertext := sealed[:box.aead.NonceSize()], sealed[box.aead.NonceSize():]
+	secret, err := box.aead.Open(nil, nonce, ciphertext, []byte(owner))
+
+	if err != nil {
+		return "", errUnsealable
+	}
+
+	return string(secret), nil
+}
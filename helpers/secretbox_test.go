@@ -0,0 +1,41 @@
+package helpers_test
+
+import (
+	"api-mygram-go/helpers"
+	"strings"
+	"testing"
+)
+
+func TestSecretBox(t *testing.T) {
+	box := helpers.NewSecretBox("totp-key")
+
+	sealed, err := box.Seal(rfc6238Secret, "user-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(sealed, rfc6238Secret) {
+		t.Errorf("sealed %s holds the secret in clear", sealed)
+	}
+
+	if again, _ := box.Seal(rfc6238Secret, "user-1"); again == sealed {
+		t.Error("sealing twice gave the same value, want a new nonce")
+	}
+
+	if secret, err := box.Open(sealed, "user-1"); err != nil || secret != rfc6238Secret {
+		t.Errorf("opened %q, %v, want the secret", secret, err)
+	}
+
+	if _, err = box.Open(sealed, "user-2"); err == nil {
+		t.Error("the secret opened for another user")
+	}
+
+	if _, err = helpers.NewSecretBox("other-key").Open(sealed, "user-1"); err == nil {
+		t.Error("the secret opened with another key")
+	}
+
+	if _, err = box.Open(rfc6238Secret, "user-1"); err == nil {
+		t.Error("a secret in clear opened")
+	}
+}
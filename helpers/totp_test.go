@@ -0,0 +1,72 @@
+package helpers_test
+
+import (
+	"api-mygram-go/helpers"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890" in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// The last six digits of the RFC 6238 SHA-1 vectors.
+	tests := []struct {
+		at   int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, test := range tests {
+		if got, err := helpers.TOTPCode(rfc6238Secret, helpers.TOTPStep(time.Unix(test.at, 0))); err != nil || got != test.want {
+			t.Errorf("code at %d = %s, %v, want %s", test.at, got, err, test.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	step := helpers.TOTPStep(at)
+
+	tests := []struct {
+		name   string
+		offset int64
+		valid  bool
+	}{
+		{"current", 0, true},
+		{"previous", -1, true},
+		{"next", 1, true},
+		{"too old", -2, false},
+		{"too early", 2, false},
+	}
+
+	for _, test := range tests {
+		code, _ := helpers.TOTPCode(rfc6238Secret, step+test.offset)
+		matched, ok := helpers.ValidateTOTP(rfc6238Secret, code[:3]+" "+code[3:], at)
+
+		if ok != test.valid || (ok && matched != step+test.offset) {
+			t.Errorf("%s code: step %d, %t, want %d, %t", test.name, matched, ok, step+test.offset, test.valid)
+		}
+	}
+
+	if _, ok := helpers.ValidateTOTP(rfc6238Secret, "12345", at); ok {
+		t.Error("a five digit code was accepted")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := helpers.TOTPProvisioningURI("MyGram", "johndoe@example.com", rfc6238Secret)
+
+	for _, want := range []string{"otpauth://totp/MyGram:johndoe@example.com?", "secret=" + rfc6238Secret, "issuer=MyGram", "digits=6", "period=30"} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("%s doesn't contain %s", uri, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package imagemeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// MaxFetchSize bounds the size of the images fetched from photo URLs.
+const MaxFetchSize = 20 << 20
+
+var errAddressNotAllowed = errors.New("the address isn't a public one")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in all but name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a domain.ImageFetcher downloading images over HTTP(S).
+// As the URLs come from users, it only connects to public addresses, gives
+// up after timeout and refuses images larger than MaxFetchSize.
+func NewFetcher(timeout time.Duration) *fetcher {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicAddressesOnly}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       time.Minute,
+	}
+
+	return &fetcher{&http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+
+			return checkScheme(req.URL)
+		},
+	}}
+}
+
+// Fetch downloads the image at rawURL.
+func (fetcher *fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	target, err := url.Parse(rawURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkScheme(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "image/*")
+
+	res, err := fetcher.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the server answered %s", res.Status)
+	}
+
+	if res.ContentLength > MaxFetchSize {
+		return nil, fmt.Errorf("the image is larger than %d MB", MaxFetchSize>>20)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, MaxFetchSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > MaxFetchSize {
+		return nil, fmt.Errorf("the image is larger than %d MB", MaxFetchSize>>20)
+	}
+
+	return data, nil
+}
+
+func checkScheme(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", target.Scheme)
+	}
+
+	return nil
+}
+
+// publicAddressesOnly is a net.Dialer Control refusing connections to
+// loopback, private, link-local and other non public addresses. It runs on
+// the resolved address, so names resolving to such addresses are refused
+// as well, whatever redirects lead to them.
+func publicAddressesOnly(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	addr := addrPort.Addr().Unmap()
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("connecting to %s: %w", addr, errAddressNotAllowed)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package imagemeta_test
+
+import (
+	"api-mygram-go/imagemeta"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetcherRefusesWhatIsntPublic(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+
+	defer server.Close()
+
+	fetcher := imagemeta.NewFetcher(time.Second)
+
+	for _, url := range []string{server.URL + "/image.jpg", "http://[::1]:9/image.jpg", "file:///etc/passwd"} {
+		if _, err := fetcher.Fetch(context.Background(), url); err == nil || !strings.Contains(err.Error(), "public") && !strings.Contains(err.Error(), "scheme") {
+			t.Errorf("fetching %s returned %v, want it refused", url, err)
+		}
+	}
+
+	if requested {
+		t.Error("the fetcher connected to a loopback address")
+	}
+}
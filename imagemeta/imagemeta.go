@@ -0,0 +1,140 @@
+// Package imagemeta reads what image files say about themselves, strips
+// where they were taken from them, and fetches them from the web.
+package imagemeta
+
+import (
+	"api-mygram-go/domain"
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	_ "golang.org/x/image/webp"
+)
+
+// exifTimeLayout is how EXIF records times. It has no time zone, so times
+// are read as UTC: the wall clock time of the camera.
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// Read returns the metadata of the image in data. Its dimensions, type and
+// size are always known; the camera, time and place only when a JPEG image
+// records them in EXIF. Anything else than a JPEG, PNG, GIF or WebP image
+// is rejected with domain.ErrInvalidPhoto.
+func Read(data []byte) (metadata domain.PhotoMetadata, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return metadata, domain.ErrInvalidPhoto
+	}
+
+	metadata = domain.PhotoMetadata{
+		Width:    config.Width,
+		Height:   config.Height,
+		MimeType: "image/" + format,
+		FileSize: int64(len(data)),
+	}
+
+	if format != "jpeg" {
+		return metadata, nil
+	}
+
+	// Damaged EXIF only costs the fields that can't be read.
+	x, _ := exif.Decode(bytes.NewReader(data))
+
+	if x == nil {
+		return metadata, nil
+	}
+
+	if rotated(orientation(x)) {
+		metadata.Width, metadata.Height = metadata.Height, metadata.Width
+	}
+
+	metadata.CameraModel = truncate(stringTag(x, exif.Model), 100)
+
+	for _, name := range []exif.FieldName{exif.DateTimeOriginal, exif.DateTime} {
+		if takenAt, err := time.Parse(exifTimeLayout, stringTag(x, name)); err == nil {
+			metadata.TakenAt = &takenAt
+
+			break
+		}
+	}
+
+	if lat, lng, err := x.LatLong(); err == nil && validCoordinates(lat, lng) {
+		metadata.Latitude, metadata.Longitude = &lat, &lng
+	}
+
+	return metadata, nil
+}
+
+func stringTag(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+
+	if err != nil {
+		return ""
+	}
+
+	value, err := tag.StringVal()
+
+	if err != nil {
+		return ""
+	}
+
+	return Clean(value)
+}
+
+// Clean makes text read from an image fit for the database: valid UTF-8,
+// without NUL characters nor surrounding spaces.
+func Clean(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(strings.ToValidUTF8(s, ""), "\x00", ""))
+}
+
+// truncate cuts s to at most n characters.
+func truncate(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+
+		n--
+	}
+
+	return s
+}
+
+// orientation returns the EXIF orientation of an image, 1 when unknown.
+func orientation(x *exif.Exif) int {
+	tag, err := x.Get(exif.Orientation)
+
+	if err != nil {
+		return 1
+	}
+
+	value, err := tag.Int(0)
+
+	if err != nil || value < 1 || value > 8 {
+		return 1
+	}
+
+	return value
+}
+
+// rotated reports whether an image with orientation is shown turned by a
+// quarter, so its width is shown as its height.
+func rotated(orientation int) bool {
+	return orientation >= 5
+}
+
+// validCoordinates rejects what cameras without a fix write: nothing at
+// all, or out of range values.
+func validCoordinates(lat float64, lng float64) bool {
+	if math.IsNaN(lat) || math.IsNaN(lng) || (lat == 0 && lng == 0) {
+		return false
+	}
+
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
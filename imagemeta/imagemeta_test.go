@@ -0,0 +1,216 @@
+package imagemeta_test
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/imagemeta"
+	"api-mygram-go/imagemeta/imagemetatest"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+func TestRead(t *testing.T) {
+	data := imagemetatest.JPEG(t, 80, 60, imagemetatest.EXIF{
+		Model:     "Pixel 8",
+		TakenAt:   "2024:05:17 18:04:05",
+		GPS:       true,
+		Latitude:  -6.175392,
+		Longitude: 106.827153,
+	})
+
+	metadata, err := imagemeta.Read(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Width != 80 || metadata.Height != 60 || metadata.MimeType != "image/jpeg" || metadata.FileSize != int64(len(data)) {
+		t.Errorf("read %+v, want the dimensions, type and size of the image", metadata)
+	}
+
+	if metadata.CameraModel != "Pixel 8" {
+		t.Errorf("camera model = %q, want Pixel 8", metadata.CameraModel)
+	}
+
+	if want := time.Date(2024, 5, 17, 18, 4, 5, 0, time.UTC); metadata.TakenAt == nil || !metadata.TakenAt.Equal(want) {
+		t.Errorf("taken at = %v, want %v", metadata.TakenAt, want)
+	}
+
+	if metadata.Latitude == nil || math.Abs(*metadata.Latitude+6.175392) > 1e-6 || math.Abs(*metadata.Longitude-106.827153) > 1e-6 {
+		t.Errorf("location = %v, %v, want -6.175392, 106.827153", metadata.Latitude, metadata.Longitude)
+	}
+}
+
+func TestReadCleansTheCameraModel(t *testing.T) {
+	model := "Kamera \xff" + strings.Repeat("é", 120)
+	metadata, err := imagemeta.Read(imagemetatest.JPEG(t, 80, 60, imagemetatest.EXIF{Model: model}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Kamera " + strings.Repeat("é", 93); metadata.CameraModel != want {
+		t.Errorf("camera model = %q, want %q", metadata.CameraModel, want)
+	}
+}
+
+func TestClean(t *testing.T) {
+	if cleaned := imagemeta.Clean(" Pixel\x00 8\xff\x00 "); cleaned != "Pixel 8" {
+		t.Errorf("cleaned = %q, want Pixel 8", cleaned)
+	}
+}
+
+func TestReadSwapsTheDimensionsOfTurnedImages(t *testing.T) {
+	metadata, err := imagemeta.Read(imagemetatest.JPEG(t, 80, 60, imagemetatest.EXIF{Orientation: 6}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Width != 60 || metadata.Height != 80 {
+		t.Errorf("dimensions = %dx%d, want 60x80 as shown", metadata.Width, metadata.Height)
+	}
+}
+
+func TestReadRejectsWhatIsntAnImage(t *testing.T) {
+	if _, err := imagemeta.Read([]byte("%PDF-1.7")); !errors.Is(err, domain.ErrInvalidPhoto) {
+		t.Errorf("reading a pdf returned %v, want %v", err, domain.ErrInvalidPhoto)
+	}
+}
+
+func TestStripLocationFromJPEG(t *testing.T) {
+	data := imagemetatest.JPEG(t, 80, 60, imagemetatest.EXIF{
+		Model:       "Pixel 8",
+		Orientation: 6,
+		GPS:         true,
+		Latitude:    -6.175392,
+		Longitude:   106.827153,
+	})
+
+	stripped, err := imagemeta.StripLocation(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := imagemeta.Read(stripped)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Latitude != nil || metadata.CameraModel != "" {
+		t.Errorf("read %+v from the stripped image, want no EXIF left", metadata)
+	}
+
+	if metadata.Width != 60 || metadata.Height != 80 {
+		t.Errorf("dimensions = %dx%d, want the orientation kept", metadata.Width, metadata.Height)
+	}
+
+	if _, _, err = image.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("the stripped image doesn't decode: %v", err)
+	}
+}
+
+func TestStripLocationDropsWhatFollowsTheJPEGImage(t *testing.T) {
+	// Like the secondary image of an MPF file, with its own EXIF.
+	trailer := imagemetatest.JPEG(t, 40, 30, imagemetatest.EXIF{GPS: true, Latitude: -6.175392, Longitude: 106.827153})
+	primary := imagemetatest.JPEG(t, 80, 60, imagemetatest.EXIF{Model: "Pixel 8"})
+	data := append(append([]byte{}, primary...), trailer...)
+
+	stripped, err := imagemeta.StripLocation(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x, err := exif.Decode(bytes.NewReader(stripped)); err == nil {
+		if lat, long, err := x.LatLong(); err == nil {
+			t.Errorf("found the location %f, %f in the stripped image", lat, long)
+		}
+	}
+
+	if bytes.Contains(stripped, trailer[len(trailer)-64:]) {
+		t.Error("the data following the image was kept")
+	}
+
+	if metadata, err := imagemeta.Read(stripped); err != nil || metadata.Width != 80 || metadata.Height != 60 {
+		t.Errorf("read %+v, %v from the stripped image, want the 80x60 image", metadata, err)
+	}
+}
+
+func TestStripLocationFromPNG(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := buf.Bytes()
+	ihdrEnd := 8 + 12 + int(binary.BigEndian.Uint32(encoded[8:]))
+
+	data := append([]byte{}, encoded[:ihdrEnd]...)
+	data = append(data, pngChunk("tEXt", "GPSLatitude\x00-6.175392")...)
+	data = append(data, encoded[ihdrEnd:]...)
+
+	stripped, err := imagemeta.StripLocation(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(stripped, encoded) {
+		t.Error("the text chunk wasn't stripped, or more was")
+	}
+}
+
+func TestStripLocationFromWebP(t *testing.T) {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	data = append(data, webpChunk("VP8X", "\x0c\x00\x00\x00\x03\x00\x00\x03\x00\x00")...)
+	data = append(data, webpChunk("VP8L", "\x2f\x03\x00\x00\x00")...)
+	data = append(data, webpChunk("EXIF", "MM\x00*\x00\x00\x00\x08")...)
+	data = append(data, webpChunk("XMP ", "<x:xmpmeta/>")...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	stripped, err := imagemeta.StripLocation(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte("RIFF\x00\x00\x00\x00WEBP")
+	want = append(want, webpChunk("VP8X", "\x00\x00\x00\x00\x03\x00\x00\x03\x00\x00")...)
+	want = append(want, webpChunk("VP8L", "\x2f\x03\x00\x00\x00")...)
+	binary.LittleEndian.PutUint32(want[4:], uint32(len(want)-8))
+
+	if !bytes.Equal(stripped, want) {
+		t.Errorf("stripped to %q, want %q", stripped, want)
+	}
+}
+
+func pngChunk(kind string, data string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind+data...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(kind+data)))
+}
+
+func webpChunk(kind string, data string) []byte {
+	chunk := append([]byte(kind), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+
+	return chunk
+}
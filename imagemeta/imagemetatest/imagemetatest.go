@@ -0,0 +1,192 @@
+// Package imagemetatest builds images carrying metadata, as cameras and
+// phones write it, for the tests of the code reading it.
+package imagemetatest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"testing"
+)
+
+// EXIF is what JPEG records in the EXIF block of its image. TakenAt is
+// written as EXIF does, as in 2024:05:17 18:04:05. The coordinates are only
+// written with GPS.
+type EXIF struct {
+	Model       string
+	TakenAt     string
+	Orientation int
+	GPS         bool
+	Latitude    float64
+	Longitude   float64
+}
+
+// JPEG returns a width by height JPEG image holding exif.
+func JPEG(t testing.TB, width int, height int, exif EXIF) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := buf.Bytes()
+	block := append([]byte("Exif\x00\x00"), tiff(exif)...)
+
+	out := append([]byte{}, encoded[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(block)+2))
+	out = append(out, block...)
+
+	return append(out, encoded[2:]...)
+}
+
+// TIFF field types.
+const (
+	asciiType    = 2
+	shortType    = 3
+	longType     = 4
+	rationalType = 5
+)
+
+type entry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+func tiff(exif EXIF) []byte {
+	var subIFD, gpsIFD []entry
+
+	if exif.TakenAt != "" {
+		subIFD = append(subIFD, ascii(0x9003, exif.TakenAt))
+	}
+
+	if exif.GPS {
+		gpsIFD = []entry{
+			ascii(0x0001, hemisphere(exif.Latitude, "N", "S")),
+			degrees(0x0002, exif.Latitude),
+			ascii(0x0003, hemisphere(exif.Longitude, "E", "W")),
+			degrees(0x0004, exif.Longitude),
+		}
+	}
+
+	// The pointers to the other IFDs are inlined, so the size of the first
+	// one doesn't depend on their values.
+	first := func(subOffset uint32, gpsOffset uint32) []entry {
+		var entries []entry
+
+		if exif.Model != "" {
+			entries = append(entries, ascii(0x0110, exif.Model))
+		}
+
+		if exif.Orientation != 0 {
+			entries = append(entries, entry{0x0112, shortType, 1, binary.BigEndian.AppendUint16(nil, uint16(exif.Orientation))})
+		}
+
+		if subIFD != nil {
+			entries = append(entries, entry{0x8769, longType, 1, binary.BigEndian.AppendUint32(nil, subOffset)})
+		}
+
+		if gpsIFD != nil {
+			entries = append(entries, entry{0x8825, longType, 1, binary.BigEndian.AppendUint32(nil, gpsOffset)})
+		}
+
+		return entries
+	}
+
+	subOffset := 8 + uint32(len(ifd(0, first(0, 0))))
+	gpsOffset := subOffset
+
+	if subIFD != nil {
+		gpsOffset += uint32(len(ifd(subOffset, subIFD)))
+	}
+
+	out := []byte{'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08}
+	out = append(out, ifd(8, first(subOffset, gpsOffset))...)
+
+	if subIFD != nil {
+		out = append(out, ifd(subOffset, subIFD)...)
+	}
+
+	if gpsIFD != nil {
+		out = append(out, ifd(gpsOffset, gpsIFD)...)
+	}
+
+	return out
+}
+
+// ifd returns an IFD starting at offset, followed by the values too large
+// to be inlined.
+func ifd(offset uint32, entries []entry) []byte {
+	dataOffset := offset + 2 + 12*uint32(len(entries)) + 4
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(entries)))
+
+	var data []byte
+
+	for _, e := range entries {
+		out = binary.BigEndian.AppendUint16(out, e.tag)
+		out = binary.BigEndian.AppendUint16(out, e.kind)
+		out = binary.BigEndian.AppendUint32(out, e.count)
+
+		if len(e.value) <= 4 {
+			out = append(out, append(e.value, make([]byte, 4-len(e.value))...)...)
+
+			continue
+		}
+
+		out = binary.BigEndian.AppendUint32(out, dataOffset+uint32(len(data)))
+		data = append(data, e.value...)
+
+		if len(data)%2 == 1 {
+			data = append(data, 0)
+		}
+	}
+
+	out = binary.BigEndian.AppendUint32(out, 0)
+
+	return append(out, data...)
+}
+
+func ascii(tag uint16, value string) entry {
+	return entry{tag, asciiType, uint32(len(value) + 1), append([]byte(value), 0)}
+}
+
+// degrees writes the absolute value of angle as degrees, minutes and
+// seconds.
+func degrees(tag uint16, angle float64) entry {
+	angle = math.Abs(angle)
+	d := math.Floor(angle)
+	m := math.Floor((angle - d) * 60)
+	s := ((angle-d)*60 - m) * 60
+
+	var value []byte
+
+	for _, rational := range [][2]uint32{{uint32(d), 1}, {uint32(m), 1}, {uint32(math.Round(s * 10000)), 10000}} {
+		value = binary.BigEndian.AppendUint32(value, rational[0])
+		value = binary.BigEndian.AppendUint32(value, rational[1])
+	}
+
+	return entry{tag, rationalType, 3, value}
+}
+
+func hemisphere(angle float64, positive string, negative string) string {
+	if angle < 0 {
+		return negative
+	}
+
+	return positive
+}
@@ -0,0 +1,197 @@
+package imagemeta
+
+import (
+	"api-mygram-go/domain"
+	"bytes"
+	"encoding/binary"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	jpegSOI      = []byte{0xFF, 0xD8}
+)
+
+// StripLocation returns the image in data without the metadata that can
+// tell where it was taken: EXIF, XMP and IPTC in JPEG images, EXIF and text
+// chunks in PNG images, EXIF and XMP in WebP images. The pixels are left
+// untouched, and so is the EXIF orientation of JPEG images, without which
+// they'd be shown turned. GIF images are returned as they are.
+func StripLocation(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebP(data)
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return data, nil
+	}
+
+	return nil, domain.ErrInvalidPhoto
+}
+
+// stripJPEG drops the APP1 (EXIF, XMP) and APP13 (IPTC) segments, and
+// writes back the orientation on its own. It stops at the end of the image:
+// what follows, as the secondary images of MPF files or the videos of motion
+// photos, may hold EXIF of its own.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, jpegSOI...)
+
+	if x, _ := exif.Decode(bytes.NewReader(data)); x != nil {
+		if value := orientation(x); value != 1 {
+			out = append(out, orientationSegment(value)...)
+		}
+	}
+
+	scanned := false
+
+	for i := len(jpegSOI); ; {
+		if scanned && i == len(data) {
+			return out, nil
+		}
+
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		marker := data[i+1]
+
+		switch {
+		case marker == 0xFF:
+			i++
+
+			continue
+		case marker == 0xD9 && scanned:
+			return append(out, data[i:i+2]...), nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			out = append(out, data[i:i+2]...)
+			i += 2
+
+			continue
+		case marker == 0xD9 || i+4 > len(data):
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+
+		if end < i+4 || end > len(data) {
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		if marker == 0xDA {
+			scanned = true
+			end = scanEnd(data, end)
+		}
+
+		if marker != 0xE1 && marker != 0xED {
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+}
+
+// scanEnd returns where the entropy-coded data starting at i ends, at the
+// next marker other than a restart one. An image cut short ends with its
+// data.
+func scanEnd(data []byte, i int) int {
+	for ; i+1 < len(data); i++ {
+		if data[i] == 0xFF && data[i+1] != 0x00 && (data[i+1] < 0xD0 || data[i+1] > 0xD7) {
+			return i
+		}
+	}
+
+	return len(data)
+}
+
+// orientationSegment returns an APP1 segment holding an EXIF block with
+// nothing but orientation.
+func orientationSegment(orientation int) []byte {
+	return []byte{
+		0xFF, 0xE1, 0x00, 0x22,
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+}
+
+// stripPNG drops the eXIf chunk and the text chunks, XMP being one of them.
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	for i := len(pngSignature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+
+		if length < 0 || end < i || end > len(data) {
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt":
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	return out, nil
+}
+
+// VP8X flags telling the EXIF and XMP chunks are there.
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// stripWebP drops the EXIF and XMP chunks, then fixes the sizes and flags
+// referring to them.
+func stripWebP(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		length := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + length + length%2
+
+		if length < 0 || end < i || end > len(data) {
+			return nil, domain.ErrInvalidPhoto
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+
+			if length > 0 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+
+	return out, nil
+}
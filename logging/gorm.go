@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// stringLiterals matches the quoted values GORM interpolates into the SQL it
+// logs, which include password and key hashes. Postgres quotes values with
+// single quotes, while SQLite uses double quotes and backticks identifiers.
+var stringLiterals = map[string]*regexp.Regexp{
+	"postgres": regexp.MustCompile(`'(?:[^']|'')*'`),
+	"sqlite":   regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"`),
+}
+
+// gormLogger sends GORM's logs to the logger of the request that ran the
+// query. Failed queries are logged as errors and slow ones as warnings.
+type gormLogger struct {
+	level              logger.LogLevel
+	slowQueryThreshold time.Duration
+	driver             string
+}
+
+func NewGormLogger(slowQueryThreshold time.Duration, driver string) *gormLogger {
+	return &gormLogger{logger.Warn, slowQueryThreshold, driver}
+}
+
+func (gormLogger *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *gormLogger
+	copied.level = level
+
+	return &copied
+}
+
+func (gormLogger *gormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.level >= logger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (gormLogger *gormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.level >= logger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (gormLogger *gormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.level >= logger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (gormLogger *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if gormLogger.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && gormLogger.level >= logger.Error:
+		sql, rows := fc()
+		FromContext(ctx).ErrorContext(ctx, "query failed", "error", err.Error(), "sql", RedactSQL(sql, gormLogger.driver), "rows", rows, "elapsed_ms", milliseconds(elapsed))
+	case gormLogger.slowQueryThreshold > 0 && elapsed > gormLogger.slowQueryThreshold && gormLogger.level >= logger.Warn:
+		sql, rows := fc()
+		FromContext(ctx).WarnContext(ctx, "slow query", "sql", RedactSQL(sql, gormLogger.driver), "rows", rows, "elapsed_ms", milliseconds(elapsed))
+	case gormLogger.level >= logger.Info:
+		sql, rows := fc()
+		FromContext(ctx).DebugContext(ctx, "query", "sql", RedactSQL(sql, gormLogger.driver), "rows", rows, "elapsed_ms", milliseconds(elapsed))
+	}
+}
+
+// RedactSQL replaces the string values in sql logged by driver.
+func RedactSQL(sql string, driver string) string {
+	literals, ok := stringLiterals[driver]
+
+	if !ok {
+		literals = stringLiterals["postgres"]
+	}
+
+	return literals.ReplaceAllString(sql, "'"+redacted+"'")
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"api-mygram-go/helpers"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxCapturedBody bounds how much of an error response is kept for the log.
+const maxCapturedBody = 1 << 10
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Middleware gives every request an ID, reusing a well formed X-Request-ID
+// from the caller and echoing it back, stores a logger tagged with it in the
+// request context and writes one access log line when the request is done.
+// Failed requests are logged with the message sent to the client.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		requestID := ctx.GetHeader(RequestIDHeader)
+
+		if !validRequestID.MatchString(requestID) {
+			requestID, _ = gonanoid.New()
+		}
+
+		ctx.Header(RequestIDHeader, requestID)
+
+		route := ctx.FullPath()
+
+		if route == "" {
+			route = "unmatched"
+		}
+
+		logger := slog.Default().With("request_id", requestID, "method", ctx.Request.Method, "route", route)
+
+		if span := trace.SpanContextFromContext(ctx.Request.Context()); span.IsValid() {
+			logger = logger.With("trace_id", span.TraceID().String())
+		}
+
+		ctx.Request = ctx.Request.WithContext(WithLogger(ctx.Request.Context(), logger))
+
+		writer := &capturingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attributes := []any{
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"path", ctx.Request.URL.Path,
+			"client_ip", ctx.ClientIP(),
+			"bytes", ctx.Writer.Size(),
+		}
+		level := slog.LevelInfo
+
+		if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+
+			var response helpers.ResponseMessage
+
+			if json.Unmarshal(writer.body.Bytes(), &response) == nil && response.Message != "" {
+				attributes = append(attributes, "error", response.Message)
+			}
+		}
+
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		for _, err := range ctx.Errors {
+			attributes = append(attributes, "error", err.Error())
+		}
+
+		FromContext(ctx.Request.Context()).Log(ctx.Request.Context(), level, "request", attributes...)
+	}
+}
+
+// Recovery turns a panic into a 500 and logs it with the request's logger.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, recovered any) {
+		FromContext(ctx.Request.Context()).Error("panic while handling request", "panic", recovered)
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ResponseMessage{
+			Status:  "error",
+			Message: "something went wrong, please try again later",
+		})
+	})
+}
+
+// capturingWriter keeps the start of error responses so their message can
+// be logged.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (writer *capturingWriter) Write(data []byte) (int, error) {
+	writer.capture(data)
+
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *capturingWriter) WriteString(s string) (int, error) {
+	writer.capture([]byte(s))
+
+	return writer.ResponseWriter.WriteString(s)
+}
+
+func (writer *capturingWriter) capture(data []byte) {
+	if writer.Status() < http.StatusBadRequest {
+		return
+	}
+
+	if room := maxCapturedBody - writer.body.Len(); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+
+		writer.body.Write(data)
+	}
+}
@@ -0,0 +1,77 @@
+package logging_test
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/logging"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddlewareTagsTheLogWithTheRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+
+	defaultLogger := slog.Default()
+	slog.SetDefault(logging.New(config.Logging{Level: "info"}, &buf))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	router := gin.New()
+	router.Use(logging.Middleware())
+	router.GET("/photos/:photoId", func(ctx *gin.Context) {
+		logging.FromContext(ctx.Request.Context()).Info("handling")
+		ctx.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		sent string
+		keep bool
+	}{
+		{"req-1234", true},
+		{"", false},
+		{"not valid\n", false},
+	}
+
+	for _, test := range tests {
+		buf.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/photos/photo-1", nil)
+
+		if test.sent != "" {
+			req.Header.Set(logging.RequestIDHeader, test.sent)
+		}
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		requestID := res.Header().Get(logging.RequestIDHeader)
+
+		if requestID == "" || (requestID == test.sent) != test.keep {
+			t.Errorf("sent %q, got back %q", test.sent, requestID)
+		}
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+
+		if len(lines) != 2 {
+			t.Fatalf("logged %s, want the handler's line and the access line", buf.String())
+		}
+
+		for _, data := range lines {
+			var line map[string]any
+
+			if err := json.Unmarshal(data, &line); err != nil {
+				t.Fatal(err)
+			}
+
+			if line["request_id"] != requestID || line["route"] != "/photos/:photoId" {
+				t.Errorf("logged %s, want request_id %q and the route", data, requestID)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package mail sends account emails through an SMTP server, or writes them to
+// the log when none is configured.
+package mail
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/domain"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewMailer returns the SMTP mailer of cfg, or the log mailer when cfg has no
+// server.
+func NewMailer(cfg config.Mail) domain.Mailer {
+	if cfg.SMTPHost == "" {
+		return NewLogMailer()
+	}
+
+	return NewSMTPMailer(cfg)
+}
+
+type smtpMailer struct {
+	config config.Mail
+}
+
+func NewSMTPMailer(cfg config.Mail) *smtpMailer {
+	return &smtpMailer{cfg}
+}
+
+// Send delivers mail, upgrading the connection with STARTTLS when the server
+// offers it. Credentials are only sent over TLS or to localhost.
+func (mailer *smtpMailer) Send(ctx context.Context, message domain.Mail) (err error) {
+	from, err := mail.ParseAddress(mailer.config.From)
+
+	if err != nil {
+		return fmt.Errorf("parsing the sender address: %w", err)
+	}
+
+	to, err := mail.ParseAddress(message.To)
+
+	if err != nil {
+		return fmt.Errorf("parsing the recipient address: %w", err)
+	}
+
+	data, err := compose(from, to, message, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+	defer cancel()
+
+	host := mailer.config.SMTPHost
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(mailer.config.SMTPPort)))
+
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, host)
+
+	if err != nil {
+		conn.Close()
+
+		return err
+	}
+
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if mailer.config.SMTPUsername != "" {
+		if err = client.Auth(smtp.PlainAuth("", mailer.config.SMTPUsername, mailer.config.SMTPPassword, host)); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(from.Address); err != nil {
+		return err
+	}
+
+	if err = client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = writer.Write(data); err != nil {
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// compose renders message as a quoted-printable UTF-8 text email.
+func compose(from *mail.Address, to *mail.Address, message domain.Mail, date time.Time) ([]byte, error) {
+	if strings.ContainsAny(message.Subject, "\r\n") {
+		return nil, errors.New("the subject can't contain line breaks")
+	}
+
+	var buf bytes.Buffer
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", message.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `text/plain; charset="utf-8"`},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+
+	if _, err := body.Write([]byte(strings.ReplaceAll(message.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+type logMailer struct{}
+
+func NewLogMailer() *logMailer {
+	return &logMailer{}
+}
+
+// Send logs mail instead of delivering it, links included, so it must not be
+// used in production.
+func (mailer *logMailer) Send(ctx context.Context, message domain.Mail) error {
+	slog.InfoContext(ctx, "email not sent, no SMTP server is configured", "to", message.To, "subject", message.Subject, "body", message.Body)
+
+	return nil
+}
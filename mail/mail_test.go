@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"api-mygram-go/domain"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompose(t *testing.T) {
+	from := &mail.Address{Name: "MyGram", Address: "no-reply@example.com"}
+	to := &mail.Address{Address: "janedoe@example.com"}
+	date := time.Date(2030, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	data, err := compose(from, to, domain.Mail{Subject: "Confirm your email – MyGram", Body: "Hello,\nfollow the link."}, date)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(string(data)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+
+	if err != nil || subject != "Confirm your email – MyGram" {
+		t.Errorf("subject = %q (%v)", subject, err)
+	}
+
+	if got := message.Header.Get("To"); got != "<janedoe@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+
+	if !strings.Contains(string(data), "Hello,\r\nfollow the link.") {
+		t.Errorf("body isn't in CRLF lines:\n%s", data)
+	}
+
+	if _, err = compose(from, to, domain.Mail{Subject: "Hi\r\nBcc: someone@example.com"}, date); err == nil {
+		t.Error("a subject with a line break was accepted")
+	}
+}
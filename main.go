@@ -1,28 +1,23 @@
 package main
 
 import (
-	"log"
-	commentDelivery "api-mygram-go/comment/delivery/http"
-	commentRepository "api-mygram-go/comment/repository/postgres"
-	commentUseCase "api-mygram-go/comment/usecase"
+	"api-mygram-go/app"
+	"api-mygram-go/config"
 	"api-mygram-go/config/database"
-	photoDelivery "api-mygram-go/photo/delivery/http"
-	photoRepository "api-mygram-go/photo/repository/postgres"
-	photoUseCase "api-mygram-go/photo/usecase"
-	socialMediaDelivery "api-mygram-go/socialmedia/delivery/http"
-	socialMediaRepository "api-mygram-go/socialmedia/repository/postgres"
-	socialMediaUseCase "api-mygram-go/socialmedia/usecase"
-	userDelivery "api-mygram-go/user/delivery/http"
-	userRepository "api-mygram-go/user/repository/postgres"
-	userUseCase "api-mygram-go/user/usecase"
+	"api-mygram-go/logging"
+	"api-mygram-go/metrics"
+	"api-mygram-go/tracing"
+	"api-mygram-go/worker"
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
-
-	_ "api-mygram-go/docs"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"os/signal"
+	"strconv"
+	"syscall"
 )
 
 // @title MyGram API
@@ -41,56 +36,39 @@ import (
 // @name                        Authorization
 // @description					        Description for what is this security definition being used
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file: ", err)
-	}
-
-	db := database.StartDB()
-
-	routers := gin.Default()
-
-	routers.Use(func(ctx *gin.Context) {
-		ctx.Writer.Header().Set("Content-Type", "application/json")
-		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		ctx.Writer.Header().Set("Access-Control-Max-Age", "86400")
-		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, UPDATE")
-		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Max")
-		ctx.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		if ctx.Request.Method == "OPTIONS" {
-			ctx.AbortWithStatus(200)
-		} else {
-			ctx.Next()
-		}
-	})
+	cfg, err := config.Load()
 
-	userRepository := userRepository.NewUserRepository(db)
-	userUseCase := userUseCase.NewUserUseCase(userRepository)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	userDelivery.NewUserHandler(routers, userUseCase)
+	slog.SetDefault(logging.New(cfg.Logging, os.Stdout))
 
-	photoRepository := photoRepository.NewPhotoRepository(db)
-	photoUseCase := photoUseCase.NewPhotoUseCase(photoRepository)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 
-	photoDelivery.NewPhotoHandler(routers, photoUseCase)
+	if err != nil {
+		log.Fatal("Error starting tracing: ", err)
+	}
 
-	commentRepository := commentRepository.NewCommentRepository(db)
-	commentUseCase := commentUseCase.NewCommentUseCase(commentRepository)
+	db := database.StartDB(cfg.Database, logging.NewGormLogger(cfg.Logging.SlowQueryThreshold, cfg.Database.Driver))
 
-	commentDelivery.NewCommentHandler(routers, commentUseCase, photoUseCase)
+	if err = db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal("Error tracing database: ", err)
+	}
 
-	socialMediaRepository := socialMediaRepository.NewSocialMediaRepository(db)
-	socialMediaUseCase := socialMediaUseCase.NewSocialMediaUseCase(socialMediaRepository)
+	if err = metrics.RegisterDB(db); err != nil {
+		log.Fatal("Error instrumenting database: ", err)
+	}
 
-	socialMediaDelivery.NewSocialMediaHandler(routers, socialMediaUseCase)
+	workers := worker.NewGroup(context.Background())
 
-	routers.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	routers, err := app.NewRouter(cfg, db, workers)
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file: ", err)
+	if err != nil {
+		log.Fatal("Error building router: ", err)
 	}
 
-	port := os.Getenv("PORT")
+	port := strconv.Itoa(cfg.HTTP.Port)
 
 	if len(os.Args) > 1 {
 		reqPort := os.Args[1]
@@ -100,9 +78,59 @@ func main() {
 		}
 	}
 
-	if port == "" {
-		port = "8080"
+	server := &http.Server{
+		Addr:              net.JoinHostPort(cfg.HTTP.Host, port),
+		Handler:           routers,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
 
-	routers.Run(":" + port)
+	go func() {
+		var err error
+
+		if cfg.HTTP.TLS() {
+			log.Printf("Listening on %s with TLS", server.Addr)
+			err = server.ListenAndServeTLS(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile)
+		} else {
+			log.Printf("Listening on %s", server.Addr)
+			err = server.ListenAndServe()
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Error starting server: ", err)
+		}
+	}()
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	defer stop()
+
+	<-signals.Done()
+	stop()
+
+	log.Printf("Shutting down, draining connections for up to %s", cfg.HTTP.ShutdownTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Print("Error draining connections: ", err)
+	}
+
+	if err := workers.Stop(ctx); err != nil {
+		log.Print("Error stopping background workers: ", err)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		log.Print("Error flushing traces: ", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err = sqlDB.Close(); err != nil {
+			log.Print("Error closing database: ", err)
+		}
+	}
 }
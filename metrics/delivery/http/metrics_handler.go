@@ -0,0 +1,23 @@
+package delivery
+
+import (
+	"api-mygram-go/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func NewMetricsHandler(routers *gin.Engine) {
+	routers.GET("/metrics", Metrics())
+}
+
+// Metrics godoc
+// @Summary			Prometheus metrics
+// @Description	HTTP, database and domain metrics in the Prometheus text format
+// @Tags				metrics
+// @Produce			plain
+// @Success			200
+// @Router			/metrics	[get]
+func Metrics() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+}
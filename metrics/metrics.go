@@ -0,0 +1,51 @@
+// Package metrics exposes Prometheus metrics for HTTP traffic, database
+// queries and domain events.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "mygram"
+
+// Registry holds every MyGram metric. It is served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	UsersRegistered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "Accounts created, by password or identity provider.",
+	})
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Password login attempts by result: success, invalid_credentials or locked.",
+	}, []string{"result"})
+	PhotosStored = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "photos_stored_total",
+		Help:      "Photos stored.",
+	})
+	CommentsPosted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "comments_posted_total",
+		Help:      "Comments posted.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		dbQueryDuration,
+		dbQueryErrors,
+		UsersRegistered,
+		Logins,
+		PhotosStored,
+		CommentsPosted,
+	)
+}
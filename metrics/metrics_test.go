@@ -0,0 +1,105 @@
+package metrics_test
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"api-mygram-go/metrics"
+	delivery "api-mygram-go/metrics/delivery/http"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// scrape returns what /metrics serves.
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	router := gin.New()
+	delivery.NewMetricsHandler(router)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("/metrics answered %d", res.Code)
+	}
+
+	return res.Body.String()
+}
+
+func TestMiddlewareRecordsRouteTemplates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(metrics.Middleware())
+	router.GET("/photos/:photoId", func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/photos/photo-1", "/photos/photo-2", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t)
+
+	for _, want := range []string{
+		`mygram_http_requests_total{method="GET",route="/photos/:photoId",status="204"} 2`,
+		`mygram_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`mygram_http_request_duration_seconds_count{method="GET",route="/photos/:photoId"} 2`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics doesn't report %s", want)
+		}
+	}
+
+	if strings.Contains(body, "photo-1") {
+		t.Error("/metrics reports a photo id")
+	}
+}
+
+func TestRegisterDBRecordsQueries(t *testing.T) {
+	db, err := database.Open(config.Database{Driver: "sqlite", SQLitePath: ":memory:"}, &gorm.Config{Logger: logger.Discard})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = metrics.RegisterDB(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.AutoMigrate(&domain.SocialMedia{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var found []domain.SocialMedia
+
+	if err = db.Find(&found).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.Table("missing_table").Find(&[]map[string]any{}).Error; err == nil {
+		t.Fatal("querying a missing table succeeded")
+	}
+
+	body := scrape(t)
+
+	for _, want := range []string{
+		`mygram_db_query_duration_seconds_count{operation="query",table="social_media"} 1`,
+		`mygram_db_query_errors_total{operation="query",table="missing_table"} 1`,
+		`go_sql_max_open_connections{db_name="mygram"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics doesn't report %s", want)
+		}
+	}
+
+	if strings.Contains(body, `mygram_db_query_errors_total{operation="query",table="social_media"}`) {
+		t.Error("a successful query was counted as failed")
+	}
+}
@@ -0,0 +1,288 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/photo/utils"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxManifestBytes and maxManifestRows bound the size of an import.
+	maxManifestBytes = 10 << 20
+	maxManifestRows  = 1000
+)
+
+var errManifestTooLong = fmt.Errorf("the manifest has more than %d rows, split it into several imports", maxManifestRows)
+
+// Import godoc
+// @Summary    	Import photos
+// @Description	Store the photos listed in a CSV or JSON Lines manifest with authentication user. A CSV manifest starts with a header naming its title, caption and photo_url columns, and every line of a JSON Lines manifest is an object with those fields. The format is read from the format query parameter, or else from the Content-Type. Invalid rows are reported and skipped; with dry_run nothing is stored.
+// @Tags        photos
+// @Accept      text/csv
+// @Accept      application/x-ndjson
+// @Produce     json
+// @Param       format	query			string	false	"Format of the manifest"	Enums(csv, jsonl)
+// @Param       dry_run	query			bool		false	"Validate the manifest without storing anything"
+// @Param       body		body			string	true	"Manifest"
+// @Success     200			{object}	utils.ResponseDataImportedPhotos
+// @Success     201			{object}	utils.ResponseDataImportedPhotos
+// @Failure     400			{object}	utils.ResponseMessage
+// @Failure     401			{object}	utils.ResponseMessage
+// @Failure     403			{object}	utils.ResponseMessage
+// @Failure     415			{object}	utils.ResponseMessage
+// @Security    Bearer
+// @Router      /photos/import	[post]
+func (handler *photoHandler) Import(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+	dryRun := false
+
+	if value := ctx.Query("dry_run"); value != "" {
+		var err error
+
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: fmt.Sprintf("dry_run must be true or false, got %q", value),
+			})
+
+			return
+		}
+	}
+
+	var parse func(io.Reader) ([]domain.PhotoImportRow, error)
+
+	switch manifestFormat(ctx) {
+	case "csv":
+		parse = parseCSVManifest
+	case "jsonl":
+		parse = parseJSONLinesManifest
+	default:
+		ctx.AbortWithStatusJSON(http.StatusUnsupportedMediaType, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: "send the manifest as text/csv or application/x-ndjson, or set format to csv or jsonl",
+		})
+
+		return
+	}
+
+	rows, err := parse(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxManifestBytes))
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	report, err := handler.photoUseCase.Import(ctx.Request.Context(), userID, rows, dryRun)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	importedPhotos := utils.ImportedPhotos{
+		DryRun:    report.DryRun,
+		Succeeded: report.Succeeded,
+		Failed:    report.Failed,
+		Rows:      []utils.ImportedPhotoRow{},
+	}
+
+	for _, result := range report.Results {
+		row := utils.ImportedPhotoRow{Line: result.Line, ID: result.PhotoID, Title: result.Title, Error: result.Error}
+
+		switch {
+		case result.Error != "":
+			row.Status = "failed"
+		case report.DryRun:
+			row.Status = "valid"
+		default:
+			row.Status = "imported"
+		}
+
+		importedPhotos.Rows = append(importedPhotos.Rows, row)
+	}
+
+	status := http.StatusOK
+
+	if !report.DryRun && report.Succeeded > 0 {
+		status = http.StatusCreated
+	}
+
+	ctx.JSON(status, helpers.ResponseData{
+		Status: "success",
+		Data:   importedPhotos,
+	})
+}
+
+// manifestFormat returns csv or jsonl from the format query parameter, or
+// else from the Content-Type of the request.
+func manifestFormat(ctx *gin.Context) string {
+	if format := ctx.Query("format"); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return "csv"
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return "jsonl"
+	}
+
+	return ""
+}
+
+// parseCSVManifest reads a CSV manifest. Rows with the wrong number of
+// fields are returned with an error, while a malformed header or quoting
+// rejects the whole manifest.
+func parseCSVManifest(r io.Reader) ([]domain.PhotoImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the manifest is empty")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+
+		switch name {
+		case "title", "caption", "photo_url":
+		default:
+			return nil, fmt.Errorf("unknown column %q, the columns are title, caption and photo_url", name)
+		}
+
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("the column %s is listed twice", name)
+		}
+
+		columns[name] = i
+	}
+
+	for _, name := range []string{"title", "photo_url"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the manifest has no %s column", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+
+		return ""
+	}
+
+	rows := []domain.PhotoImportRow{}
+
+	for {
+		record, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+
+		if len(rows) == maxManifestRows {
+			return nil, errManifestTooLong
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, domain.PhotoImportRow{
+			Line: line,
+			Photo: domain.Photo{
+				Title:    field(record, "title"),
+				Caption:  field(record, "caption"),
+				PhotoUrl: field(record, "photo_url"),
+			},
+			Err: err,
+		})
+	}
+}
+
+// parseJSONLinesManifest reads a JSON Lines manifest, skipping blank lines.
+// Lines that aren't a JSON object are returned with an error.
+func parseJSONLinesManifest(r io.Reader) ([]domain.PhotoImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxManifestBytes)
+
+	rows := []domain.PhotoImportRow{}
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if text == "" {
+			continue
+		}
+
+		if len(rows) == maxManifestRows {
+			return nil, errManifestTooLong
+		}
+
+		var photo utils.AddPhoto
+
+		err := json.Unmarshal([]byte(text), &photo)
+
+		rows = append(rows, domain.PhotoImportRow{
+			Line: line,
+			Photo: domain.Photo{
+				Title:    strings.TrimSpace(photo.Title),
+				Caption:  strings.TrimSpace(photo.Caption),
+				PhotoUrl: strings.TrimSpace(photo.PhotoUrl),
+			},
+			Err: err,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("the manifest is empty")
+	}
+
+	return rows, nil
+}
@@ -1,42 +1,73 @@
 package delivery
 
 import (
+	"api-mygram-go/auth"
 	"api-mygram-go/domain"
 	"api-mygram-go/helpers"
-	"api-mygram-go/photo/delivery/http/middleware"
 	"api-mygram-go/photo/utils"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultNearRadiusKm is the radius of a near filter not given one, and
+	// maxNearRadiusKm bounds it.
+	defaultNearRadiusKm = 10
+	maxNearRadiusKm     = 500
 )
 
 type photoHandler struct {
 	photoUseCase domain.PhotoUseCase
 }
 
-func NewPhotoHandler(routers *gin.Engine, photoUseCase domain.PhotoUseCase) {
+func NewPhotoHandler(routers *gin.Engine, photoUseCase domain.PhotoUseCase, authenticator *auth.Authenticator) {
 	handler := &photoHandler{photoUseCase}
 
 	router := routers.Group("/photos")
 	{
-		router.Use(middleware.Authentication())
-		router.GET("", handler.Fetch)
-		router.POST("", handler.Store)
-		router.PUT("/:photoId", middleware.Authorization(handler.photoUseCase), handler.Update)
-		router.DELETE("/:photoId", middleware.Authorization(handler.photoUseCase), handler.Delete)
+		router.Use(authenticator.Authentication())
+		router.GET("", auth.RequireScope(domain.ScopePhotosRead), handler.Fetch)
+		router.GET("/:photoId", auth.RequireScope(domain.ScopePhotosRead), handler.GetByID)
+		router.POST("", auth.RequireScope(domain.ScopePhotosWrite), handler.Store)
+		router.POST("/upload", auth.RequireScope(domain.ScopePhotosWrite), handler.Upload)
+		router.POST("/import", auth.RequireScope(domain.ScopePhotosWrite), handler.Import)
+		router.PUT("/:photoId", auth.RequireScope(domain.ScopePhotosWrite), auth.Ownership("photo", "photoId", handler.photoOwner), handler.Update)
+		router.DELETE("/:photoId", auth.RequireScope(domain.ScopePhotosWrite), auth.Ownership("photo", "photoId", handler.photoOwner), handler.Delete)
+	}
+
+	routers.GET(domain.PhotoPathPrefix+":userId/:file", handler.GetImage)
+}
+
+// photoOwner loads the owner of a Photo for auth.Ownership.
+func (handler *photoHandler) photoOwner(ctx context.Context, id string) (string, error) {
+	var photo domain.Photo
+
+	if err := handler.photoUseCase.GetByID(ctx, &photo, id); err != nil {
+		return "", err
 	}
+
+	return photo.UserID, nil
 }
 
 // Fetch godoc
 // @Summary    	Fetch all photos
-// @Description	Get all photos with authentication user
+// @Description	Get all photos with authentication user. With near, only the photos whose owners made their location public and that were taken within radius kilometers of near are listed, closest first.
 // @Tags        photos
 // @Accept      json
 // @Produce     json
+// @Param       near		query			string	false	"Latitude and longitude, comma separated"	example(-6.175392,106.827153)
+// @Param       radius	query			number	false	"Radius around near, in kilometers, up to 500"	default(10)
 // @Success     200			{object}	utils.ResponseDataFetchedPhoto
 // @Failure     400			{object}	utils.ResponseMessage
 // @Failure     401			{object}	utils.ResponseMessage
+// @Failure     403			{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /photos	[get]
 func (handler *photoHandler) Fetch(ctx *gin.Context) {
@@ -45,7 +76,18 @@ func (handler *photoHandler) Fetch(ctx *gin.Context) {
 		err    error
 	)
 
-	if err = handler.photoUseCase.Fetch(ctx.Request.Context(), &photos); err != nil {
+	filter, err := photoFilter(ctx)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if err = handler.photoUseCase.Fetch(ctx.Request.Context(), &photos, filter); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
 			Status:  "fail",
 			Message: err.Error(),
@@ -57,7 +99,7 @@ func (handler *photoHandler) Fetch(ctx *gin.Context) {
 	fetchedPhotos := []*utils.FetchedPhoto{}
 
 	for _, photo := range photos {
-		fetchedPhotos = append(fetchedPhotos, &utils.FetchedPhoto{
+		fetchedPhoto := &utils.FetchedPhoto{
 			ID:        photo.ID,
 			Title:     photo.Title,
 			Caption:   photo.Caption,
@@ -66,10 +108,18 @@ func (handler *photoHandler) Fetch(ctx *gin.Context) {
 			CreatedAt: photo.CreatedAt,
 			UpdatedAt: photo.UpdatedAt,
 			User: &utils.User{
-				Email:    photo.User.Email,
-				Username: photo.User.Username,
+				Email:           photo.User.Email,
+				Username:        photo.User.Username,
+				ProfileImageUrl: photo.User.ProfileImageUrl,
 			},
-		})
+		}
+
+		if location, ok := photo.Location(); ok && filter.Near != nil {
+			distance := filter.Near.DistanceKm(location)
+			fetchedPhoto.DistanceKm = &distance
+		}
+
+		fetchedPhotos = append(fetchedPhotos, fetchedPhoto)
 	}
 
 	ctx.JSON(http.StatusOK, helpers.ResponseData{
@@ -78,9 +128,119 @@ func (handler *photoHandler) Fetch(ctx *gin.Context) {
 	})
 }
 
+// photoFilter reads the near and radius query parameters of a listing.
+func photoFilter(ctx *gin.Context) (filter domain.PhotoFilter, err error) {
+	near := ctx.Query("near")
+
+	if near == "" {
+		return filter, nil
+	}
+
+	latitude, longitude, ok := strings.Cut(near, ",")
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+	lng, lngErr := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+
+	if !ok || latErr != nil || lngErr != nil || !(lat >= -90 && lat <= 90) || !(lng >= -180 && lng <= 180) {
+		return filter, errors.New("near must be a latitude and a longitude in degrees, comma separated")
+	}
+
+	filter.Near = &domain.GeoPoint{Latitude: lat, Longitude: lng}
+	filter.RadiusKm = defaultNearRadiusKm
+
+	if value := ctx.Query("radius"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+
+		if err != nil || !(radius > 0 && radius <= maxNearRadiusKm) {
+			return filter, fmt.Errorf("radius must be a number of kilometers up to %d", maxNearRadiusKm)
+		}
+
+		filter.RadiusKm = radius
+	}
+
+	return filter, nil
+}
+
+// GetByID godoc
+// @Summary    	Get a photo
+// @Description	Get a photo by id with its metadata, read from the image once it is uploaded or fetched from its URL. Where the photo was taken is only shown to others when its owner made it public.
+// @Tags        photos
+// @Produce     json
+// @Param       id	path			string	true	"Photo ID"
+// @Success     200	{object}	utils.ResponseDataPhoto
+// @Failure     400	{object}	utils.ResponseMessage
+// @Failure     401	{object}	utils.ResponseMessage
+// @Failure     403	{object}	utils.ResponseMessage
+// @Failure     404	{object}	utils.ResponseMessage
+// @Security    Bearer
+// @Router      /photos/{id}	[get]
+func (handler *photoHandler) GetByID(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	var photo domain.Photo
+
+	photoID := ctx.Param("photoId")
+
+	if err := handler.photoUseCase.GetByID(ctx.Request.Context(), &photo, photoID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: fmt.Sprintf("photo with id %s doesn't exist", photoID),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data:   photoDetails(photo, principal.UserID),
+	})
+}
+
+// photoDetails shows photo to the user with viewerID.
+func photoDetails(photo domain.Photo, viewerID string) utils.Photo {
+	details := utils.Photo{
+		ID:        photo.ID,
+		Title:     photo.Title,
+		Caption:   photo.Caption,
+		PhotoUrl:  photo.PhotoUrl,
+		UserID:    photo.UserID,
+		CreatedAt: photo.CreatedAt,
+		UpdatedAt: photo.UpdatedAt,
+		Metadata: utils.PhotoMetadata{
+			Status:         photo.MetadataStatus,
+			Error:          photo.MetadataError,
+			Width:          photo.Width,
+			Height:         photo.Height,
+			MimeType:       photo.MimeType,
+			FileSize:       photo.FileSize,
+			CameraModel:    photo.CameraModel,
+			TakenAt:        photo.TakenAt,
+			LocationPublic: photo.LocationPublic,
+		},
+	}
+
+	if location, ok := photo.Location(); ok && (photo.LocationPublic || photo.UserID == viewerID) {
+		details.Metadata.Location = &utils.Location{Latitude: location.Latitude, Longitude: location.Longitude}
+	}
+
+	return details
+}
+
 // Store godoc
 // @Summary    	Store a photo
-// @Description	Create and store a photo with authentication user
+// @Description	Create and store a photo with authentication user. Its metadata is read from the image at photo_url shortly after; where it was taken is only shown to others with location_public.
 // @Tags        photos
 // @Accept      json
 // @Produce     json
@@ -88,6 +248,7 @@ func (handler *photoHandler) Fetch(ctx *gin.Context) {
 // @Success     201			{object}  utils.ResponseDataAddedPhoto
 // @Failure     400			{object}	utils.ResponseMessage
 // @Failure     401			{object}	utils.ResponseMessage
+// @Failure     403			{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /photos	[post]
 func (handler *photoHandler) Store(ctx *gin.Context) {
@@ -96,8 +257,13 @@ func (handler *photoHandler) Store(ctx *gin.Context) {
 		err   error
 	)
 
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = ctx.ShouldBindJSON(&photo); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -108,7 +274,13 @@ func (handler *photoHandler) Store(ctx *gin.Context) {
 		return
 	}
 
-	photo.UserID = userID
+	photo = domain.Photo{
+		Title:          photo.Title,
+		Caption:        photo.Caption,
+		PhotoUrl:       photo.PhotoUrl,
+		UserID:         userID,
+		LocationPublic: photo.LocationPublic,
+	}
 
 	if err = handler.photoUseCase.Store(ctx.Request.Context(), &photo); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -143,6 +315,7 @@ func (handler *photoHandler) Store(ctx *gin.Context) {
 // @Success     200		{object}  utils.ResponseDataUpdatedPhoto
 // @Failure     400		{object}	utils.ResponseMessage
 // @Failure     401		{object}	utils.ResponseMessage
+// @Failure     403		{object}	utils.ResponseMessage
 // @Failure     404		{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /photos/{id}		[put]
@@ -201,6 +374,7 @@ func (handler *photoHandler) Update(ctx *gin.Context) {
 // @Success     200	{object}	utils.ResponseMessageDeletedPhoto
 // @Failure     400	{object}	utils.ResponseMessage
 // @Failure     401	{object}	utils.ResponseMessage
+// @Failure     403	{object}	utils.ResponseMessage
 // @Failure     404	{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /photos/{id}	[delete]
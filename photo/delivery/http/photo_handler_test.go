@@ -0,0 +1,471 @@
+package delivery_test
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"api-mygram-go/imagemeta"
+	"api-mygram-go/imagemeta/imagemetatest"
+	photoDelivery "api-mygram-go/photo/delivery/http"
+	photoUseCase "api-mygram-go/photo/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var (
+	latitude, longitude = -6.175392, 106.827153
+	taken               = domain.PhotoMetadata{Width: 640, Height: 480, MimeType: "image/jpeg", Latitude: &latitude, Longitude: &longitude}
+)
+
+var photos = map[string]domain.Photo{
+	"photo-1": {ID: "photo-1", Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: "user-1", PhotoMetadata: taken},
+	"photo-2": {ID: "photo-2", Title: "Sunrise", PhotoUrl: "https://example.com/sunrise.jpg", UserID: "user-2"},
+	"photo-3": {ID: "photo-3", Title: "Home", PhotoUrl: "https://example.com/home.jpg", UserID: "user-2", PhotoMetadata: taken, MetadataStatus: domain.PhotoMetadataReady},
+	"photo-4": {ID: "photo-4", Title: "Monas", PhotoUrl: "https://example.com/monas.jpg", UserID: "user-2", PhotoMetadata: taken, LocationPublic: true},
+}
+
+func getPhoto(ctx context.Context, photo *domain.Photo, id string) error {
+	stored, ok := photos[id]
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*photo = stored
+
+	return nil
+}
+
+func TestPhotoHandler(t *testing.T) {
+	readWrite := []string{domain.ScopePhotosRead, domain.ScopePhotosWrite}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		scopes     []string
+		useCase    fakes.PhotoUseCase
+		wantStatus int
+		wantBody   string
+		notInBody  string
+	}{
+		{
+			name:       "fetch without credentials",
+			method:     http.MethodGet,
+			path:       "/photos",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "fetch without the read scope",
+			method:     http.MethodGet,
+			path:       "/photos",
+			scopes:     []string{domain.ScopePhotosWrite},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "photos:read",
+		},
+		{
+			name:   "fetch",
+			method: http.MethodGet,
+			path:   "/photos",
+			scopes: readWrite,
+			useCase: fakes.PhotoUseCase{FetchFunc: func(ctx context.Context, fetched *[]domain.Photo, filter domain.PhotoFilter) error {
+				photo := photos["photo-1"]
+				photo.User = &domain.User{Username: "johndoe", Email: "johndoe@example.com"}
+				*fetched = []domain.Photo{photo}
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"email":"johndoe@example.com"`,
+		},
+		{
+			name:   "fetch fails",
+			method: http.MethodGet,
+			path:   "/photos",
+			scopes: readWrite,
+			useCase: fakes.PhotoUseCase{FetchFunc: func(context.Context, *[]domain.Photo, domain.PhotoFilter) error {
+				return errors.New("connection refused")
+			}},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "connection refused",
+		},
+		{
+			name:   "fetch near a place",
+			method: http.MethodGet,
+			path:   "/photos?near=-6.194948,106.823036&radius=5",
+			scopes: readWrite,
+			useCase: fakes.PhotoUseCase{FetchFunc: func(ctx context.Context, fetched *[]domain.Photo, filter domain.PhotoFilter) error {
+				if filter.Near == nil || filter.Near.Latitude != -6.194948 || filter.RadiusKm != 5 {
+					return errors.New("the filter wasn't read from the query")
+				}
+
+				photo := photos["photo-4"]
+				photo.User = &domain.User{Username: "janedoe"}
+				*fetched = []domain.Photo{photo}
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"distance_km":2.2`,
+		},
+		{
+			name:       "fetch near nowhere",
+			method:     http.MethodGet,
+			path:       "/photos?near=91,0",
+			scopes:     readWrite,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "near must be a latitude and a longitude",
+		},
+		{
+			name:       "fetch within a too large radius",
+			method:     http.MethodGet,
+			path:       "/photos?near=-6.2,106.8&radius=5000",
+			scopes:     readWrite,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "radius must be a number of kilometers up to 500",
+		},
+		{
+			name:       "get a missing photo",
+			method:     http.MethodGet,
+			path:       "/photos/photo-9",
+			scopes:     readWrite,
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "get someone else's photo with a private location",
+			method:     http.MethodGet,
+			path:       "/photos/photo-3",
+			scopes:     []string{domain.ScopePhotosRead},
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ready","width":640`,
+			notInBody:  `"location":`,
+		},
+		{
+			name:       "get someone else's photo with a public location",
+			method:     http.MethodGet,
+			path:       "/photos/photo-4",
+			scopes:     []string{domain.ScopePhotosRead},
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusOK,
+			wantBody:   `"location":{"latitude":-6.175392,"longitude":106.827153}`,
+		},
+		{
+			name:       "get your own photo",
+			method:     http.MethodGet,
+			path:       "/photos/photo-1",
+			scopes:     []string{domain.ScopePhotosRead},
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusOK,
+			wantBody:   `"location":{"latitude":-6.175392`,
+		},
+		{
+			name:       "upload without an image",
+			method:     http.MethodPost,
+			path:       "/photos/upload",
+			body:       `{"title":"Sunset"}`,
+			scopes:     readWrite,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "send the image as the image field",
+		},
+		{
+			name:       "get a missing image",
+			method:     http.MethodGet,
+			path:       "/images/user-1/abcdefghijkl.jpg",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "store malformed json",
+			method:     http.MethodPost,
+			path:       "/photos",
+			body:       `{"title":`,
+			scopes:     readWrite,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "store",
+			method: http.MethodPost,
+			path:   "/photos",
+			body:   `{"title":"Sunset","photo_url":"https://example.com/sunset.jpg"}`,
+			scopes: readWrite,
+			useCase: fakes.PhotoUseCase{StoreFunc: func(ctx context.Context, photo *domain.Photo) error {
+				photo.ID = "photo-3"
+
+				return nil
+			}},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"user_id":"user-1"`,
+		},
+		{
+			name:       "store without the write scope",
+			method:     http.MethodPost,
+			path:       "/photos",
+			body:       `{"title":"Sunset","photo_url":"https://example.com/sunset.jpg"}`,
+			scopes:     []string{domain.ScopePhotosRead},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "update a missing photo",
+			method:     http.MethodPut,
+			path:       "/photos/photo-9",
+			body:       `{"title":"Dusk"}`,
+			scopes:     readWrite,
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "photo with id photo-9 doesn't exist",
+		},
+		{
+			name:       "update someone else's photo",
+			method:     http.MethodPut,
+			path:       "/photos/photo-2",
+			body:       `{"title":"Dusk"}`,
+			scopes:     readWrite,
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "update",
+			method: http.MethodPut,
+			path:   "/photos/photo-1",
+			body:   `{"title":"Dusk"}`,
+			scopes: readWrite,
+			useCase: fakes.PhotoUseCase{
+				GetByIDFunc: getPhoto,
+				UpdateFunc: func(ctx context.Context, photo domain.Photo, id string) (domain.Photo, error) {
+					updated := photos[id]
+					updated.Title = photo.Title
+
+					return updated, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"title":"Dusk"`,
+		},
+		{
+			name:       "delete",
+			method:     http.MethodDelete,
+			path:       "/photos/photo-1",
+			scopes:     readWrite,
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusOK,
+			wantBody:   "successfully deleted",
+		},
+		{
+			name:       "delete someone else's photo",
+			method:     http.MethodDelete,
+			path:       "/photos/photo-2",
+			scopes:     readWrite,
+			useCase:    fakes.PhotoUseCase{GetByIDFunc: getPhoto},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routers := gin.New()
+			useCase := test.useCase
+
+			photoDelivery.NewPhotoHandler(routers, &useCase, auth.NewAuthenticator(fakes.KeySet(), fakes.AuthenticatingAPIKeys("user-1", test.scopes...), &fakes.SessionUseCase{}))
+
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			if test.scopes != nil {
+				request.Header.Set("X-API-Key", "mygram_test")
+			}
+
+			recorder := httptest.NewRecorder()
+			routers.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+
+			if !strings.Contains(recorder.Body.String(), test.wantBody) {
+				t.Errorf("body %s doesn't contain %s", recorder.Body, test.wantBody)
+			}
+
+			if test.notInBody != "" && strings.Contains(recorder.Body.String(), test.notInBody) {
+				t.Errorf("body %s contains %s", recorder.Body, test.notInBody)
+			}
+		})
+	}
+}
+
+func TestPhotoHandlerImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    []string
+		wantStored  int
+	}{
+		{
+			name:        "csv",
+			path:        "/photos/import",
+			contentType: "text/csv",
+			body:        "photo_url,title,caption\nhttps://example.com/a.jpg,Sunrise,Early\n,Missing url,\nhttps://example.com/b.jpg,Sunset,\nhttps://example.com/c.jpg\n",
+			wantStatus:  http.StatusCreated,
+			wantBody:    []string{`"succeeded":2`, `"failed":2`, `"line":3,"status":"failed"`, `wrong number of fields`, `"status":"imported"`},
+			wantStored:  2,
+		},
+		{
+			name:        "json lines dry run",
+			path:        "/photos/import?dry_run=true",
+			contentType: "application/x-ndjson",
+			body:        "{\"title\":\"Sunrise\",\"photo_url\":\"https://example.com/a.jpg\"}\n\nnot json\n",
+			wantStatus:  http.StatusOK,
+			wantBody:    []string{`"dry_run":true`, `"line":1,"status":"valid"`, `"line":3,"status":"failed"`},
+		},
+		{
+			name:        "json lines with a title too long",
+			path:        "/photos/import",
+			contentType: "application/x-ndjson",
+			body:        "{\"title\":\"" + strings.Repeat("é", 51) + "\",\"photo_url\":\"https://example.com/a.jpg\"}\n{\"title\":\"" + strings.Repeat("é", 50) + "\",\"photo_url\":\"https://example.com/b.jpg\"}\n",
+			wantStatus:  http.StatusCreated,
+			wantBody:    []string{`"succeeded":1`, `"failed":1`, `"line":1,"status":"failed"`, `maxstringlength(50)`},
+			wantStored:  1,
+		},
+		{
+			name:       "format from the query",
+			path:       "/photos/import?format=jsonl",
+			body:       `{"title":"Sunrise","photo_url":"https://example.com/a.jpg"}`,
+			wantStatus: http.StatusCreated,
+			wantStored: 1,
+		},
+		{
+			name:        "csv with an unknown column",
+			path:        "/photos/import",
+			contentType: "text/csv",
+			body:        "title,photo_url,tags\n",
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    []string{`unknown column \"tags\"`},
+		},
+		{
+			name:        "unsupported format",
+			path:        "/photos/import",
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routers := gin.New()
+			repository := fakes.NewPhotoRepository(nil)
+			useCase := photoUseCase.NewPhotoUseCase(repository, fakes.NewBlobStore(), fakes.ImageFetcher{}, fakes.TxManager{}, func() {})
+
+			photoDelivery.NewPhotoHandler(routers, useCase, auth.NewAuthenticator(fakes.KeySet(), fakes.AuthenticatingAPIKeys("user-1", domain.ScopePhotosRead, domain.ScopePhotosWrite), &fakes.SessionUseCase{}))
+
+			request := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			request.Header.Set("X-API-Key", "mygram_test")
+
+			recorder := httptest.NewRecorder()
+			routers.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+
+			for _, want := range test.wantBody {
+				if !strings.Contains(recorder.Body.String(), want) {
+					t.Errorf("body %s doesn't contain %s", recorder.Body, want)
+				}
+			}
+
+			var stored []domain.Photo
+
+			if err := repository.Fetch(context.Background(), &stored, domain.PhotoFilter{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(stored) != test.wantStored {
+				t.Errorf("stored %d photos, want %d", len(stored), test.wantStored)
+			}
+
+			for _, photo := range stored {
+				if photo.UserID != "user-1" {
+					t.Errorf("photo %s belongs to %q, want the importing user", photo.ID, photo.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestPhotoHandlerUpload(t *testing.T) {
+	routers := gin.New()
+	repository := fakes.NewPhotoRepository(nil)
+	useCase := photoUseCase.NewPhotoUseCase(repository, fakes.NewBlobStore(), fakes.ImageFetcher{}, fakes.TxManager{}, func() {})
+
+	photoDelivery.NewPhotoHandler(routers, useCase, auth.NewAuthenticator(fakes.KeySet(), fakes.AuthenticatingAPIKeys("user-1", domain.ScopePhotosRead, domain.ScopePhotosWrite), &fakes.SessionUseCase{}))
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+	form.WriteField("title", "Monas")
+	part, _ := form.CreateFormFile("image", "monas.jpg")
+	part.Write(imagemetatest.JPEG(t, 80, 60, imagemetatest.EXIF{Model: "Pixel 8", GPS: true, Latitude: -6.175392, Longitude: 106.827153}))
+	form.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/photos/upload", &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	request.Header.Set("X-API-Key", "mygram_test")
+
+	recorder := httptest.NewRecorder()
+	routers.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", recorder.Code, recorder.Body)
+	}
+
+	var response struct {
+		Data struct {
+			PhotoUrl string `json:"photo_url"`
+			Metadata struct {
+				Width       int    `json:"width"`
+				CameraModel string `json:"camera_model"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Data.Metadata.Width != 80 || response.Data.Metadata.CameraModel != "Pixel 8" {
+		t.Errorf("metadata = %+v, want it read from the image", response.Data.Metadata)
+	}
+
+	recorder = httptest.NewRecorder()
+	routers.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, response.Data.PhotoUrl, nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("getting %s: status = %d, content type %q", response.Data.PhotoUrl, recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	metadata, err := imagemeta.Read(recorder.Body.Bytes())
+
+	if err != nil || metadata.Latitude != nil {
+		t.Errorf("served an image with %+v, %v, want it without a location", metadata, err)
+	}
+}
@@ -0,0 +1,158 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxPhotoBytes = 20 << 20
+
+// Upload godoc
+// @Summary			Upload a photo
+// @Description	Store a photo of a JPEG, PNG, GIF or WebP image of up to 20 MB with authentication user. Its dimensions, type, size and, from EXIF, camera, time and place are read right away. Unless keep_location is set, location data is stripped from the stored image and where it was taken is only shown to its owner.
+// @Tags				photos
+// @Accept			multipart/form-data
+// @Produce			json
+// @Param				image					formData	file		true	"Image"
+// @Param				title					formData	string	true	"Title"
+// @Param				caption				formData	string	false	"Caption"
+// @Param				keep_location	formData	bool		false	"Keep where the photo was taken in the image and share it"
+// @Success			201		{object}	utils.ResponseDataPhoto
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Failure			413		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/photos/upload	[post]
+func (handler *photoHandler) Upload(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxPhotoBytes)
+
+	header, err := ctx.FormFile("image")
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+
+		if errors.As(err, &tooLarge) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "the photo can't be larger than 20 MB",
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: "send the image as the image field of a multipart form",
+		})
+
+		return
+	}
+
+	keepLocation := false
+
+	if value := ctx.PostForm("keep_location"); value != "" {
+		if keepLocation, err = strconv.ParseBool(value); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "keep_location must be true or false",
+			})
+
+			return
+		}
+	}
+
+	file, err := header.Open()
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	defer file.Close()
+
+	upload := domain.PhotoUpload{
+		Photo: domain.Photo{
+			Title:   ctx.PostForm("title"),
+			Caption: ctx.PostForm("caption"),
+			UserID:  principal.UserID,
+		},
+		Image:        file,
+		KeepLocation: keepLocation,
+	}
+
+	if err = handler.photoUseCase.Upload(ctx.Request.Context(), &upload); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ResponseData{
+		Status: "success",
+		Data:   photoDetails(upload.Photo, principal.UserID),
+	})
+}
+
+// GetImage godoc
+// @Summary			Get an uploaded photo
+// @Description	Download the image of an uploaded photo from its photo_url. Files never change, so they can be cached for good.
+// @Tags				photos
+// @Produce			jpeg
+// @Produce			png
+// @Produce			gif
+// @Produce			image/webp
+// @Param				userId	path			string	true	"User ID"
+// @Param				file		path			string	true	"Image file"
+// @Success			200		{file}		binary
+// @Failure			404		{object}	utils.ResponseMessage
+// @Router			/images/{userId}/{file}	[get]
+func (handler *photoHandler) GetImage(ctx *gin.Context) {
+	file, err := handler.photoUseCase.Open(ctx.Request.Context(), ctx.Param("userId"), ctx.Param("file"))
+
+	if err != nil {
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "image not found",
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(ctx.Param("file")))
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
@@ -0,0 +1,174 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"api-mygram-go/config/database/databasetest"
+	"api-mygram-go/domain"
+	photoRepository "api-mygram-go/photo/repository/postgres"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	databasetest.Main(m)
+}
+
+func TestPhotoRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := photoRepository.NewPhotoRepository(db)
+	user := domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20, ProfileImageUrl: "/avatars/user-1/abcdefghijkl-256.jpg"}
+
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	photo := domain.Photo{Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: user.ID}
+
+	if err := repository.Store(ctx, &photo); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Store(ctx, &domain.Photo{UserID: user.ID}); err == nil {
+		t.Error("a photo without a title and url was stored")
+	}
+
+	var photos []domain.Photo
+
+	if err := repository.Fetch(ctx, &photos, domain.PhotoFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(photos) != 1 || photos[0].User == nil || photos[0].User.Email != user.Email || photos[0].User.ProfileImageUrl != user.ProfileImageUrl {
+		t.Fatalf("fetched %+v, want the photo with its user and avatar preloaded", photos)
+	}
+
+	if photos[0].User.Password != "" {
+		t.Error("the preloaded user includes the password hash")
+	}
+
+	updated, err := repository.Update(ctx, domain.Photo{Title: "Dusk"}, photo.ID)
+
+	if err != nil || updated.Title != "Dusk" || updated.PhotoUrl != photo.PhotoUrl {
+		t.Errorf("update returned %+v, %v", updated, err)
+	}
+
+	if err = repository.Delete(ctx, photo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repository.Delete(ctx, photo.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleting twice returned %v", err)
+	}
+}
+
+func TestPhotoRepositoryStoreBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := photoRepository.NewPhotoRepository(db)
+	user := domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}
+
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	photos := []domain.Photo{
+		{Title: "Sunrise", PhotoUrl: "https://example.com/sunrise.jpg", UserID: user.ID},
+		{Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: user.ID},
+	}
+
+	if err := repository.StoreBatch(ctx, photos); err != nil {
+		t.Fatal(err)
+	}
+
+	if photos[0].ID == "" || photos[0].ID == photos[1].ID {
+		t.Errorf("ids = %q and %q, want distinct ids", photos[0].ID, photos[1].ID)
+	}
+
+	invalid := []domain.Photo{{Title: "Dusk", PhotoUrl: "https://example.com/dusk.jpg", UserID: user.ID}, {UserID: user.ID}}
+
+	if err := repository.StoreBatch(ctx, invalid); err == nil {
+		t.Error("a batch with an invalid photo was stored")
+	}
+
+	var count int64
+
+	if err := db.Model(&domain.Photo{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("%d photos stored, %v, want only the valid batch", count, err)
+	}
+}
+
+func TestPhotoRepositoryMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := photoRepository.NewPhotoRepository(db)
+	user := domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}
+
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	monas := domain.Photo{Title: "Monas", PhotoUrl: "https://example.com/monas.jpg", UserID: user.ID, LocationPublic: true}
+	hidden := domain.Photo{Title: "Home", PhotoUrl: "https://example.com/home.jpg", UserID: user.ID}
+
+	for _, photo := range []*domain.Photo{&monas, &hidden} {
+		if err := repository.Store(ctx, photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var pending []domain.Photo
+
+	if err := repository.FetchMetadataPending(ctx, &pending, 10); err != nil || len(pending) != 2 {
+		t.Fatalf("pending photos = %d, %v, want both new photos", len(pending), err)
+	}
+
+	lat, lng := -6.175392, 106.827153
+
+	for _, photo := range []domain.Photo{monas, hidden} {
+		photo.MetadataStatus = domain.PhotoMetadataReady
+		photo.PhotoMetadata = domain.PhotoMetadata{Width: 640, Height: 480, MimeType: "image/jpeg", Latitude: &lat, Longitude: &lng}
+
+		if err := repository.UpdateMetadata(ctx, photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stale := hidden
+	stale.PhotoUrl = "https://example.com/old.jpg"
+
+	if err := repository.UpdateMetadata(ctx, stale); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("updating the metadata of a former url returned %v", err)
+	}
+
+	var stored domain.Photo
+
+	if err := repository.GetByID(ctx, &stored, monas.ID); err != nil || stored.Width != 640 || stored.MetadataStatus != domain.PhotoMetadataReady || stored.Latitude == nil {
+		t.Errorf("stored %+v, %v, want the metadata read", stored, err)
+	}
+
+	var near []domain.Photo
+
+	// The national monument is about 2 km from the Bundaran HI roundabout.
+	if err := repository.Fetch(ctx, &near, domain.PhotoFilter{Near: &domain.GeoPoint{Latitude: -6.194948, Longitude: 106.823036}, RadiusKm: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(near) != 1 || near[0].ID != monas.ID {
+		t.Errorf("photos near = %+v, want only the one with a public location", near)
+	}
+
+	if err := repository.Fetch(ctx, &near, domain.PhotoFilter{Near: &domain.GeoPoint{Latitude: -6.194948, Longitude: 106.823036}, RadiusKm: 1}); err != nil || len(near) != 0 {
+		t.Errorf("photos within 1 km = %d, %v, want none", len(near), err)
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"api-mygram-go/config/database"
 	"api-mygram-go/domain"
 	"time"
 
@@ -18,17 +19,35 @@ func NewPhotoRepository(db *gorm.DB) *photoRepository {
 	return &photoRepository{db}
 }
 
-func (photoRepository *photoRepository) Fetch(ctx context.Context, photos *[]domain.Photo) (err error) {
+// Fetch lists the photos matching filter. Photos near a place are first
+// narrowed down to a bounding box in the database, then to the circle.
+func (photoRepository *photoRepository) Fetch(ctx context.Context, photos *[]domain.Photo, filter domain.PhotoFilter) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 
 	defer cancel()
 
-	if err = photoRepository.db.WithContext(ctx).Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id", "username", "email")
-	}).Find(&photos).Error; err != nil {
+	query := database.FromContext(ctx, photoRepository.db).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "email", "profile_image_url")
+	})
+
+	if filter.Near != nil {
+		minLat, maxLat, minLng, maxLng, wraps := filter.Near.BoundingBox(filter.RadiusKm)
+
+		query = query.Where("location_public = ? AND latitude BETWEEN ? AND ? AND longitude IS NOT NULL", true, minLat, maxLat)
+
+		if !wraps {
+			query = query.Where("longitude BETWEEN ? AND ?", minLng, maxLng)
+		}
+	}
+
+	if err = query.Find(&photos).Error; err != nil {
 		return err
 	}
 
+	if filter.Near != nil {
+		*photos = domain.PhotosNear(*photos, *filter.Near, filter.RadiusKm)
+	}
+
 	return
 }
 
@@ -41,7 +60,26 @@ func (photoRepository *photoRepository) Store(ctx context.Context, photo *domain
 
 	photo.ID = fmt.Sprintf("photo-%s", ID)
 
-	if err := photoRepository.db.WithContext(ctx).Create(&photo).Error; err != nil {
+	if err := database.FromContext(ctx, photoRepository.db).Create(&photo).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// StoreBatch stores photos with a single insert, assigning their ids.
+func (photoRepository *photoRepository) StoreBatch(ctx context.Context, photos []domain.Photo) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+	defer cancel()
+
+	for i := range photos {
+		ID, _ := gonanoid.New(16)
+
+		photos[i].ID = fmt.Sprintf("photo-%s", ID)
+	}
+
+	if err = database.FromContext(ctx, photoRepository.db).Create(&photos).Error; err != nil {
 		return err
 	}
 
@@ -53,7 +91,7 @@ func (photoRepository *photoRepository) GetByID(ctx context.Context, photo *doma
 
 	defer cancel()
 
-	if err = photoRepository.db.WithContext(ctx).First(&photo, &id).Error; err != nil {
+	if err = database.FromContext(ctx, photoRepository.db).First(&photo, &id).Error; err != nil {
 		return err
 	}
 
@@ -67,11 +105,11 @@ func (photoRepository *photoRepository) Update(ctx context.Context, photo domain
 
 	p = domain.Photo{}
 
-	if err = photoRepository.db.WithContext(ctx).First(&p, &id).Error; err != nil {
+	if err = database.FromContext(ctx, photoRepository.db).First(&p, &id).Error; err != nil {
 		return p, err
 	}
 
-	if err = photoRepository.db.WithContext(ctx).Model(&p).Updates(photo).Error; err != nil {
+	if err = database.FromContext(ctx, photoRepository.db).Model(&p).Updates(photo).Error; err != nil {
 		return p, err
 	}
 
@@ -83,13 +121,65 @@ func (photoRepository *photoRepository) Delete(ctx context.Context, id string) (
 
 	defer cancel()
 
-	if err = photoRepository.db.WithContext(ctx).First(&domain.Photo{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, photoRepository.db).First(&domain.Photo{}, &id).Error; err != nil {
+		return err
+	}
+
+	if err = database.FromContext(ctx, photoRepository.db).Delete(&domain.Photo{}, &id).Error; err != nil {
 		return err
 	}
 
-	if err = photoRepository.db.WithContext(ctx).Delete(&domain.Photo{}, &id).Error; err != nil {
+	return
+}
+
+// FetchMetadataPending lists up to limit photos whose metadata wasn't read
+// yet, oldest first.
+func (photoRepository *photoRepository) FetchMetadataPending(ctx context.Context, photos *[]domain.Photo, limit int) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, photoRepository.db).
+		Where("metadata_status = ?", domain.PhotoMetadataPending).
+		Order("created_at").
+		Limit(limit).
+		Find(&photos).Error; err != nil {
 		return err
 	}
 
 	return
 }
+
+// UpdateMetadata stores the metadata of photo, unless its URL changed since
+// it was read.
+func (photoRepository *photoRepository) UpdateMetadata(ctx context.Context, photo domain.Photo) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, photoRepository.db).
+		Model(&domain.Photo{}).
+		Where("id = ? AND photo_url = ?", photo.ID, photo.PhotoUrl).
+		UpdateColumns(map[string]any{
+			"width":           photo.Width,
+			"height":          photo.Height,
+			"mime_type":       photo.MimeType,
+			"file_size":       photo.FileSize,
+			"camera_model":    photo.CameraModel,
+			"taken_at":        photo.TakenAt,
+			"latitude":        photo.Latitude,
+			"longitude":       photo.Longitude,
+			"metadata_status": photo.MetadataStatus,
+			"metadata_error":  photo.MetadataError,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
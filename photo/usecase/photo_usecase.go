@@ -1,34 +1,223 @@
 package usecase
 
 import (
-	"context"
 	"api-mygram-go/domain"
+	"api-mygram-go/imagemeta"
+	"api-mygram-go/metrics"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	// importBatchSize is how many photos each insert of an import stores.
+	importBatchSize = 100
+
+	// metadataBatchSize bounds how many photos one ProcessMetadata call
+	// fetches.
+	metadataBatchSize = 20
+)
+
+var (
+	photoFilePattern = regexp.MustCompile(`^[a-z0-9]{12}\.(jpg|png|gif|webp)$`)
+	userIDPattern    = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
 )
 
 type photoUseCase struct {
 	photoRepository domain.PhotoRepository
+	blobStore       domain.BlobStore
+	imageFetcher    domain.ImageFetcher
+	txManager       domain.TxManager
+	notify          func()
 }
 
-func NewPhotoUseCase(photoRepository domain.PhotoRepository) *photoUseCase {
-	return &photoUseCase{photoRepository}
+// NewPhotoUseCase fetches the images of photos given by URL with
+// imageFetcher. notify is called whenever a photo waits for its metadata,
+// to wake whatever calls ProcessMetadata.
+func NewPhotoUseCase(photoRepository domain.PhotoRepository, blobStore domain.BlobStore, imageFetcher domain.ImageFetcher, txManager domain.TxManager, notify func()) *photoUseCase {
+	return &photoUseCase{photoRepository, blobStore, imageFetcher, txManager, notify}
 }
 
-func (photoUseCase *photoUseCase) Fetch(ctx context.Context, photos *[]domain.Photo) (err error) {
-	if err = photoUseCase.photoRepository.Fetch(ctx, photos); err != nil {
+func (photoUseCase *photoUseCase) Fetch(ctx context.Context, photos *[]domain.Photo, filter domain.PhotoFilter) (err error) {
+	if err = photoUseCase.photoRepository.Fetch(ctx, photos, filter); err != nil {
 		return err
 	}
 
 	return
 }
 
+// Store stores photo, whose metadata is read later from its URL.
 func (photoUseCase *photoUseCase) Store(ctx context.Context, photo *domain.Photo) (err error) {
+	resetMetadata(photo)
+
 	if err = photoUseCase.photoRepository.Store(ctx, photo); err != nil {
 		return err
 	}
 
+	metrics.PhotosStored.Inc()
+	photoUseCase.notify()
+
 	return
 }
 
+// Upload stores the uploaded image and a photo of it, along with its
+// metadata. Unless upload.KeepLocation is set, location data is stripped
+// from the stored image and the location of the photo is kept private.
+func (photoUseCase *photoUseCase) Upload(ctx context.Context, upload *domain.PhotoUpload) (err error) {
+	data, err := io.ReadAll(upload.Image)
+
+	if err != nil {
+		return err
+	}
+
+	metadata, err := imagemeta.Read(data)
+
+	if err != nil {
+		return err
+	}
+
+	if !upload.KeepLocation {
+		if data, err = imagemeta.StripLocation(data); err != nil {
+			return err
+		}
+
+		metadata.FileSize = int64(len(data))
+	}
+
+	version, err := gonanoid.Generate("abcdefghijklmnopqrstuvwxyz0123456789", 12)
+
+	if err != nil {
+		return err
+	}
+
+	photo := &upload.Photo
+	file := version + "." + extension(metadata.MimeType)
+
+	photo.PhotoUrl = domain.PhotoPath(photo.UserID, file)
+	photo.PhotoMetadata = metadata
+	photo.MetadataStatus = domain.PhotoMetadataReady
+	photo.MetadataError = ""
+	photo.LocationPublic = upload.KeepLocation
+
+	if err = photo.Validate(); err != nil {
+		return err
+	}
+
+	key := domain.PhotoKey(photo.UserID, file)
+
+	if err = photoUseCase.blobStore.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	if err = photoUseCase.photoRepository.Store(ctx, photo); err != nil {
+		_ = photoUseCase.blobStore.Delete(ctx, key)
+
+		return err
+	}
+
+	metrics.PhotosStored.Inc()
+
+	return nil
+}
+
+// Open returns the uploaded photo file of the user with userID, named as in
+// domain.PhotoPath.
+func (photoUseCase *photoUseCase) Open(ctx context.Context, userID string, file string) (io.ReadCloser, error) {
+	if !photoFilePattern.MatchString(file) || !userIDPattern.MatchString(userID) {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return photoUseCase.blobStore.Open(ctx, domain.PhotoKey(userID, file))
+}
+
+// ProcessMetadata reads the metadata of the photos waiting for it and
+// reports how many it processed. A photo whose image can't be fetched or
+// read, or whose metadata can't be stored, is marked failed with the reason,
+// so the next photos don't wait behind it.
+func (photoUseCase *photoUseCase) ProcessMetadata(ctx context.Context) (processed int, err error) {
+	photos := []domain.Photo{}
+
+	if err = photoUseCase.photoRepository.FetchMetadataPending(ctx, &photos, metadataBatchSize); err != nil {
+		return 0, err
+	}
+
+	var errs []error
+
+	for _, photo := range photos {
+		metadata, failure := photoUseCase.readMetadata(ctx, photo.PhotoUrl)
+
+		if failure == nil {
+			photo.PhotoMetadata = metadata
+			photo.MetadataStatus = domain.PhotoMetadataReady
+			photo.MetadataError = ""
+
+			// The photo was deleted or given another URL meanwhile.
+			if failure = photoUseCase.photoRepository.UpdateMetadata(ctx, photo); errors.Is(failure, gorm.ErrRecordNotFound) {
+				continue
+			} else if failure == nil {
+				processed++
+
+				continue
+			}
+		}
+
+		photo.PhotoMetadata = domain.PhotoMetadata{}
+		photo.MetadataStatus = domain.PhotoMetadataFailed
+		photo.MetadataError = imagemeta.Clean(failure.Error())
+
+		// The photo was deleted or given another URL meanwhile.
+		if err := photoUseCase.photoRepository.UpdateMetadata(ctx, photo); errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		processed++
+	}
+
+	return processed, errors.Join(errs...)
+}
+
+// readMetadata reads the metadata of the image at photoURL, from the blob
+// store when it was uploaded.
+func (photoUseCase *photoUseCase) readMetadata(ctx context.Context, photoURL string) (domain.PhotoMetadata, error) {
+	if path, ok := strings.CutPrefix(photoURL, domain.PhotoPathPrefix); ok {
+		userID, file, _ := strings.Cut(path, "/")
+		r, err := photoUseCase.Open(ctx, userID, file)
+
+		if err != nil {
+			return domain.PhotoMetadata{}, err
+		}
+
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+
+		if err != nil {
+			return domain.PhotoMetadata{}, err
+		}
+
+		return imagemeta.Read(data)
+	}
+
+	data, err := photoUseCase.imageFetcher.Fetch(ctx, photoURL)
+
+	if err != nil {
+		return domain.PhotoMetadata{}, err
+	}
+
+	return imagemeta.Read(data)
+}
+
 func (photoUseCase *photoUseCase) GetByID(ctx context.Context, photo *domain.Photo, id string) (err error) {
 	if err = photoUseCase.photoRepository.GetByID(ctx, photo, id); err != nil {
 		return err
@@ -37,18 +226,152 @@ func (photoUseCase *photoUseCase) GetByID(ctx context.Context, photo *domain.Pho
 	return
 }
 
+// Update updates the photo with id. When its URL changes, its metadata is
+// read again, and the file it was uploaded with is removed.
 func (photoUseCase *photoUseCase) Update(ctx context.Context, photo domain.Photo, id string) (p domain.Photo, err error) {
-	if p, err = photoUseCase.photoRepository.Update(ctx, photo, id); err != nil {
+	var previous domain.Photo
+
+	err = photoUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := photoUseCase.photoRepository.GetByID(ctx, &previous, id); err != nil {
+			return err
+		}
+
+		var err error
+
+		if p, err = photoUseCase.photoRepository.Update(ctx, photo, id); err != nil {
+			return err
+		}
+
+		if p.PhotoUrl == previous.PhotoUrl {
+			return nil
+		}
+
+		resetMetadata(&p)
+
+		return photoUseCase.photoRepository.UpdateMetadata(ctx, p)
+	})
+
+	if err != nil {
 		return p, err
 	}
 
+	if p.PhotoUrl != previous.PhotoUrl {
+		photoUseCase.notify()
+		photoUseCase.removeFile(ctx, previous)
+	}
+
 	return p, nil
 }
 
+// Delete deletes the photo with id and the file it was uploaded with.
 func (photoUseCase *photoUseCase) Delete(ctx context.Context, id string) (err error) {
+	var photo domain.Photo
+
+	if err = photoUseCase.photoRepository.GetByID(ctx, &photo, id); err != nil {
+		return err
+	}
+
 	if err = photoUseCase.photoRepository.Delete(ctx, id); err != nil {
 		return err
 	}
 
+	photoUseCase.removeFile(ctx, photo)
+
 	return
 }
+
+// removeFile deletes the file photo was uploaded with, if any. Only files of
+// the owner of photo are deleted, whatever its URL points at. Files left
+// behind by a failure here are removed with the account.
+func (photoUseCase *photoUseCase) removeFile(ctx context.Context, photo domain.Photo) {
+	file, ok := strings.CutPrefix(photo.PhotoUrl, domain.PhotoPath(photo.UserID, ""))
+
+	if !ok || !photoFilePattern.MatchString(file) {
+		return
+	}
+
+	_ = photoUseCase.blobStore.Delete(ctx, domain.PhotoKey(photo.UserID, file))
+}
+
+// resetMetadata marks the metadata of photo to be read from its URL.
+func resetMetadata(photo *domain.Photo) {
+	photo.PhotoMetadata = domain.PhotoMetadata{}
+	photo.MetadataStatus = domain.PhotoMetadataPending
+	photo.MetadataError = ""
+}
+
+// extension returns the file name extension of images of mimeType.
+func extension(mimeType string) string {
+	format := strings.TrimPrefix(mimeType, "image/")
+
+	if format == "jpeg" {
+		return "jpg"
+	}
+
+	return format
+}
+
+// Import stores the valid rows as photos of userID, in batches inside one
+// transaction, and reports on every row. Rows that can't be read or fail
+// validation are skipped. Nothing is stored in a dry run, or when storing
+// any batch fails.
+func (photoUseCase *photoUseCase) Import(ctx context.Context, userID string, rows []domain.PhotoImportRow, dryRun bool) (report domain.PhotoImportReport, err error) {
+	report = domain.PhotoImportReport{DryRun: dryRun, Results: []domain.PhotoImportResult{}}
+
+	var (
+		photos  []domain.Photo
+		results []int
+	)
+
+	for _, row := range rows {
+		result := domain.PhotoImportResult{Line: row.Line, Title: row.Photo.Title}
+		err := row.Err
+
+		if err == nil {
+			row.Photo.ID = ""
+			row.Photo.UserID = userID
+			resetMetadata(&row.Photo)
+			err = row.Photo.Validate()
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+			report.Failed++
+		} else {
+			photos = append(photos, row.Photo)
+			results = append(results, len(report.Results))
+			report.Succeeded++
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	if dryRun || len(photos) == 0 {
+		return report, nil
+	}
+
+	err = photoUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(photos); start += importBatchSize {
+			batch := photos[start:min(start+importBatchSize, len(photos))]
+
+			if err := photoUseCase.photoRepository.StoreBatch(ctx, batch); err != nil {
+				return fmt.Errorf("importing the rows from line %d: %w", report.Results[results[start]].Line, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return report, err
+	}
+
+	for i, result := range results {
+		report.Results[result].PhotoID = photos[i].ID
+	}
+
+	metrics.PhotosStored.Add(float64(len(photos)))
+	photoUseCase.notify()
+
+	return report, nil
+}
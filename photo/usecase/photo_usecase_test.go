@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"api-mygram-go/imagemeta"
+	"api-mygram-go/imagemeta/imagemetatest"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var takenInJakarta = imagemetatest.EXIF{
+	Model:     "Pixel 8",
+	TakenAt:   "2024:05:17 18:04:05",
+	GPS:       true,
+	Latitude:  -6.175392,
+	Longitude: 106.827153,
+}
+
+func TestUploadStripsTheLocationUnlessKept(t *testing.T) {
+	for _, keepLocation := range []bool{false, true} {
+		ctx := context.Background()
+		photos := fakes.NewPhotoRepository(nil)
+		blobs := fakes.NewBlobStore()
+		useCase := NewPhotoUseCase(photos, blobs, fakes.ImageFetcher{}, fakes.TxManager{}, func() {})
+
+		upload := domain.PhotoUpload{
+			Photo:        domain.Photo{Title: "Monas", UserID: "user-1"},
+			Image:        bytes.NewReader(imagemetatest.JPEG(t, 80, 60, takenInJakarta)),
+			KeepLocation: keepLocation,
+		}
+
+		if err := useCase.Upload(ctx, &upload); err != nil {
+			t.Fatal(err)
+		}
+
+		var stored domain.Photo
+
+		if err := photos.GetByID(ctx, &stored, upload.Photo.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if stored.MetadataStatus != domain.PhotoMetadataReady || stored.CameraModel != "Pixel 8" || stored.Latitude == nil {
+			t.Errorf("stored %+v, want the metadata read with the location", stored)
+		}
+
+		if stored.LocationPublic != keepLocation {
+			t.Errorf("location public = %t, want %t", stored.LocationPublic, keepLocation)
+		}
+
+		userID, file, _ := strings.Cut(strings.TrimPrefix(stored.PhotoUrl, domain.PhotoPathPrefix), "/")
+		r, err := useCase.Open(ctx, userID, file)
+
+		if err != nil {
+			t.Fatalf("opening %s: %v", stored.PhotoUrl, err)
+		}
+
+		data, _ := io.ReadAll(r)
+		served, err := imagemeta.Read(data)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if (served.Latitude != nil) != keepLocation {
+			t.Errorf("the served image has a location: %t, want %t", served.Latitude != nil, keepLocation)
+		}
+
+		if err = useCase.Delete(ctx, stored.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if keys, _ := blobs.List(ctx, "users/user-1/photos/"); len(keys) != 0 {
+			t.Errorf("files %v are left after deleting the photo", keys)
+		}
+	}
+}
+
+func TestProcessMetadata(t *testing.T) {
+	ctx := context.Background()
+	photos := fakes.NewPhotoRepository(nil)
+	fetcher := fakes.ImageFetcher{Images: map[string][]byte{
+		"https://example.com/monas.jpg": imagemetatest.JPEG(t, 80, 60, takenInJakarta),
+	}}
+	notified := 0
+	useCase := NewPhotoUseCase(photos, fakes.NewBlobStore(), fetcher, fakes.TxManager{}, func() { notified++ })
+
+	monas := domain.Photo{Title: "Monas", PhotoUrl: "https://example.com/monas.jpg", UserID: "user-1"}
+	missing := domain.Photo{Title: "Missing", PhotoUrl: "https://example.com/missing.jpg", UserID: "user-1"}
+
+	for _, photo := range []*domain.Photo{&monas, &missing} {
+		if err := useCase.Store(ctx, photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if notified != 2 {
+		t.Errorf("notified %d times, want once per stored photo", notified)
+	}
+
+	if processed, err := useCase.ProcessMetadata(ctx); err != nil || processed != 2 {
+		t.Fatalf("processed %d, %v, want both photos", processed, err)
+	}
+
+	if err := photos.GetByID(ctx, &monas, monas.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if monas.MetadataStatus != domain.PhotoMetadataReady || monas.Width != 80 || monas.Latitude == nil || monas.LocationPublic {
+		t.Errorf("photo %+v, want its metadata read and its location private", monas)
+	}
+
+	if err := photos.GetByID(ctx, &missing, missing.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if missing.MetadataStatus != domain.PhotoMetadataFailed || !strings.Contains(missing.MetadataError, "404") {
+		t.Errorf("photo %+v, want it failed with the reason", missing)
+	}
+
+	if _, err := useCase.Update(ctx, domain.Photo{PhotoUrl: "https://example.com/monas.jpg"}, missing.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := photos.GetByID(ctx, &missing, missing.ID); err != nil || missing.MetadataStatus != domain.PhotoMetadataPending || missing.MetadataError != "" {
+		t.Errorf("photo %+v, %v, want its metadata read again for the new url", missing, err)
+	}
+}
+
+// rejectingMetadata refuses to store the metadata read for one photo, as the
+// database would metadata that doesn't fit its columns.
+type rejectingMetadata struct {
+	*fakes.PhotoRepository
+	photoID string
+}
+
+func (repository rejectingMetadata) UpdateMetadata(ctx context.Context, photo domain.Photo) error {
+	if photo.ID == repository.photoID && photo.MetadataStatus == domain.PhotoMetadataReady {
+		return errors.New("value too long for type character varying(100)")
+	}
+
+	return repository.PhotoRepository.UpdateMetadata(ctx, photo)
+}
+
+func TestProcessMetadataGoesOnPastAPhotoItCantStore(t *testing.T) {
+	ctx := context.Background()
+	photos := fakes.NewPhotoRepository(nil)
+	fetcher := fakes.ImageFetcher{Images: map[string][]byte{
+		"https://example.com/monas.jpg": imagemetatest.JPEG(t, 80, 60, takenInJakarta),
+	}}
+	rejected := domain.Photo{Title: "Rejected", PhotoUrl: "https://example.com/monas.jpg", UserID: "user-1"}
+	monas := domain.Photo{Title: "Monas", PhotoUrl: "https://example.com/monas.jpg", UserID: "user-1"}
+
+	for _, photo := range []*domain.Photo{&rejected, &monas} {
+		if err := photos.Store(ctx, photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	useCase := NewPhotoUseCase(rejectingMetadata{photos, rejected.ID}, fakes.NewBlobStore(), fetcher, fakes.TxManager{}, func() {})
+
+	if processed, err := useCase.ProcessMetadata(ctx); err != nil || processed != 2 {
+		t.Fatalf("processed %d, %v, want both photos", processed, err)
+	}
+
+	if err := photos.GetByID(ctx, &rejected, rejected.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if rejected.MetadataStatus != domain.PhotoMetadataFailed || !strings.Contains(rejected.MetadataError, "too long") {
+		t.Errorf("photo %+v, want it failed with the reason", rejected)
+	}
+
+	if err := photos.GetByID(ctx, &monas, monas.ID); err != nil || monas.MetadataStatus != domain.PhotoMetadataReady {
+		t.Errorf("photo %+v, %v, want its metadata read", monas, err)
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/tracing"
+	"context"
+	"io"
+)
+
+type tracedPhotoUseCase struct {
+	next domain.PhotoUseCase
+}
+
+// NewTracedPhotoUseCase wraps next so every call is recorded as a span.
+func NewTracedPhotoUseCase(next domain.PhotoUseCase) *tracedPhotoUseCase {
+	return &tracedPhotoUseCase{next}
+}
+
+func (traced *tracedPhotoUseCase) Fetch(ctx context.Context, photos *[]domain.Photo, filter domain.PhotoFilter) (err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Fetch")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Fetch(ctx, photos, filter)
+}
+
+func (traced *tracedPhotoUseCase) Store(ctx context.Context, photo *domain.Photo) (err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Store")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Store(ctx, photo)
+}
+
+func (traced *tracedPhotoUseCase) GetByID(ctx context.Context, photo *domain.Photo, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.GetByID")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.GetByID(ctx, photo, id)
+}
+
+func (traced *tracedPhotoUseCase) Update(ctx context.Context, photo domain.Photo, id string) (p domain.Photo, err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Update")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Update(ctx, photo, id)
+}
+
+func (traced *tracedPhotoUseCase) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Delete")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Delete(ctx, id)
+}
+
+func (traced *tracedPhotoUseCase) Import(ctx context.Context, userID string, rows []domain.PhotoImportRow, dryRun bool) (report domain.PhotoImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Import")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Import(ctx, userID, rows, dryRun)
+}
+
+func (traced *tracedPhotoUseCase) Upload(ctx context.Context, upload *domain.PhotoUpload) (err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Upload")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Upload(ctx, upload)
+}
+
+func (traced *tracedPhotoUseCase) Open(ctx context.Context, userID string, file string) (r io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.Open")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Open(ctx, userID, file)
+}
+
+func (traced *tracedPhotoUseCase) ProcessMetadata(ctx context.Context) (processed int, err error) {
+	ctx, span := tracing.Start(ctx, "PhotoUseCase.ProcessMetadata")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.ProcessMetadata(ctx)
+}
@@ -5,8 +5,9 @@ import (
 )
 
 type User struct {
-	Email    string `json:"email"`
-	Username string `json:"username"`
+	Email           string `json:"email"`
+	Username        string `json:"username"`
+	ProfileImageUrl string `json:"profileImageUrl,omitempty" example:"/avatars/user-1/k3j5h2g8d9s1-256.jpg"`
 }
 
 type FetchedPhoto struct {
@@ -18,6 +19,9 @@ type FetchedPhoto struct {
 	CreatedAt *time.Time `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
 	User      *User      `json:"user"`
+
+	// DistanceKm is set when photos near a place are listed.
+	DistanceKm *float64 `json:"distance_km,omitempty" example:"1.25"`
 }
 
 type ResponseDataFetchedPhoto struct {
@@ -25,10 +29,47 @@ type ResponseDataFetchedPhoto struct {
 	Data   []FetchedPhoto `json:"data"`
 }
 
+type Location struct {
+	Latitude  float64 `json:"latitude" example:"-6.175392"`
+	Longitude float64 `json:"longitude" example:"106.827153"`
+}
+
+type PhotoMetadata struct {
+	Status      string     `json:"status" example:"ready"`
+	Error       string     `json:"error,omitempty" example:"the server answered 404 Not Found"`
+	Width       int        `json:"width,omitempty" example:"4032"`
+	Height      int        `json:"height,omitempty" example:"3024"`
+	MimeType    string     `json:"mime_type,omitempty" example:"image/jpeg"`
+	FileSize    int64      `json:"file_size,omitempty" example:"2483911"`
+	CameraModel string     `json:"camera_model,omitempty" example:"Pixel 8"`
+	TakenAt     *time.Time `json:"taken_at,omitempty"`
+
+	// Location is only shown to others when the owner made it public.
+	Location       *Location `json:"location,omitempty"`
+	LocationPublic bool      `json:"location_public" example:"false"`
+}
+
+type Photo struct {
+	ID        string        `json:"id"`
+	Title     string        `json:"title"`
+	Caption   string        `json:"caption"`
+	PhotoUrl  string        `json:"photo_url"`
+	UserID    string        `json:"user_id"`
+	CreatedAt *time.Time    `json:"created_at"`
+	UpdatedAt *time.Time    `json:"updated_at"`
+	Metadata  PhotoMetadata `json:"metadata"`
+}
+
+type ResponseDataPhoto struct {
+	Status string `json:"status" example:"success"`
+	Data   Photo  `json:"data"`
+}
+
 type AddPhoto struct {
-	Title    string `json:"title" example:"A Title"`
-	Caption  string `json:"caption" example:"A caption"`
-	PhotoUrl string `json:"photo_url" example:"https://www.example.com/image.jpg"`
+	Title          string `json:"title" example:"A Title"`
+	Caption        string `json:"caption" example:"A caption"`
+	PhotoUrl       string `json:"photo_url" example:"https://www.example.com/image.jpg"`
+	LocationPublic bool   `json:"location_public" example:"false"`
 }
 
 type AddedPhoto struct {
@@ -74,3 +115,23 @@ type ResponseMessage struct {
 	Status string `json:"status" example:"fail"`
 	Data   string `json:"data" example:"the error explained here"`
 }
+
+type ImportedPhotoRow struct {
+	Line   int    `json:"line" example:"2"`
+	Status string `json:"status" example:"imported"`
+	ID     string `json:"id,omitempty" example:"here is the generated photo id"`
+	Title  string `json:"title" example:"A Title"`
+	Error  string `json:"error,omitempty" example:"photo_url: non zero value required"`
+}
+
+type ImportedPhotos struct {
+	DryRun    bool               `json:"dry_run" example:"false"`
+	Succeeded int                `json:"succeeded" example:"1"`
+	Failed    int                `json:"failed" example:"0"`
+	Rows      []ImportedPhotoRow `json:"rows"`
+}
+
+type ResponseDataImportedPhotos struct {
+	Status string         `json:"status" example:"success"`
+	Data   ImportedPhotos `json:"data"`
+}
@@ -1,13 +1,13 @@
 package delivery
 
 import (
+	"api-mygram-go/auth"
 	"api-mygram-go/domain"
 	"api-mygram-go/helpers"
-	"api-mygram-go/socialmedia/delivery/http/middleware"
 	"api-mygram-go/socialmedia/utils"
+	"context"
 	"net/http"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,19 +15,30 @@ type socialMediaHandler struct {
 	socialMediaUseCase domain.SocialMediaUseCase
 }
 
-func NewSocialMediaHandler(routers *gin.Engine, socialMediaUseCase domain.SocialMediaUseCase) {
+func NewSocialMediaHandler(routers *gin.Engine, socialMediaUseCase domain.SocialMediaUseCase, authenticator *auth.Authenticator) {
 	handler := &socialMediaHandler{socialMediaUseCase}
 
 	router := routers.Group("/socialmedias")
 	{
-		router.Use(middleware.Authentication())
-		router.GET("", handler.Fetch)
-		router.POST("", handler.Store)
-		router.PUT("/:socialMediaId", middleware.Authorization(handler.socialMediaUseCase), handler.Update)
-		router.DELETE("/:socialMediaId", middleware.Authorization(handler.socialMediaUseCase), handler.Delete)
+		router.Use(authenticator.Authentication())
+		router.GET("", auth.RequireScope(domain.ScopeSocialMediasRead), handler.Fetch)
+		router.POST("", auth.RequireScope(domain.ScopeSocialMediasWrite), handler.Store)
+		router.PUT("/:socialMediaId", auth.RequireScope(domain.ScopeSocialMediasWrite), auth.Ownership("social media", "socialMediaId", handler.socialMediaOwner), handler.Update)
+		router.DELETE("/:socialMediaId", auth.RequireScope(domain.ScopeSocialMediasWrite), auth.Ownership("social media", "socialMediaId", handler.socialMediaOwner), handler.Delete)
 	}
 }
 
+// socialMediaOwner loads the owner of a SocialMedia for auth.Ownership.
+func (handler *socialMediaHandler) socialMediaOwner(ctx context.Context, id string) (string, error) {
+	var socialMedia domain.SocialMedia
+
+	if err := handler.socialMediaUseCase.GetByID(ctx, &socialMedia, id); err != nil {
+		return "", err
+	}
+
+	return socialMedia.UserID, nil
+}
+
 // Fetch godoc
 // @Summary    	Fetch all social media
 // @Description	Get all social media with authentication user
@@ -37,6 +48,7 @@ func NewSocialMediaHandler(routers *gin.Engine, socialMediaUseCase domain.Social
 // @Success     200	{object}	utils.ResponseDataFetchedSocialMedia
 // @Failure     400	{object}	utils.ResponseMessage
 // @Failure     401	{object}	utils.ResponseMessage
+// @Failure     403	{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /socialmedias	[get]
 func (handler *socialMediaHandler) Fetch(ctx *gin.Context) {
@@ -45,8 +57,13 @@ func (handler *socialMediaHandler) Fetch(ctx *gin.Context) {
 		err          error
 	)
 
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = handler.socialMediaUseCase.Fetch(ctx.Request.Context(), &socialMedias, userID); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -75,6 +92,7 @@ func (handler *socialMediaHandler) Fetch(ctx *gin.Context) {
 // @Success     201		{object}  utils.ResponseDataAddedSocialMedia
 // @Failure     400		{object}	utils.ResponseMessage
 // @Failure     401		{object}	utils.ResponseMessage
+// @Failure     403		{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /socialmedias		[post]
 func (handler *socialMediaHandler) Store(ctx *gin.Context) {
@@ -83,8 +101,13 @@ func (handler *socialMediaHandler) Store(ctx *gin.Context) {
 		err         error
 	)
 
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = ctx.ShouldBindJSON(&socialMedia); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -129,6 +152,7 @@ func (handler *socialMediaHandler) Store(ctx *gin.Context) {
 // @Success     200		{object}	utils.ResponseDataUpdatedSocialMedia
 // @Failure     400		{object}	utils.ResponseMessage
 // @Failure     401		{object}	utils.ResponseMessage
+// @Failure     403		{object}	utils.ResponseMessage
 // @Failure     404		{object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /socialmedias/{id} [put]
@@ -139,8 +163,13 @@ func (handler *socialMediaHandler) Update(ctx *gin.Context) {
 	)
 
 	socialMediaID := ctx.Param("socialMediaId")
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
 
 	if err = ctx.ShouldBindJSON(&socialMedia); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
@@ -185,6 +214,7 @@ func (handler *socialMediaHandler) Update(ctx *gin.Context) {
 // @Success     200  {object}	utils.ResponseMessageDeletedSocialMedia
 // @Failure     400  {object}	utils.ResponseMessage
 // @Failure     401  {object}	utils.ResponseMessage
+// @Failure     403  {object}	utils.ResponseMessage
 // @Failure     404  {object}	utils.ResponseMessage
 // @Security    Bearer
 // @Router      /socialmedias/{id} [delete]
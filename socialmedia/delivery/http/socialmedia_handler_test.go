@@ -0,0 +1,194 @@
+package delivery_test
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	socialMediaDelivery "api-mygram-go/socialmedia/delivery/http"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var socialMedias = map[string]domain.SocialMedia{
+	"socialmedia-1": {ID: "socialmedia-1", Name: "GitHub", SocialMediaUrl: "https://github.com/johndoe", UserID: "user-1"},
+	"socialmedia-2": {ID: "socialmedia-2", Name: "GitLab", SocialMediaUrl: "https://gitlab.com/janedoe", UserID: "user-2"},
+}
+
+func getSocialMedia(ctx context.Context, socialMedia *domain.SocialMedia, id string) error {
+	stored, ok := socialMedias[id]
+
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*socialMedia = stored
+
+	return nil
+}
+
+func TestSocialMediaHandler(t *testing.T) {
+	readWrite := []string{domain.ScopeSocialMediasRead, domain.ScopeSocialMediasWrite}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		scopes     []string
+		useCase    fakes.SocialMediaUseCase
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "fetch without credentials",
+			method:     http.MethodGet,
+			path:       "/socialmedias",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "fetch without the read scope",
+			method:     http.MethodGet,
+			path:       "/socialmedias",
+			scopes:     []string{domain.ScopeSocialMediasWrite},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "fetch",
+			method: http.MethodGet,
+			path:   "/socialmedias",
+			scopes: readWrite,
+			useCase: fakes.SocialMediaUseCase{FetchFunc: func(ctx context.Context, fetched *[]domain.SocialMedia, userID string) error {
+				socialMedia := socialMedias["socialmedia-1"]
+				socialMedia.User = &domain.User{ID: userID, Username: "johndoe", Email: "johndoe@example.com"}
+				*fetched = []domain.SocialMedia{socialMedia}
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"social_medias":[{"id":"socialmedia-1"`,
+		},
+		{
+			name:   "fetch fails",
+			method: http.MethodGet,
+			path:   "/socialmedias",
+			scopes: readWrite,
+			useCase: fakes.SocialMediaUseCase{FetchFunc: func(context.Context, *[]domain.SocialMedia, string) error {
+				return errors.New("connection refused")
+			}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "store malformed json",
+			method:     http.MethodPost,
+			path:       "/socialmedias",
+			body:       `[]`,
+			scopes:     readWrite,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "store",
+			method: http.MethodPost,
+			path:   "/socialmedias",
+			body:   `{"name":"GitHub","social_media_url":"https://github.com/johndoe"}`,
+			scopes: readWrite,
+			useCase: fakes.SocialMediaUseCase{StoreFunc: func(ctx context.Context, socialMedia *domain.SocialMedia) error {
+				socialMedia.ID = "socialmedia-3"
+
+				return nil
+			}},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"user_id":"user-1"`,
+		},
+		{
+			name:       "update a missing social media",
+			method:     http.MethodPut,
+			path:       "/socialmedias/socialmedia-9",
+			body:       `{"name":"Codeberg"}`,
+			scopes:     readWrite,
+			useCase:    fakes.SocialMediaUseCase{GetByIDFunc: getSocialMedia},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "social media with id socialmedia-9 doesn't exist",
+		},
+		{
+			name:       "update someone else's social media",
+			method:     http.MethodPut,
+			path:       "/socialmedias/socialmedia-2",
+			body:       `{"name":"Codeberg"}`,
+			scopes:     readWrite,
+			useCase:    fakes.SocialMediaUseCase{GetByIDFunc: getSocialMedia},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "update",
+			method: http.MethodPut,
+			path:   "/socialmedias/socialmedia-1",
+			body:   `{"name":"Codeberg","social_media_url":"https://codeberg.org/johndoe"}`,
+			scopes: readWrite,
+			useCase: fakes.SocialMediaUseCase{
+				GetByIDFunc: getSocialMedia,
+				UpdateFunc: func(ctx context.Context, socialMedia domain.SocialMedia, id string) (domain.SocialMedia, error) {
+					socialMedia.ID = id
+
+					return socialMedia, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"Codeberg"`,
+		},
+		{
+			name:       "delete",
+			method:     http.MethodDelete,
+			path:       "/socialmedias/socialmedia-1",
+			scopes:     readWrite,
+			useCase:    fakes.SocialMediaUseCase{GetByIDFunc: getSocialMedia},
+			wantStatus: http.StatusOK,
+			wantBody:   "successfully deleted",
+		},
+		{
+			name:       "delete without the write scope",
+			method:     http.MethodDelete,
+			path:       "/socialmedias/socialmedia-1",
+			scopes:     []string{domain.ScopeSocialMediasRead},
+			useCase:    fakes.SocialMediaUseCase{GetByIDFunc: getSocialMedia},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routers := gin.New()
+			useCase := test.useCase
+
+			socialMediaDelivery.NewSocialMediaHandler(routers, &useCase, auth.NewAuthenticator(fakes.KeySet(), fakes.AuthenticatingAPIKeys("user-1", test.scopes...), &fakes.SessionUseCase{}))
+
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			if test.scopes != nil {
+				request.Header.Set("X-API-Key", "mygram_test")
+			}
+
+			recorder := httptest.NewRecorder()
+			routers.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+
+			if !strings.Contains(recorder.Body.String(), test.wantBody) {
+				t.Errorf("body %s doesn't contain %s", recorder.Body, test.wantBody)
+			}
+		})
+	}
+}
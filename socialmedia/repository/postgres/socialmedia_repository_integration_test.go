@@ -0,0 +1,58 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"api-mygram-go/config/database/databasetest"
+	"api-mygram-go/domain"
+	socialMediaRepository "api-mygram-go/socialmedia/repository/postgres"
+	"context"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	databasetest.Main(m)
+}
+
+func TestSocialMediaRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := socialMediaRepository.NewSocialMediaRepository(db)
+
+	for _, user := range []domain.User{
+		{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20},
+		{ID: "user-2", Username: "janedoe", Email: "janedoe@example.com", Password: "secret", Age: 20},
+	} {
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	socialMedia := domain.SocialMedia{Name: "GitHub", SocialMediaUrl: "https://github.com/johndoe", UserID: "user-1"}
+
+	if err := repository.Store(ctx, &socialMedia); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Store(ctx, &domain.SocialMedia{Name: "GitLab", SocialMediaUrl: "https://gitlab.com/janedoe", UserID: "user-2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var socialMedias []domain.SocialMedia
+
+	if err := repository.Fetch(ctx, &socialMedias, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(socialMedias) != 1 || socialMedias[0].User == nil || socialMedias[0].User.Username != "johndoe" {
+		t.Fatalf("fetched %+v, want only johndoe's social media with the user preloaded", socialMedias)
+	}
+
+	updated, err := repository.Update(ctx, domain.SocialMedia{Name: "Codeberg", SocialMediaUrl: "https://codeberg.org/johndoe"}, socialMedia.ID)
+
+	if err != nil || updated.Name != "Codeberg" {
+		t.Errorf("update returned %+v, %v", updated, err)
+	}
+}
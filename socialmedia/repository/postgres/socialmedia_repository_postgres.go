@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"api-mygram-go/config/database"
 	"api-mygram-go/domain"
 	"time"
 
@@ -23,7 +24,7 @@ func (socialMediaRepository *socialMediaRepository) Fetch(ctx context.Context, s
 
 	defer cancel()
 
-	if err = socialMediaRepository.db.WithContext(ctx).Where("user_id = ?", userID).Preload("User", func(db *gorm.DB) *gorm.DB {
+	if err = database.FromContext(ctx, socialMediaRepository.db).Where("user_id = ?", userID).Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("ID", "Email", "Username", "ProfileImageUrl")
 	}).Find(&socialMedias).Error; err != nil {
 		return err
@@ -41,7 +42,7 @@ func (socialMediaRepository *socialMediaRepository) Store(ctx context.Context, s
 
 	socialMedia.ID = fmt.Sprintf("socialmedia-%s", ID)
 
-	if err = socialMediaRepository.db.WithContext(ctx).Create(&socialMedia).Error; err != nil {
+	if err = database.FromContext(ctx, socialMediaRepository.db).Create(&socialMedia).Error; err != nil {
 		return err
 	}
 
@@ -53,7 +54,7 @@ func (socialMediaRepository *socialMediaRepository) GetByID(ctx context.Context,
 
 	defer cancel()
 
-	if err = socialMediaRepository.db.WithContext(ctx).First(&socialMedia, &id).Error; err != nil {
+	if err = database.FromContext(ctx, socialMediaRepository.db).First(&socialMedia, &id).Error; err != nil {
 		return err
 	}
 
@@ -67,11 +68,11 @@ func (socialMediaRepository *socialMediaRepository) Update(ctx context.Context,
 
 	socmed = domain.SocialMedia{}
 
-	if err = socialMediaRepository.db.WithContext(ctx).First(&socmed, &id).Error; err != nil {
+	if err = database.FromContext(ctx, socialMediaRepository.db).First(&socmed, &id).Error; err != nil {
 		return socmed, err
 	}
 
-	if err = socialMediaRepository.db.WithContext(ctx).Model(&socmed).Updates(socialMedia).Error; err != nil {
+	if err = database.FromContext(ctx, socialMediaRepository.db).Model(&socmed).Updates(socialMedia).Error; err != nil {
 		return socmed, err
 	}
 
@@ -83,11 +84,11 @@ func (socialMediaRepository *socialMediaRepository) Delete(ctx context.Context,
 
 	defer cancel()
 
-	if err = socialMediaRepository.db.WithContext(ctx).First(&domain.SocialMedia{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, socialMediaRepository.db).First(&domain.SocialMedia{}, &id).Error; err != nil {
 		return err
 	}
 
-	if err = socialMediaRepository.db.WithContext(ctx).Delete(&domain.SocialMedia{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, socialMediaRepository.db).Delete(&domain.SocialMedia{}, &id).Error; err != nil {
 		return err
 	}
 
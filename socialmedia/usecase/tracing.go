@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/tracing"
+	"context"
+)
+
+type tracedSocialMediaUseCase struct {
+	next domain.SocialMediaUseCase
+}
+
+// NewTracedSocialMediaUseCase wraps next so every call is recorded as a span.
+func NewTracedSocialMediaUseCase(next domain.SocialMediaUseCase) *tracedSocialMediaUseCase {
+	return &tracedSocialMediaUseCase{next}
+}
+
+func (traced *tracedSocialMediaUseCase) Fetch(ctx context.Context, socialMedias *[]domain.SocialMedia, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "SocialMediaUseCase.Fetch")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Fetch(ctx, socialMedias, userID)
+}
+
+func (traced *tracedSocialMediaUseCase) Store(ctx context.Context, socialMedia *domain.SocialMedia) (err error) {
+	ctx, span := tracing.Start(ctx, "SocialMediaUseCase.Store")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Store(ctx, socialMedia)
+}
+
+func (traced *tracedSocialMediaUseCase) GetByID(ctx context.Context, socialMedia *domain.SocialMedia, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "SocialMediaUseCase.GetByID")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.GetByID(ctx, socialMedia, id)
+}
+
+func (traced *tracedSocialMediaUseCase) Update(ctx context.Context, socialMedia domain.SocialMedia, id string) (s domain.SocialMedia, err error) {
+	ctx, span := tracing.Start(ctx, "SocialMediaUseCase.Update")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Update(ctx, socialMedia, id)
+}
+
+func (traced *tracedSocialMediaUseCase) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "SocialMediaUseCase.Delete")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Delete(ctx, id)
+}
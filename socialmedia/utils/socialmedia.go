@@ -3,9 +3,10 @@ package utils
 import "time"
 
 type User struct {
-	ID       string `json:"id" example:"here is the generated user id"`
-	Username string `json:"username" example:"johndoe"`
-	Email    string `json:"email" example:"johndoe@example.com"`
+	ID              string `json:"id" example:"here is the generated user id"`
+	Username        string `json:"username" example:"johndoe"`
+	Email           string `json:"email" example:"johndoe@example.com"`
+	ProfileImageUrl string `json:"profileImageUrl,omitempty" example:"/avatars/user-1/k3j5h2g8d9s1-256.jpg"`
 }
 
 type SocialMedia struct {
@@ -0,0 +1,182 @@
+// Package local keeps blobs as files under a directory of the local disk.
+package local
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type blobStore struct {
+	root string
+}
+
+// NewBlobStore stores blobs under root, creating it when it doesn't exist.
+func NewBlobStore(root string) (*blobStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &blobStore{root}, nil
+}
+
+// path maps key to a file under the root, refusing keys that would escape it.
+func (blobStore *blobStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+
+	if clean == "/" || clean != "/"+strings.TrimSuffix(key, "/") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(blobStore.root, filepath.FromSlash(clean)), nil
+}
+
+// Put writes to a temporary file first, so readers never see half a blob.
+func (blobStore *blobStore) Put(ctx context.Context, key string, r io.Reader) (err error) {
+	path, err := blobStore.path(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, r); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (blobStore *blobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := blobStore.path(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return file, err
+}
+
+// List returns the keys under prefix, which must name a directory, in
+// lexical order.
+func (blobStore *blobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	if !strings.HasSuffix(prefix, "/") {
+		return nil, fmt.Errorf("blob prefix %q must end with a slash", prefix)
+	}
+
+	path, err := blobStore.path(prefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip the temporary files of uploads in progress.
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		relative, err := filepath.Rel(path, file)
+
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, prefix+filepath.ToSlash(relative))
+
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return keys, nil
+	}
+
+	return keys, err
+}
+
+func (blobStore *blobStore) Delete(ctx context.Context, key string) error {
+	path, err := blobStore.path(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); errors.Is(err, fs.ErrNotExist) {
+		return domain.ErrBlobNotFound
+	}
+
+	return err
+}
+
+// DeletePrefix removes the blobs under prefix, which must name a directory
+// such as "users/user-1/", and reports how many there were.
+func (blobStore *blobStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if !strings.HasSuffix(prefix, "/") {
+		return 0, fmt.Errorf("blob prefix %q must end with a slash", prefix)
+	}
+
+	path, err := blobStore.path(prefix)
+
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+
+	err = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			deleted++
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, os.RemoveAll(path)
+}
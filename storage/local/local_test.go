@@ -0,0 +1,63 @@
+package local
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewBlobStore(t.TempDir())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"users/user-1/avatar.jpg", "users/user-1/exports/export-1.zip", "users/user-2/avatar.jpg"} {
+		if err = store.Put(ctx, key, strings.NewReader(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := store.Open(ctx, "users/user-1/avatar.jpg")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := io.ReadAll(file)
+	file.Close()
+
+	if string(content) != "users/user-1/avatar.jpg" {
+		t.Errorf("content = %q", content)
+	}
+
+	keys, err := store.List(ctx, "users/user-1/")
+
+	if want := []string{"users/user-1/avatar.jpg", "users/user-1/exports/export-1.zip"}; err != nil || !reflect.DeepEqual(keys, want) {
+		t.Errorf("List = %v, %v, want %v", keys, err, want)
+	}
+
+	if deleted, err := store.DeletePrefix(ctx, "users/user-1/"); err != nil || deleted != 2 {
+		t.Errorf("DeletePrefix deleted %d, %v, want 2", deleted, err)
+	}
+
+	if _, err = store.Open(ctx, "users/user-1/avatar.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("opening a deleted blob returned %v", err)
+	}
+
+	if err = store.Delete(ctx, "users/user-2/avatar.jpg"); err != nil {
+		t.Errorf("Delete returned %v", err)
+	}
+
+	for _, key := range []string{"../escape", "users/../../escape", "/"} {
+		if err = store.Put(ctx, key, strings.NewReader("")); err == nil {
+			t.Errorf("Put accepted the key %q", key)
+		}
+	}
+}
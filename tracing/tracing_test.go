@@ -0,0 +1,181 @@
+package tracing_test
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	photoUseCase "api-mygram-go/photo/usecase"
+	"api-mygram-go/tracing"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var exporter = tracetest.NewInMemoryExporter()
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	os.Exit(m.Run())
+}
+
+func spanNamed(t *testing.T, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	t.Fatalf("no span named %q was exported", name)
+
+	return tracetest.SpanStub{}
+}
+
+func attributeOf(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter.Reset()
+
+	routers := gin.New()
+	routers.Use(tracing.Middleware())
+	routers.GET("/photos/:photoId", func(ctx *gin.Context) {
+		_, span := tracing.Start(ctx.Request.Context(), "handler")
+		span.End()
+
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/photos/photo-1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	routers.ServeHTTP(httptest.NewRecorder(), request)
+
+	server := spanNamed(t, "GET /photos/:photoId")
+
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the one from traceparent", got)
+	}
+
+	if got := server.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want the one from traceparent", got)
+	}
+
+	if got := attributeOf(server, "http.response.status_code").AsInt64(); got != http.StatusInternalServerError {
+		t.Errorf("status code attribute = %d, want 500", got)
+	}
+
+	if server.Status.Code != codes.Error {
+		t.Errorf("status = %v, want Error for a 5xx response", server.Status.Code)
+	}
+
+	if handler := spanNamed(t, "handler"); handler.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Error("handler span isn't a child of the server span")
+	}
+}
+
+type failingPhotoRepository struct {
+	domain.PhotoRepository
+	err error
+}
+
+func (repository failingPhotoRepository) GetByID(ctx context.Context, photo *domain.Photo, id string) error {
+	return repository.err
+}
+
+func (repository failingPhotoRepository) Delete(ctx context.Context, id string) error {
+	return repository.err
+}
+
+func TestUseCaseDecoratorRecordsErrors(t *testing.T) {
+	exporter.Reset()
+
+	failure := errors.New("connection refused")
+	useCase := photoUseCase.NewTracedPhotoUseCase(photoUseCase.NewPhotoUseCase(failingPhotoRepository{err: failure}, fakes.NewBlobStore(), fakes.ImageFetcher{}, fakes.TxManager{}, func() {}))
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+
+	if err := useCase.Delete(ctx, "photo-1"); !errors.Is(err, failure) {
+		t.Fatalf("Delete returned %v, want %v", err, failure)
+	}
+
+	parent.End()
+
+	span := spanNamed(t, "PhotoUseCase.Delete")
+
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("usecase span isn't a child of the caller's span")
+	}
+
+	if span.Status.Code != codes.Error || span.Status.Description != failure.Error() {
+		t.Errorf("status = %v %q, want Error %q", span.Status.Code, span.Status.Description, failure)
+	}
+}
+
+func TestUseCaseDecoratorIgnoresRecordNotFound(t *testing.T) {
+	exporter.Reset()
+
+	useCase := photoUseCase.NewTracedPhotoUseCase(photoUseCase.NewPhotoUseCase(failingPhotoRepository{err: gorm.ErrRecordNotFound}, fakes.NewBlobStore(), fakes.ImageFetcher{}, fakes.TxManager{}, func() {}))
+
+	_ = useCase.Delete(context.Background(), "photo-1")
+
+	if span := spanNamed(t, "PhotoUseCase.Delete"); span.Status.Code == codes.Error {
+		t.Error("a missing record was recorded as an error")
+	}
+}
+
+func TestGormPluginTracesQueries(t *testing.T) {
+	exporter.Reset()
+
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.Use(tracing.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+
+	var photo domain.Photo
+
+	db.WithContext(ctx).Where("id = ?", "photo-1").First(&photo)
+	parent.End()
+
+	span := spanNamed(t, "db.query")
+
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("query span isn't a child of the caller's span")
+	}
+
+	if got := attributeOf(span, "db.collection.name").AsString(); got != "photos" {
+		t.Errorf("table attribute = %q, want photos", got)
+	}
+
+	if got := attributeOf(span, "db.query.text").AsString(); got == "" {
+		t.Error("statement attribute is empty")
+	}
+}
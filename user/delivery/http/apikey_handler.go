@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/user/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateAPIKey godoc
+// @Summary			Create an API key
+// @Description	Create an API key with the given scopes and optional expiry. The key is only returned once.
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Param				json	body			utils.CreateAPIKey	true	"Create API Key"
+// @Success			201		{object}	utils.ResponseDataCreatedAPIKey
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/apikeys	[post]
+func (handler *userHandler) CreateAPIKey(ctx *gin.Context) {
+	var (
+		createAPIKey utils.CreateAPIKey
+		err          error
+	)
+
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	if err = ctx.ShouldBindJSON(&createAPIKey); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	apiKey := domain.APIKey{
+		UserID:    userID,
+		Name:      createAPIKey.Name,
+		ExpiresAt: createAPIKey.ExpiresAt,
+	}
+
+	key, err := handler.apiKeyUseCase.Create(ctx.Request.Context(), &apiKey, createAPIKey.Scopes)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ResponseData{
+		Status: "success",
+		Data: utils.CreatedAPIKey{
+			ID:        apiKey.ID,
+			Name:      apiKey.Name,
+			Key:       key,
+			Prefix:    apiKey.Prefix,
+			Scopes:    apiKey.ScopeList(),
+			ExpiresAt: apiKey.ExpiresAt,
+			CreatedAt: apiKey.CreatedAt,
+		},
+	})
+}
+
+// FetchAPIKeys godoc
+// @Summary			Fetch API keys
+// @Description	Get the API keys of the authentication user, including revoked and expired ones
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			200		{object}	utils.ResponseDataFetchedAPIKeys
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/apikeys	[get]
+func (handler *userHandler) FetchAPIKeys(ctx *gin.Context) {
+	var (
+		apiKeys []domain.APIKey
+		err     error
+	)
+
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	if err = handler.apiKeyUseCase.Fetch(ctx.Request.Context(), &apiKeys, userID); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	fetchedAPIKeys := []utils.APIKey{}
+
+	for _, apiKey := range apiKeys {
+		fetchedAPIKeys = append(fetchedAPIKeys, utils.APIKey{
+			ID:         apiKey.ID,
+			Name:       apiKey.Name,
+			Prefix:     apiKey.Prefix,
+			Scopes:     apiKey.ScopeList(),
+			ExpiresAt:  apiKey.ExpiresAt,
+			LastUsedAt: apiKey.LastUsedAt,
+			RevokedAt:  apiKey.RevokedAt,
+			CreatedAt:  apiKey.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data:   fetchedAPIKeys,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary			Revoke an API key
+// @Description	Revoke an API key of the authentication user
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Param				apiKeyId	path			string	true	"Revoke API key by id"
+// @Success			200				{object}	utils.ResponseMessageRevokedAPIKey
+// @Failure			400				{object}	utils.ResponseMessage
+// @Failure			401				{object}	utils.ResponseMessage
+// @Failure			403				{object}	utils.ResponseMessage
+// @Failure			404				{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/apikeys/{apiKeyId}	[delete]
+func (handler *userHandler) RevokeAPIKey(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+	apiKeyID := ctx.Param("apiKeyId")
+
+	if err := handler.apiKeyUseCase.Revoke(ctx.Request.Context(), apiKeyID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: fmt.Sprintf("active api key with id %s doesn't exist", apiKeyID),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseMessage{
+		Status:  "success",
+		Message: "your api key has been successfully revoked",
+	})
+}
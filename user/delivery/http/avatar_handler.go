@@ -0,0 +1,144 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/user/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxAvatarBytes = 10 << 20
+
+// SetAvatar godoc
+// @Summary			Upload an avatar
+// @Description	Set the avatar of the authentication user from a JPEG, PNG, GIF or WebP image of up to 10 MB. The image is cropped to a centered square and stored in several sizes, profileImageUrl points at the 256 pixels one.
+// @Tags				users
+// @Accept			multipart/form-data
+// @Produce			json
+// @Param				avatar	formData	file	true	"Avatar image"
+// @Success			200		{object}	utils.ResponseDataAvatar
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Failure			413		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/me/avatar	[put]
+func (handler *userHandler) SetAvatar(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxAvatarBytes)
+
+	header, err := ctx.FormFile("avatar")
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+
+		if errors.As(err, &tooLarge) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "the avatar can't be larger than 10 MB",
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: "send the image as the avatar field of a multipart form",
+		})
+
+		return
+	}
+
+	file, err := header.Open()
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	defer file.Close()
+
+	user, err := handler.avatarUseCase.Set(ctx.Request.Context(), principal.UserID, file)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	avatar := utils.Avatar{
+		ProfileImageUrl: user.ProfileImageUrl,
+		Sizes:           map[string]string{},
+	}
+
+	for _, size := range domain.AvatarSizes {
+		avatar.Sizes[strconv.Itoa(size)] = avatarURL(user.ProfileImageUrl, size)
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data:   avatar,
+	})
+}
+
+// avatarURL swaps the size of the profile image URL of an avatar for size.
+func avatarURL(profileImageUrl string, size int) string {
+	suffix := "-" + strconv.Itoa(domain.ProfileAvatarSize) + ".jpg"
+
+	return profileImageUrl[:len(profileImageUrl)-len(suffix)] + "-" + strconv.Itoa(size) + ".jpg"
+}
+
+// GetAvatar godoc
+// @Summary			Get an avatar
+// @Description	Download an avatar file from the URL it was given when uploaded. Files never change, so they can be cached for good.
+// @Tags				users
+// @Produce			jpeg
+// @Param				userId	path			string	true	"User ID"
+// @Param				file		path			string	true	"Avatar file"
+// @Success			200		{file}		binary
+// @Failure			404		{object}	utils.ResponseMessage
+// @Router			/avatars/{userId}/{file}	[get]
+func (handler *userHandler) GetAvatar(ctx *gin.Context) {
+	file, err := handler.avatarUseCase.Open(ctx.Request.Context(), ctx.Param("userId"), ctx.Param("file"))
+
+	if err != nil {
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "avatar not found",
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	defer file.Close()
+
+	ctx.Header("Content-Type", "image/jpeg")
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.DataFromReader(http.StatusOK, -1, "image/jpeg", file, nil)
+}
@@ -0,0 +1,159 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/user/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Delete godoc
+// @Summary			Delete a user
+// @Description	Schedule the deletion of the authentication user. The account, its photos, comments, social medias and files are erased once the grace period ends, and comments on other people's photos are kept without their author.
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			200			{object}	utils.ResponseMessageDeletedUser
+// @Failure			400			{object}	utils.ResponseMessage
+// @Failure			401			{object}	utils.ResponseMessage
+// @Failure			403			{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users	[delete]
+func (handler *userHandler) Delete(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	deletion, err := handler.deletionUseCase.Schedule(ctx.Request.Context(), userID)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: "account not found",
+		})
+
+		return
+	}
+
+	message := "your account will be deleted shortly"
+
+	if deletion.PurgeAfter != nil {
+		message = fmt.Sprintf("your account will be deleted after %s, cancel the deletion before then to keep it", deletion.PurgeAfter.UTC().Format("2006-01-02 15:04 MST"))
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		helpers.ResponseMessage{
+			Status:  "success",
+			Message: message,
+		},
+	)
+}
+
+// GetDeletion godoc
+// @Summary			Get the pending deletion
+// @Description	Get when the account of the authentication user is going to be deleted
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			200		{object}	utils.ResponseDataAccountDeletion
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Failure			404		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/deletion	[get]
+func (handler *userHandler) GetDeletion(ctx *gin.Context) {
+	var deletion domain.AccountDeletion
+
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	if err := handler.deletionUseCase.GetPending(ctx.Request.Context(), &deletion, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "your account isn't scheduled for deletion",
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data: utils.AccountDeletion{
+			ID:         deletion.ID,
+			PurgeAfter: deletion.PurgeAfter,
+			CreatedAt:  deletion.CreatedAt,
+		},
+	})
+}
+
+// CancelDeletion godoc
+// @Summary			Cancel the pending deletion
+// @Description	Keep the account of the authentication user
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			200		{object}	utils.ResponseMessageCanceledDeletion
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Failure			404		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/deletion	[delete]
+func (handler *userHandler) CancelDeletion(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	if err := handler.deletionUseCase.Cancel(ctx.Request.Context(), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: "your account isn't scheduled for deletion",
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseMessage{
+		Status:  "success",
+		Message: "your account will no longer be deleted",
+	})
+}
@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/user/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequestExport godoc
+// @Summary			Export my data
+// @Description	Queue a ZIP archive of the profile, photos, comments, social medias and stored files of the authentication user. Poll the export until it is ready to get its download link.
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			202		{object}	utils.ResponseDataDataExport
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/me/export	[post]
+func (handler *userHandler) RequestExport(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	export, err := handler.dataExportUseCase.Request(ctx.Request.Context(), userID)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, helpers.ResponseData{
+		Status: "success",
+		Data:   handler.dataExport(export),
+	})
+}
+
+// GetExport godoc
+// @Summary			Get an export
+// @Description	Get the status of an export of the authentication user, with a time-limited download link once it is ready
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Param				exportId	path			string	true	"Get export by id"
+// @Success			200				{object}	utils.ResponseDataDataExport
+// @Failure			400				{object}	utils.ResponseMessage
+// @Failure			401				{object}	utils.ResponseMessage
+// @Failure			403				{object}	utils.ResponseMessage
+// @Failure			404				{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/me/export/{exportId}	[get]
+func (handler *userHandler) GetExport(ctx *gin.Context) {
+	var export domain.DataExport
+
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+	exportID := ctx.Param("exportId")
+
+	if err := handler.dataExportUseCase.GetByID(ctx.Request.Context(), &export, exportID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: fmt.Sprintf("export with id %s doesn't exist", exportID),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data:   handler.dataExport(export),
+	})
+}
+
+// DownloadExport godoc
+// @Summary			Download an export
+// @Description	Download the ZIP archive of an export. The link returned with a ready export authorizes the download, so no credentials are needed.
+// @Tags				users
+// @Produce			application/zip
+// @Param				exportId	path			string	true	"Download export by id"
+// @Param				expires		query			int			true	"Expiry of the link, in seconds since the epoch"
+// @Param				signature	query			string	true	"Signature of the link"
+// @Success			200				{file}		file
+// @Failure			403				{object}	utils.ResponseMessage
+// @Router			/users/me/export/{exportId}/download	[get]
+func (handler *userHandler) DownloadExport(ctx *gin.Context) {
+	exportID := ctx.Param("exportId")
+	expires, err := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: domain.ErrExportLinkInvalid.Error(),
+		})
+
+		return
+	}
+
+	link := domain.DataExportLink{Expires: time.Unix(expires, 0), Signature: ctx.Query("signature")}
+	file, err := handler.dataExportUseCase.Open(ctx.Request.Context(), exportID, link)
+
+	if err != nil {
+		if errors.Is(err, domain.ErrExportLinkInvalid) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	defer file.Close()
+
+	// The router defaults every response to JSON, and gin keeps a
+	// Content-Type that is already set.
+	ctx.Header("Content-Type", "application/zip")
+	ctx.DataFromReader(http.StatusOK, -1, "application/zip", file, map[string]string{
+		"Content-Disposition": `attachment; filename="mygram-export.zip"`,
+		"Cache-Control":       "no-store",
+	})
+}
+
+func (handler *userHandler) dataExport(export domain.DataExport) utils.DataExport {
+	dataExport := utils.DataExport{
+		ID:          export.ID,
+		Status:      export.Status,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+
+	if export.Status == domain.DataExportReady {
+		link := handler.dataExportUseCase.Link(export)
+		query := url.Values{
+			"expires":   {strconv.FormatInt(link.Expires.Unix(), 10)},
+			"signature": {link.Signature},
+		}
+
+		dataExport.DownloadURL = fmt.Sprintf("/users/me/export/%s/download?%s", url.PathEscape(export.ID), query.Encode())
+		dataExport.DownloadExpiresAt = &link.Expires
+	}
+
+	return dataExport
+}
@@ -0,0 +1,223 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/user/utils"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMFA godoc
+// @Summary			Complete a two-factor login
+// @Description	Exchange the mfa_required challenge token and a TOTP or recovery code for a token
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Param				json	body			utils.LoginMFA	true	"Login MFA"
+// @Success			200		{object}	utils.ResponseDataLoggedinUser
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			429		{object}	utils.ResponseMessage
+// @Router			/users/login/mfa		[post]
+func (handler *userHandler) LoginMFA(ctx *gin.Context) {
+	var (
+		loginMFA utils.LoginMFA
+		err      error
+	)
+
+	if err = ctx.ShouldBindJSON(&loginMFA); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	claims, err := handler.keySet.VerifyMFAChallengeToken(loginMFA.MFAToken)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	userID, _ := claims["id"].(string)
+	email, _ := claims["email"].(string)
+
+	if err = handler.mfaUseCase.Verify(ctx.Request.Context(), userID, loginMFA.Code); err != nil {
+		var lockedErr *domain.LoginLockedError
+
+		if errors.As(err, &lockedErr) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, helpers.ResponseMessage{
+				Status:  "unauthenticated",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	session, err := handler.sessionUseCase.Start(ctx.Request.Context(), userID, newLoginInfo(ctx))
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	token, err := handler.keySet.GenerateToken(userID, email, session.ID)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data: utils.LoggedinUser{
+			Token: token,
+		},
+	})
+}
+
+// EnrollTOTP godoc
+// @Summary			Start TOTP enrollment
+// @Description	Generate a TOTP secret and provisioning URI for the authentication user
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			201		{object}	utils.ResponseDataTOTPEnrollment
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Failure			409		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/mfa/totp	[post]
+func (handler *userHandler) EnrollTOTP(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+	email := principal.Email
+
+	enrollment, err := handler.mfaUseCase.EnrollTOTP(ctx.Request.Context(), userID, email)
+
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ResponseData{
+		Status: "success",
+		Data: utils.TOTPEnrollment{
+			Secret:          enrollment.Secret,
+			ProvisioningURI: enrollment.ProvisioningURI,
+		},
+	})
+}
+
+// ConfirmTOTP godoc
+// @Summary			Confirm TOTP enrollment
+// @Description	Enable two-factor authentication with a code from the authenticator app and retrieve recovery codes
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Param				json	body			utils.ConfirmTOTP	true	"Confirm TOTP"
+// @Success			200		{object}	utils.ResponseDataRecoveryCodes
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Failure			409		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/mfa/totp/confirm	[post]
+func (handler *userHandler) ConfirmTOTP(ctx *gin.Context) {
+	var (
+		confirmTOTP utils.ConfirmTOTP
+		err         error
+	)
+
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+
+	if err = ctx.ShouldBindJSON(&confirmTOTP); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	recoveryCodes, err := handler.mfaUseCase.ConfirmTOTP(ctx.Request.Context(), userID, confirmTOTP.Code)
+
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data: utils.RecoveryCodes{
+			RecoveryCodes: recoveryCodes,
+		},
+	})
+}
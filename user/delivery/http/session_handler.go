@@ -0,0 +1,119 @@
+package delivery
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"api-mygram-go/user/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FetchSessions godoc
+// @Summary			Fetch sessions
+// @Description	Get the devices the authentication user is signed in on
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Success			200		{object}	utils.ResponseDataFetchedSessions
+// @Failure			400		{object}	utils.ResponseMessage
+// @Failure			401		{object}	utils.ResponseMessage
+// @Failure			403		{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/sessions	[get]
+func (handler *userHandler) FetchSessions(ctx *gin.Context) {
+	var (
+		sessions []domain.Session
+		err      error
+	)
+
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+	currentID := principal.SessionID
+
+	if err = handler.sessionUseCase.Fetch(ctx.Request.Context(), &sessions, userID); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	fetchedSessions := []utils.Session{}
+
+	for _, session := range sessions {
+		fetchedSessions = append(fetchedSessions, utils.Session{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data:   fetchedSessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary			Revoke a session
+// @Description	Sign the authentication user out of a device. Tokens issued for the session stop working immediately.
+// @Tags				users
+// @Accept			json
+// @Produce			json
+// @Param				sessionId	path			string	true	"Revoke session by id"
+// @Success			200				{object}	utils.ResponseMessageRevokedSession
+// @Failure			400				{object}	utils.ResponseMessage
+// @Failure			401				{object}	utils.ResponseMessage
+// @Failure			403				{object}	utils.ResponseMessage
+// @Failure			404				{object}	utils.ResponseMessage
+// @Security		Bearer
+// @Router			/users/sessions/{sessionId}	[delete]
+func (handler *userHandler) RevokeSession(ctx *gin.Context) {
+	principal, ok := auth.Authenticated(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID := principal.UserID
+	sessionID := ctx.Param("sessionId")
+
+	if err := handler.sessionUseCase.Revoke(ctx.Request.Context(), sessionID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: fmt.Sprintf("active session with id %s doesn't exist", sessionID),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseMessage{
+		Status:  "success",
+		Message: "the session has been successfully signed out",
+	})
+}
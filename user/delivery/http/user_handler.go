@@ -1,31 +1,57 @@
 package delivery
 
 import (
+	"api-mygram-go/auth"
 	"api-mygram-go/domain"
 	"api-mygram-go/helpers"
-	"api-mygram-go/user/delivery/http/middleware"
 	"api-mygram-go/user/utils"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
 type userHandler struct {
-	userUseCase domain.UserUseCase
+	userUseCase       domain.UserUseCase
+	mfaUseCase        domain.MFAUseCase
+	apiKeyUseCase     domain.APIKeyUseCase
+	sessionUseCase    domain.SessionUseCase
+	deletionUseCase   domain.AccountDeletionUseCase
+	dataExportUseCase domain.DataExportUseCase
+	avatarUseCase     domain.AvatarUseCase
+	keySet            *helpers.KeySet
 }
 
-func NewUserHandler(routers *gin.Engine, userUseCase domain.UserUseCase) {
-	handler := &userHandler{userUseCase}
+func NewUserHandler(routers *gin.Engine, userUseCase domain.UserUseCase, mfaUseCase domain.MFAUseCase, apiKeyUseCase domain.APIKeyUseCase, sessionUseCase domain.SessionUseCase, deletionUseCase domain.AccountDeletionUseCase, dataExportUseCase domain.DataExportUseCase, avatarUseCase domain.AvatarUseCase, keySet *helpers.KeySet, authenticator *auth.Authenticator) {
+	handler := &userHandler{userUseCase, mfaUseCase, apiKeyUseCase, sessionUseCase, deletionUseCase, dataExportUseCase, avatarUseCase, keySet}
 
 	router := routers.Group("/users")
 	{
 		router.POST("/register", handler.Register)
 		router.POST("/login", handler.Login)
-		router.PUT("", middleware.Authentication(), handler.Update)
-		router.DELETE("", middleware.Authentication(), handler.Delete)
+		router.POST("/login/mfa", handler.LoginMFA)
+		router.PUT("", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), handler.Update)
+		router.POST("/email/verify", handler.VerifyEmail)
+		router.PUT("/me/avatar", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), handler.SetAvatar)
+		router.DELETE("", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.Delete)
+		router.GET("/deletion", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), handler.GetDeletion)
+		router.DELETE("/deletion", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.CancelDeletion)
+		router.POST("/me/export", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.RequestExport)
+		router.GET("/me/export/:exportId", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.GetExport)
+		router.GET("/me/export/:exportId/download", handler.DownloadExport)
+		router.POST("/mfa/totp", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.EnrollTOTP)
+		router.POST("/mfa/totp/confirm", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.ConfirmTOTP)
+		router.POST("/apikeys", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.CreateAPIKey)
+		router.GET("/apikeys", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.FetchAPIKeys)
+		router.DELETE("/apikeys/:apiKeyId", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.RevokeAPIKey)
+		router.GET("/sessions", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.FetchSessions)
+		router.DELETE("/sessions/:sessionId", authenticator.Authentication(), auth.RequireScope(domain.ScopeUsersWrite), auth.RequireInteractive(), handler.RevokeSession)
 	}
+
+	routers.GET("/avatars/:userId/:file", handler.GetAvatar)
 }
 
 // Register godoc
@@ -55,19 +81,10 @@ func (handler *userHandler) Register(ctx *gin.Context) {
 	}
 
 	if err = handler.userUseCase.Register(ctx.Request.Context(), &user); err != nil {
-		if strings.Contains(err.Error(), "idx_users_username") {
-			ctx.AbortWithStatusJSON(http.StatusConflict, helpers.ResponseMessage{
-				Status:  "fail",
-				Message: "the username you entered has been used",
-			})
-
-			return
-		}
-
-		if strings.Contains(err.Error(), "idx_users_email") {
+		if errors.Is(err, domain.ErrUsernameTaken) || errors.Is(err, domain.ErrEmailTaken) {
 			ctx.AbortWithStatusJSON(http.StatusConflict, helpers.ResponseMessage{
 				Status:  "fail",
-				Message: "the email you entered has been used",
+				Message: err.Error(),
 			})
 
 			return
@@ -100,8 +117,10 @@ func (handler *userHandler) Register(ctx *gin.Context) {
 // @Produce			json
 // @Param				json	body			utils.LoginUser	true	"Login User"
 // @Success			200		{object}	utils.ResponseDataLoggedinUser
+// @Success			202		{object}	utils.ResponseDataMFAChallenge
 // @Failure			400		{object}	utils.ResponseMessage
 // @Failure			401		{object}	utils.ResponseMessage
+// @Failure			429		{object}	utils.ResponseMessage
 // @Router			/users/login		[post]
 func (handler *userHandler) Login(ctx *gin.Context) {
 	var (
@@ -119,8 +138,42 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	if err = handler.userUseCase.Login(ctx.Request.Context(), &user); err != nil {
-		if strings.Contains(err.Error(), "the credential you entered are wrong") {
+	loginInfo := newLoginInfo(ctx)
+
+	if err = handler.userUseCase.Login(ctx.Request.Context(), &user, loginInfo); err != nil {
+		var lockedErr *domain.LoginLockedError
+
+		if errors.As(err, &lockedErr) {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, helpers.ResponseMessage{
+				Status:  "unauthenticated",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	mfaEnabled, err := handler.mfaUseCase.IsEnabled(ctx.Request.Context(), user.ID)
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "unauthenticated",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	if mfaEnabled {
+		if token, err = handler.keySet.GenerateMFAChallengeToken(user.ID, user.Email); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
 				Status:  "unauthenticated",
 				Message: err.Error(),
@@ -129,6 +182,20 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 			return
 		}
 
+		ctx.JSON(http.StatusAccepted, helpers.ResponseData{
+			Status: "mfa_required",
+			Data: utils.MFAChallenge{
+				MFAToken:  token,
+				ExpiresIn: int(helpers.MFAChallengeTokenTTL.Seconds()),
+			},
+		})
+
+		return
+	}
+
+	session, err := handler.sessionUseCase.Start(ctx.Request.Context(), user.ID, loginInfo)
+
+	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
 			Status:  "unauthenticated",
 			Message: err.Error(),
@@ -137,7 +204,7 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	if token = helpers.GenerateToken(user.ID, user.Email); err != nil {
+	if token, err = handler.keySet.GenerateToken(user.ID, user.Email, session.ID); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error":   "unauthenticated",
 			"message": err.Error(),
@@ -154,9 +221,19 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 	})
 }
 
+// newLoginInfo describes the client signing in. Clients may name the device
+// with the X-Device-Name header, otherwise it is derived from the user agent.
+func newLoginInfo(ctx *gin.Context) domain.LoginInfo {
+	return domain.LoginInfo{
+		IPAddress:  ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+		DeviceName: strings.TrimSpace(ctx.GetHeader("X-Device-Name")),
+	}
+}
+
 // Update godoc
 // @Summary			Update a user
-// @Description	Update a user with authentication user
+// @Description	Update the profile of the authentication user, fields left out are kept. A new email address replaces the current one once it is confirmed with the link mailed to it, and can only be set with a login token.
 // @Tags				users
 // @Accept			json
 // @Produce			json
@@ -164,19 +241,23 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 // @Success			200			{object}  utils.ResponseDataUpdatedUser
 // @Failure			400			{object}	utils.ResponseMessage
 // @Failure			401			{object}	utils.ResponseMessage
+// @Failure			403			{object}	utils.ResponseMessage
 // @Failure			409			{object}	utils.ResponseMessage
 // @Security		Bearer
 // @Router			/users	[put]
 func (handler *userHandler) Update(ctx *gin.Context) {
 	var (
-		user domain.User
-		err  error
+		update domain.UserUpdate
+		err    error
 	)
 
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	_ = string(userData["id"].(string))
+	principal, ok := auth.Authenticated(ctx)
 
-	if err = ctx.ShouldBindJSON(&user); err != nil {
+	if !ok {
+		return
+	}
+
+	if err = ctx.ShouldBindJSON(&update); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
 			Status:  "fail",
 			Message: err.Error(),
@@ -185,12 +266,27 @@ func (handler *userHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	updatedUser := domain.User{
-		Username: user.Username,
-		Email:    user.Email,
+	if update.Email != nil && !principal.Interactive() {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, helpers.ResponseMessage{
+			Status:  "forbidden",
+			Message: "changing the email requires a login token, api keys aren't accepted",
+		})
+
+		return
 	}
 
-	if user, err = handler.userUseCase.Update(ctx.Request.Context(), updatedUser); err != nil {
+	user, change, err := handler.userUseCase.Update(ctx.Request.Context(), principal.UserID, update)
+
+	if err != nil {
+		if errors.Is(err, domain.ErrUsernameTaken) || errors.Is(err, domain.ErrEmailTaken) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
 			Status:  "fail",
 			Message: err.Error(),
@@ -199,48 +295,77 @@ func (handler *userHandler) Update(ctx *gin.Context) {
 		return
 	}
 
+	updatedUser := newUpdatedUser(user)
+
+	if change != nil {
+		updatedUser.PendingEmail = change.Email
+		updatedUser.PendingEmailExpiresAt = change.ExpiresAt
+	}
+
 	ctx.JSON(http.StatusOK, helpers.ResponseData{
 		Status: "success",
-		Data: utils.UpdatedUser{
-			ID:        user.ID,
-			Email:     user.Email,
-			Username:  user.Username,
-			Age:       user.Age,
-			UpdatedAt: user.UpdatedAt,
-		},
+		Data:   updatedUser,
 	})
 }
 
-// Delete godoc
-// @Summary			Delete a user
-// @Description	Delete a user with authentication user
+// VerifyEmail godoc
+// @Summary			Verify a new email address
+// @Description	Confirm a new email address with the token of the link mailed to it, which makes it the email of its user
 // @Tags				users
 // @Accept			json
 // @Produce			json
-// @Success			200			{object}	utils.ResponseMessageDeletedUser
+// @Param				json		body			utils.VerifyEmail   true  "Verify Email"
+// @Success			200			{object}  utils.ResponseDataUpdatedUser
 // @Failure			400			{object}	utils.ResponseMessage
-// @Failure			401			{object}	utils.ResponseMessage
-// @Failure			404			{object}	utils.ResponseMessage
-// @Security		Bearer
-// @Router			/users	[delete]
-func (handler *userHandler) Delete(ctx *gin.Context) {
-	userData := ctx.MustGet("userData").(jwt.MapClaims)
-	userID := string(userData["id"].(string))
+// @Failure			409			{object}	utils.ResponseMessage
+// @Router			/users/email/verify	[post]
+func (handler *userHandler) VerifyEmail(ctx *gin.Context) {
+	var request utils.VerifyEmail
 
-	if err := handler.userUseCase.Delete(ctx, userID); err != nil {
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
 			Status:  "fail",
-			Message: "account not found",
+			Message: err.Error(),
 		})
 
 		return
 	}
 
-	ctx.JSON(
-		http.StatusOK,
-		helpers.ResponseMessage{
-			Status:  "success",
-			Message: "your account has been successfully deleted",
-		},
-	)
+	user, err := handler.userUseCase.ConfirmEmail(ctx.Request.Context(), request.Token)
+
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailTaken) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, helpers.ResponseMessage{
+				Status:  "fail",
+				Message: err.Error(),
+			})
+
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, helpers.ResponseMessage{
+			Status:  "fail",
+			Message: err.Error(),
+		})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ResponseData{
+		Status: "success",
+		Data:   newUpdatedUser(user),
+	})
+}
+
+func newUpdatedUser(user domain.User) utils.UpdatedUser {
+	return utils.UpdatedUser{
+		ID:              user.ID,
+		Email:           user.Email,
+		Username:        user.Username,
+		Age:             user.Age,
+		ProfileImageUrl: user.ProfileImageUrl,
+		DisplayName:     user.DisplayName,
+		Bio:             user.Bio,
+		UpdatedAt:       user.UpdatedAt,
+	}
 }
@@ -0,0 +1,613 @@
+package delivery_test
+
+import (
+	"api-mygram-go/auth"
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	userDelivery "api-mygram-go/user/delivery/http"
+	userUseCase "api-mygram-go/user/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyCredentials = "apikey"
+	tokenCredentials  = "token"
+)
+
+var keySet = fakes.KeySet()
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestUserHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		credentials string
+		scopes      []string
+		users       fakes.UserUseCase
+		mfa         fakes.MFAUseCase
+		apiKeys     fakes.APIKeyUseCase
+		sessions    fakes.SessionUseCase
+		deletions   fakes.AccountDeletionUseCase
+		exports     fakes.DataExportUseCase
+		avatars     fakes.AvatarUseCase
+		wantStatus  int
+		wantBody    string
+		wantHeader  map[string]string
+	}{
+		{
+			name:   "register",
+			method: http.MethodPost,
+			path:   "/users/register",
+			body:   `{"username":"johndoe","email":"johndoe@example.com","password":"secret","age":20}`,
+			users: fakes.UserUseCase{RegisterFunc: func(ctx context.Context, user *domain.User) error {
+				user.ID = "user-1"
+
+				return nil
+			}},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"id":"user-1"`,
+		},
+		{
+			name:   "register a taken username",
+			method: http.MethodPost,
+			path:   "/users/register",
+			body:   `{"username":"johndoe","email":"johndoe@example.com","password":"secret","age":20}`,
+			users: fakes.UserUseCase{RegisterFunc: func(ctx context.Context, user *domain.User) error {
+				return fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe"}).Register(ctx, user)
+			}},
+			wantStatus: http.StatusConflict,
+			wantBody:   "the username you entered has been used",
+		},
+		{
+			name:       "register malformed json",
+			method:     http.MethodPost,
+			path:       "/users/register",
+			body:       `{"age":"twenty"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "login",
+			method: http.MethodPost,
+			path:   "/users/login",
+			body:   `{"email":"johndoe@example.com","password":"secret"}`,
+			users: fakes.UserUseCase{LoginFunc: func(ctx context.Context, user *domain.User, info domain.LoginInfo) error {
+				user.ID = "user-1"
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"token":"`,
+		},
+		{
+			name:   "login with a wrong password",
+			method: http.MethodPost,
+			path:   "/users/login",
+			body:   `{"email":"johndoe@example.com","password":"wrong"}`,
+			users: fakes.UserUseCase{LoginFunc: func(context.Context, *domain.User, domain.LoginInfo) error {
+				return domain.ErrInvalidCredentials
+			}},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   domain.ErrInvalidCredentials.Error(),
+		},
+		{
+			name:   "login while locked out",
+			method: http.MethodPost,
+			path:   "/users/login",
+			body:   `{"email":"johndoe@example.com","password":"secret"}`,
+			users: fakes.UserUseCase{LoginFunc: func(context.Context, *domain.User, domain.LoginInfo) error {
+				return &domain.LoginLockedError{RetryAfter: 90 * time.Second}
+			}},
+			wantStatus: http.StatusTooManyRequests,
+			wantHeader: map[string]string{"Retry-After": "90"},
+		},
+		{
+			name:   "login with two-factor authentication",
+			method: http.MethodPost,
+			path:   "/users/login",
+			body:   `{"email":"johndoe@example.com","password":"secret"}`,
+			users: fakes.UserUseCase{LoginFunc: func(ctx context.Context, user *domain.User, info domain.LoginInfo) error {
+				user.ID = "user-1"
+
+				return nil
+			}},
+			mfa: fakes.MFAUseCase{IsEnabledFunc: func(context.Context, string) (bool, error) {
+				return true, nil
+			}},
+			wantStatus: http.StatusAccepted,
+			wantBody:   `"status":"mfa_required"`,
+		},
+		{
+			name:       "login mfa with a forged challenge",
+			method:     http.MethodPost,
+			path:       "/users/login/mfa",
+			body:       `{"mfa_token":"forged","code":"123456"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "update without credentials",
+			method:     http.MethodPut,
+			path:       "/users",
+			body:       `{"username":"janedoe"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "update",
+			method:      http.MethodPut,
+			path:        "/users",
+			body:        `{"username":"janedoe","email":"janedoe@example.com"}`,
+			credentials: tokenCredentials,
+			users: fakes.UserUseCase{UpdateFunc: func(ctx context.Context, userID string, update domain.UserUpdate) (domain.User, *domain.EmailChange, error) {
+				if userID != "user-1" {
+					return domain.User{}, nil, gorm.ErrRecordNotFound
+				}
+
+				return domain.User{ID: userID, Username: *update.Username, Email: "johndoe@example.com"}, &domain.EmailChange{Email: *update.Email}, nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"email":"johndoe@example.com","pending_email":"janedoe@example.com"`,
+		},
+		{
+			name:        "update to a taken username",
+			method:      http.MethodPut,
+			path:        "/users",
+			body:        `{"username":"janedoe"}`,
+			credentials: tokenCredentials,
+			users: fakes.UserUseCase{UpdateFunc: func(context.Context, string, domain.UserUpdate) (domain.User, *domain.EmailChange, error) {
+				return domain.User{}, nil, domain.ErrUsernameTaken
+			}},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:        "update the email with an api key",
+			method:      http.MethodPut,
+			path:        "/users",
+			body:        `{"email":"janedoe@example.com"}`,
+			credentials: apiKeyCredentials,
+			scopes:      []string{domain.ScopeUsersWrite},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:   "verify an email",
+			method: http.MethodPost,
+			path:   "/users/email/verify",
+			body:   `{"token":"the-token"}`,
+			users: fakes.UserUseCase{ConfirmEmailFunc: func(ctx context.Context, token string) (domain.User, error) {
+				if token != "the-token" {
+					return domain.User{}, domain.ErrEmailChangeInvalid
+				}
+
+				return domain.User{ID: "user-1", Email: "janedoe@example.com"}, nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"email":"janedoe@example.com"`,
+		},
+		{
+			name:   "verify an email with an expired link",
+			method: http.MethodPost,
+			path:   "/users/email/verify",
+			body:   `{"token":"expired"}`,
+			users: fakes.UserUseCase{ConfirmEmailFunc: func(context.Context, string) (domain.User, error) {
+				return domain.User{}, domain.ErrEmailChangeInvalid
+			}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "update with an api key lacking users:write",
+			method:      http.MethodPut,
+			path:        "/users",
+			body:        `{"username":"janedoe"}`,
+			credentials: apiKeyCredentials,
+			scopes:      []string{domain.ScopePhotosWrite},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "delete",
+			method:      http.MethodDelete,
+			path:        "/users",
+			credentials: tokenCredentials,
+			deletions: fakes.AccountDeletionUseCase{ScheduleFunc: func(ctx context.Context, userID string) (domain.AccountDeletion, error) {
+				if userID != "user-1" {
+					return domain.AccountDeletion{}, gorm.ErrRecordNotFound
+				}
+
+				purgeAfter := time.Date(2030, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+				return domain.AccountDeletion{ID: "deletion-1", UserID: userID, PurgeAfter: &purgeAfter}, nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   "will be deleted after 2030-01-31 12:00 UTC",
+		},
+		{
+			name:        "delete with an api key",
+			method:      http.MethodDelete,
+			path:        "/users",
+			credentials: apiKeyCredentials,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "get the pending deletion",
+			method:      http.MethodGet,
+			path:        "/users/deletion",
+			credentials: tokenCredentials,
+			deletions: fakes.AccountDeletionUseCase{GetPendingFunc: func(ctx context.Context, deletion *domain.AccountDeletion, userID string) error {
+				deletion.ID = "deletion-1"
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"id":"deletion-1"`,
+		},
+		{
+			name:        "cancel a deletion that isn't pending",
+			method:      http.MethodDelete,
+			path:        "/users/deletion",
+			credentials: tokenCredentials,
+			deletions: fakes.AccountDeletionUseCase{CancelFunc: func(context.Context, string) error {
+				return gorm.ErrRecordNotFound
+			}},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "isn't scheduled for deletion",
+		},
+		{
+			name:        "cancel a deletion with an api key",
+			method:      http.MethodDelete,
+			path:        "/users/deletion",
+			credentials: apiKeyCredentials,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "request an export",
+			method:      http.MethodPost,
+			path:        "/users/me/export",
+			credentials: tokenCredentials,
+			wantStatus:  http.StatusAccepted,
+			wantBody:    `"status":"pending"`,
+		},
+		{
+			name:        "get a ready export",
+			method:      http.MethodGet,
+			path:        "/users/me/export/export-1",
+			credentials: tokenCredentials,
+			exports: fakes.DataExportUseCase{
+				GetByIDFunc: func(ctx context.Context, export *domain.DataExport, id string, userID string) error {
+					*export = domain.DataExport{ID: id, UserID: userID, Status: domain.DataExportReady}
+
+					return nil
+				},
+				LinkFunc: func(domain.DataExport) domain.DataExportLink {
+					return domain.DataExportLink{Expires: time.Unix(1893456000, 0), Signature: "abc"}
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"download_url":"/users/me/export/export-1/download?expires=1893456000\u0026signature=abc"`,
+		},
+		{
+			name:        "request an export with an api key",
+			method:      http.MethodPost,
+			path:        "/users/me/export",
+			credentials: apiKeyCredentials,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:       "download an export with a forged link",
+			method:     http.MethodGet,
+			path:       "/users/me/export/export-1/download?expires=1893456000&signature=forged",
+			wantStatus: http.StatusForbidden,
+			wantBody:   domain.ErrExportLinkInvalid.Error(),
+		},
+		{
+			name:   "download an export",
+			method: http.MethodGet,
+			path:   "/users/me/export/export-1/download?expires=1893456000&signature=abc",
+			exports: fakes.DataExportUseCase{OpenFunc: func(context.Context, string, domain.DataExportLink) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("PK")), nil
+			}},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"Content-Type": "application/zip"},
+		},
+		{
+			name:        "enroll totp twice",
+			method:      http.MethodPost,
+			path:        "/users/mfa/totp",
+			credentials: tokenCredentials,
+			mfa: fakes.MFAUseCase{EnrollTOTPFunc: func(context.Context, string, string) (domain.TOTPEnrollment, error) {
+				return domain.TOTPEnrollment{}, domain.ErrMFAAlreadyEnabled
+			}},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:        "enroll totp with an api key",
+			method:      http.MethodPost,
+			path:        "/users/mfa/totp",
+			credentials: apiKeyCredentials,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "confirm totp with an api key",
+			method:      http.MethodPost,
+			path:        "/users/mfa/totp/confirm",
+			body:        `{"code":"123456"}`,
+			credentials: apiKeyCredentials,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "create an api key",
+			method:      http.MethodPost,
+			path:        "/users/apikeys",
+			body:        `{"name":"deploy script","scopes":["photos:read"]}`,
+			credentials: tokenCredentials,
+			apiKeys: fakes.APIKeyUseCase{CreateFunc: func(ctx context.Context, apiKey *domain.APIKey, scopes []string) (string, error) {
+				apiKey.ID = "apikey-1"
+				apiKey.Scopes = strings.Join(scopes, " ")
+
+				return "mygram_1a2b3c4d_secret", nil
+			}},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"key":"mygram_1a2b3c4d_secret"`,
+		},
+		{
+			name:        "create an api key with an api key",
+			method:      http.MethodPost,
+			path:        "/users/apikeys",
+			body:        `{"name":"deploy script","scopes":["photos:read"]}`,
+			credentials: apiKeyCredentials,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "revoke a missing api key",
+			method:      http.MethodDelete,
+			path:        "/users/apikeys/apikey-9",
+			credentials: tokenCredentials,
+			apiKeys: fakes.APIKeyUseCase{RevokeFunc: func(context.Context, string, string) error {
+				return gorm.ErrRecordNotFound
+			}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "fetch sessions marks the current one",
+			method:      http.MethodGet,
+			path:        "/users/sessions",
+			credentials: tokenCredentials,
+			sessions: fakes.SessionUseCase{FetchFunc: func(ctx context.Context, sessions *[]domain.Session, userID string) error {
+				*sessions = []domain.Session{{ID: "session-test", UserID: userID}}
+
+				return nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"current":true`,
+		},
+		{
+			name:        "revoke a session",
+			method:      http.MethodDelete,
+			path:        "/users/sessions/session-2",
+			credentials: tokenCredentials,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:   "get an avatar",
+			method: http.MethodGet,
+			path:   "/avatars/user-1/abcdefghijkl-64.jpg",
+			avatars: fakes.AvatarUseCase{OpenFunc: func(ctx context.Context, userID string, file string) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("jpeg")), nil
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   "jpeg",
+			wantHeader: map[string]string{"Content-Type": "image/jpeg", "Cache-Control": "public, max-age=31536000, immutable"},
+		},
+		{
+			name:       "get a missing avatar",
+			method:     http.MethodGet,
+			path:       "/avatars/user-1/abcdefghijkl-64.jpg",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routers := gin.New()
+			users, mfa, apiKeys, sessions, deletions, exports, avatars := test.users, test.mfa, test.apiKeys, test.sessions, test.deletions, test.exports, test.avatars
+			scopes := test.scopes
+
+			if scopes == nil {
+				scopes = []string{domain.ScopeUsersWrite}
+			}
+
+			apiKeys.AuthenticateFunc = fakes.AuthenticatingAPIKeys("user-1", scopes...).AuthenticateFunc
+
+			userDelivery.NewUserHandler(routers, &users, &mfa, &apiKeys, &sessions, &deletions, &exports, &avatars, keySet, auth.NewAuthenticator(keySet, &apiKeys, &sessions))
+
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			switch test.credentials {
+			case apiKeyCredentials:
+				request.Header.Set("X-API-Key", "mygram_test")
+			case tokenCredentials:
+				token, err := keySet.GenerateToken("user-1", "johndoe@example.com", "session-test")
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				request.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			recorder := httptest.NewRecorder()
+			routers.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+
+			if !strings.Contains(recorder.Body.String(), test.wantBody) {
+				t.Errorf("body %s doesn't contain %s", recorder.Body, test.wantBody)
+			}
+
+			for key, value := range test.wantHeader {
+				if got := recorder.Header().Get(key); got != value {
+					t.Errorf("header %s = %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestUserHandlerRejectsRevokedSessions(t *testing.T) {
+	routers := gin.New()
+	sessions := fakes.SessionUseCase{ValidateFunc: func(context.Context, string) error {
+		return domain.ErrSessionRevoked
+	}}
+
+	userDelivery.NewUserHandler(routers, &fakes.UserUseCase{}, &fakes.MFAUseCase{}, &fakes.APIKeyUseCase{}, &sessions, &fakes.AccountDeletionUseCase{}, &fakes.DataExportUseCase{}, &fakes.AvatarUseCase{}, keySet, auth.NewAuthenticator(keySet, &fakes.APIKeyUseCase{}, &sessions))
+
+	token, err := keySet.GenerateToken("user-1", "johndoe@example.com", "session-test")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/users/sessions", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	routers.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestUserHandlerRejectsTokensWithoutASession(t *testing.T) {
+	routers := gin.New()
+	sessions := fakes.SessionUseCase{}
+
+	userDelivery.NewUserHandler(routers, &fakes.UserUseCase{}, &fakes.MFAUseCase{}, &fakes.APIKeyUseCase{}, &sessions, &fakes.AccountDeletionUseCase{}, &fakes.DataExportUseCase{}, &fakes.AvatarUseCase{}, keySet, auth.NewAuthenticator(keySet, &fakes.APIKeyUseCase{}, &sessions))
+
+	token, err := keySet.Sign(jwt.MapClaims{"id": "user-1", "email": "johndoe@example.com", "sub": "user-1", "aud": keySet.Options.Audience})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/users/sessions", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	routers.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestUserHandlerSetAvatar(t *testing.T) {
+	users := fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20})
+	avatars := userUseCase.NewAvatarUseCase(users, fakes.NewBlobStore())
+	apiKeys := fakes.AuthenticatingAPIKeys("user-1", domain.ScopeUsersWrite)
+	routers := gin.New()
+
+	userDelivery.NewUserHandler(routers, &fakes.UserUseCase{}, &fakes.MFAUseCase{}, apiKeys, &fakes.SessionUseCase{}, &fakes.AccountDeletionUseCase{}, &fakes.DataExportUseCase{}, avatars, keySet, auth.NewAuthenticator(keySet, apiKeys, &fakes.SessionUseCase{}))
+
+	upload := func(field string, content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile(field, "avatar.png")
+		part.Write(content)
+		form.Close()
+
+		request := httptest.NewRequest(http.MethodPut, "/users/me/avatar", &body)
+		request.Header.Set("Content-Type", form.FormDataContentType())
+		request.Header.Set("X-API-Key", "mygram_test")
+
+		recorder := httptest.NewRecorder()
+		routers.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	var image bytes.Buffer
+
+	if err := png.Encode(&image, imageOfSize(300, 200)); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := upload("avatar", image.Bytes())
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+
+	var response struct {
+		Data struct {
+			ProfileImageUrl string            `json:"profileImageUrl"`
+			Sizes           map[string]string `json:"sizes"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Data.Sizes) != len(domain.AvatarSizes) || response.Data.Sizes["256"] != response.Data.ProfileImageUrl {
+		t.Fatalf("avatar = %+v, want every size with the 256 one as the profile image", response.Data)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, response.Data.Sizes["64"], nil)
+	recorder = httptest.NewRecorder()
+	routers.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("getting %s returned %d %s", response.Data.Sizes["64"], recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	if avatar, err := jpeg.DecodeConfig(recorder.Body); err != nil || avatar.Width != 64 || avatar.Height != 64 {
+		t.Errorf("the 64 pixels avatar is %dx%d (%v)", avatar.Width, avatar.Height, err)
+	}
+
+	if recorder = upload("avatar", []byte("not an image")); recorder.Code != http.StatusBadRequest {
+		t.Errorf("uploading a text file returned %d, want 400", recorder.Code)
+	}
+
+	if recorder = upload("picture", image.Bytes()); recorder.Code != http.StatusBadRequest {
+		t.Errorf("uploading under another field returned %d, want 400", recorder.Code)
+	}
+
+	if recorder = upload("avatar", make([]byte, 11<<20)); recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("uploading 11 MB returned %d, want 413", recorder.Code)
+	}
+}
+
+func imageOfSize(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+
+	return img
+}
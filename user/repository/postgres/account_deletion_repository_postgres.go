@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type accountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) *accountDeletionRepository {
+	return &accountDeletionRepository{db}
+}
+
+func (accountDeletionRepository *accountDeletionRepository) Store(ctx context.Context, deletion *domain.AccountDeletion) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	deletion.ID = fmt.Sprintf("deletion-%s", ID)
+
+	if err = database.FromContext(ctx, accountDeletionRepository.db).Create(&deletion).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (accountDeletionRepository *accountDeletionRepository) GetPending(ctx context.Context, deletion *domain.AccountDeletion, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, accountDeletionRepository.db).
+		Where("user_id = ? AND canceled_at IS NULL AND completed_at IS NULL", userID).
+		Take(&deletion).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (accountDeletionRepository *accountDeletionRepository) FetchDue(ctx context.Context, deletions *[]domain.AccountDeletion, now time.Time, limit int) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, accountDeletionRepository.db).
+		Where("canceled_at IS NULL AND completed_at IS NULL AND purge_after <= ?", now).
+		Order("purge_after").
+		Limit(limit).
+		Find(&deletions).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (accountDeletionRepository *accountDeletionRepository) Cancel(ctx context.Context, id string, canceledAt time.Time) (err error) {
+	return accountDeletionRepository.close(ctx, id, "canceled_at", canceledAt)
+}
+
+func (accountDeletionRepository *accountDeletionRepository) Complete(ctx context.Context, id string, completedAt time.Time) (err error) {
+	return accountDeletionRepository.close(ctx, id, "completed_at", completedAt)
+}
+
+// close sets column on a pending deletion, so a deletion can't be both
+// canceled and completed.
+func (accountDeletionRepository *accountDeletionRepository) close(ctx context.Context, id string, column string, at time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, accountDeletionRepository.db).Model(&domain.AccountDeletion{}).
+		Where("id = ? AND canceled_at IS NULL AND completed_at IS NULL", id).
+		Update(column, at)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+// PurgeContent hands the user's comments on other people's photos to the
+// ghost user, then deletes their remaining comments, the comments on their
+// photos and the photos themselves, along with their login history and
+// failed login count.
+func (accountDeletionRepository *accountDeletionRepository) PurgeContent(ctx context.Context, userID string) (purge domain.AccountPurge, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+	defer cancel()
+
+	db := database.FromContext(ctx, accountDeletionRepository.db)
+
+	if err = accountDeletionRepository.ensureGhost(ctx); err != nil {
+		return purge, err
+	}
+
+	ownPhotos := db.Model(&domain.Photo{}).Select("id").Where("user_id = ?", userID)
+
+	// UpdateColumn skips the hooks, which would validate the empty model.
+	result := db.Model(&domain.Comment{}).
+		Where("user_id = ? AND photo_id NOT IN (?)", userID, ownPhotos).
+		UpdateColumn("user_id", domain.GhostUserID)
+
+	if result.Error != nil {
+		return purge, result.Error
+	}
+
+	purge.AnonymizedComments = result.RowsAffected
+
+	if result = db.Where("user_id = ? OR photo_id IN (?)", userID, ownPhotos).Delete(&domain.Comment{}); result.Error != nil {
+		return purge, result.Error
+	}
+
+	purge.Comments = result.RowsAffected
+
+	if result = db.Where("user_id = ?", userID).Delete(&domain.Photo{}); result.Error != nil {
+		return purge, result.Error
+	}
+
+	purge.Photos = result.RowsAffected
+
+	user := domain.User{}
+
+	if err = db.Select("email").Take(&user, "id = ?", userID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return purge, err
+	}
+
+	events := db.Where("user_id = ?", userID)
+
+	if user.Email != "" {
+		events = events.Or("email = ?", strings.ToLower(user.Email))
+
+		if err = db.Where("key = ?", domain.EmailThrottleKey(user.Email)).Delete(&domain.LoginThrottle{}).Error; err != nil {
+			return purge, err
+		}
+	}
+
+	if result = events.Delete(&domain.LoginEvent{}); result.Error != nil {
+		return purge, result.Error
+	}
+
+	purge.LoginEvents = result.RowsAffected
+
+	return purge, nil
+}
+
+// ensureGhost creates the ghost user the first time an account is purged.
+// It skips the validation of users: its email isn't an address, so no
+// account can hold it, and its username is reserved. An account that took
+// the username before it was reserved gets the ghost a suffixed one. Its
+// password isn't the hash of any password, so nobody can sign in as it.
+func (accountDeletionRepository *accountDeletionRepository) ensureGhost(ctx context.Context) (err error) {
+	db := database.FromContext(ctx, accountDeletionRepository.db)
+
+	if err = db.Select("id").Take(&domain.User{}, "id = ?", domain.GhostUserID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	ghost := domain.User{
+		ID:       domain.GhostUserID,
+		Username: domain.GhostUsername,
+		Email:    domain.GhostUsername,
+		Password: "!",
+		Age:      8,
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			suffix, _ := gonanoid.Generate("abcdefghijklmnopqrstuvwxyz0123456789", 8)
+			ghost.Username = domain.GhostUsername + "-" + suffix
+		}
+
+		// Creating in a savepoint keeps the transaction usable on postgres
+		// when the username is taken.
+		err = db.Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&ghost).Error
+		})
+
+		if !database.UniqueViolation(err, "users", "username") {
+			return err
+		}
+	}
+
+	return err
+}
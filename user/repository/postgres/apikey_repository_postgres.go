@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *apiKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (apiKeyRepository *apiKeyRepository) Store(ctx context.Context, apiKey *domain.APIKey) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	apiKey.ID = fmt.Sprintf("apikey-%s", ID)
+
+	if err = database.FromContext(ctx, apiKeyRepository.db).Create(&apiKey).Error; err != nil {
+		if database.UniqueViolation(err, "api_keys", "prefix") {
+			return domain.ErrAPIKeyPrefixTaken
+		}
+
+		return err
+	}
+
+	return
+}
+
+func (apiKeyRepository *apiKeyRepository) Fetch(ctx context.Context, apiKeys *[]domain.APIKey, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, apiKeyRepository.db).Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (apiKeyRepository *apiKeyRepository) GetByPrefix(ctx context.Context, apiKey *domain.APIKey, prefix string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, apiKeyRepository.db).Where("prefix = ?", prefix).Take(&apiKey).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (apiKeyRepository *apiKeyRepository) Revoke(ctx context.Context, id string, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, apiKeyRepository.db).Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+func (apiKeyRepository *apiKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, apiKeyRepository.db).Model(&domain.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error; err != nil {
+		return err
+	}
+
+	return
+}
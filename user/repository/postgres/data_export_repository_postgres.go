@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) *dataExportRepository {
+	return &dataExportRepository{db}
+}
+
+func (dataExportRepository *dataExportRepository) Store(ctx context.Context, export *domain.DataExport) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	export.ID = fmt.Sprintf("export-%s", ID)
+
+	if err = database.FromContext(ctx, dataExportRepository.db).Create(&export).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (dataExportRepository *dataExportRepository) GetByID(ctx context.Context, export *domain.DataExport, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, dataExportRepository.db).Where("id = ?", id).Take(&export).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// GetActive returns the export of the user that is waiting or being
+// assembled.
+func (dataExportRepository *dataExportRepository) GetActive(ctx context.Context, export *domain.DataExport, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, dataExportRepository.db).
+		Where("user_id = ? AND status IN ?", userID, []string{domain.DataExportPending, domain.DataExportRunning}).
+		Take(&export).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (dataExportRepository *dataExportRepository) FetchPending(ctx context.Context, exports *[]domain.DataExport, limit int) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, dataExportRepository.db).
+		Where("status = ?", domain.DataExportPending).
+		Order("created_at").
+		Limit(limit).
+		Find(&exports).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (dataExportRepository *dataExportRepository) FetchExpired(ctx context.Context, exports *[]domain.DataExport, now time.Time, limit int) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, dataExportRepository.db).
+		Where("status = ? AND expires_at <= ?", domain.DataExportReady, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&exports).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// FetchStale lists the running exports claimed before the given time, or
+// never recorded as claimed.
+func (dataExportRepository *dataExportRepository) FetchStale(ctx context.Context, exports *[]domain.DataExport, before time.Time, limit int) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, dataExportRepository.db).
+		Where("status = ? AND (claimed_at IS NULL OR claimed_at < ?)", domain.DataExportRunning, before).
+		Order("created_at").
+		Limit(limit).
+		Find(&exports).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// Claim marks a pending export as running since at, so only one worker
+// assembles it.
+func (dataExportRepository *dataExportRepository) Claim(ctx context.Context, id string, at time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, dataExportRepository.db).Model(&domain.DataExport{}).
+		Where("id = ? AND status = ?", id, domain.DataExportPending).
+		Updates(map[string]any{"status": domain.DataExportRunning, "claimed_at": at})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+func (dataExportRepository *dataExportRepository) Update(ctx context.Context, export domain.DataExport) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, dataExportRepository.db).Model(&domain.DataExport{ID: export.ID}).
+		Select("status", "blob_key", "error", "completed_at", "expires_at").
+		Updates(export).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// Collect loads the profile of the user with their photos, the comments they
+// wrote and their social medias.
+func (dataExportRepository *dataExportRepository) Collect(ctx context.Context, userID string) (content domain.DataExportContent, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+
+	defer cancel()
+
+	db := database.FromContext(ctx, dataExportRepository.db)
+
+	if err = db.Where("id = ?", userID).Take(&content.User).Error; err != nil {
+		return content, err
+	}
+
+	if err = db.Where("user_id = ?", userID).Order("created_at").Find(&content.Photos).Error; err != nil {
+		return content, err
+	}
+
+	if err = db.Where("user_id = ?", userID).Order("created_at").Find(&content.Comments).Error; err != nil {
+		return content, err
+	}
+
+	if err = db.Where("user_id = ?", userID).Find(&content.SocialMedias).Error; err != nil {
+		return content, err
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type emailChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) *emailChangeRepository {
+	return &emailChangeRepository{db}
+}
+
+// Store records change and drops the unconfirmed changes the user asked for
+// before, so only the latest link works.
+func (emailChangeRepository *emailChangeRepository) Store(ctx context.Context, change *domain.EmailChange) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	change.ID = fmt.Sprintf("email-%s", ID)
+
+	return database.FromContext(ctx, emailChangeRepository.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", change.UserID).Delete(&domain.EmailChange{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&change).Error
+	})
+}
+
+// GetPending returns the unconfirmed change with tokenHash.
+func (emailChangeRepository *emailChangeRepository) GetPending(ctx context.Context, change *domain.EmailChange, tokenHash string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, emailChangeRepository.db).
+		Where("token_hash = ? AND confirmed_at IS NULL", tokenHash).
+		Take(&change).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (emailChangeRepository *emailChangeRepository) Confirm(ctx context.Context, id string, at time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, emailChangeRepository.db).
+		Model(&domain.EmailChange{}).
+		Where("id = ? AND confirmed_at IS NULL", id).
+		Update("confirmed_at", at)
+
+	if err = result.Error; err != nil {
+		return err
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *loginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (loginAttemptRepository *loginAttemptRepository) GetThrottle(ctx context.Context, throttle *domain.LoginThrottle, key string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, loginAttemptRepository.db).Where("key = ?", key).Take(&throttle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			*throttle = domain.LoginThrottle{Key: key}
+
+			return nil
+		}
+
+		return err
+	}
+
+	return
+}
+
+func (loginAttemptRepository *loginAttemptRepository) RecordFailure(ctx context.Context, key string) (failures int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	throttle := domain.LoginThrottle{Key: key, Failures: 1}
+
+	if err = database.FromContext(ctx, loginAttemptRepository.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":   gorm.Expr("login_throttles.failures + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&throttle).Error; err != nil {
+		return 0, err
+	}
+
+	if err = database.FromContext(ctx, loginAttemptRepository.db).Where("key = ?", key).Take(&throttle).Error; err != nil {
+		return 0, err
+	}
+
+	return throttle.Failures, nil
+}
+
+func (loginAttemptRepository *loginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, loginAttemptRepository.db).Model(&domain.LoginThrottle{}).Where("key = ?", key).Update("locked_until", until).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (loginAttemptRepository *loginAttemptRepository) Reset(ctx context.Context, key string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, loginAttemptRepository.db).Where("key = ?", key).Delete(&domain.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (loginAttemptRepository *loginAttemptRepository) StoreEvent(ctx context.Context, event *domain.LoginEvent) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	event.ID = fmt.Sprintf("loginevent-%s", ID)
+
+	if err = database.FromContext(ctx, loginAttemptRepository.db).Create(&event).Error; err != nil {
+		return err
+	}
+
+	return
+}
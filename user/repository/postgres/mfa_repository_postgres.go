@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) *mfaRepository {
+	return &mfaRepository{db}
+}
+
+func (mfaRepository *mfaRepository) GetByUserID(ctx context.Context, mfa *domain.UserMFA, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, mfaRepository.db).Where("user_id = ?", userID).Take(&mfa).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (mfaRepository *mfaRepository) Save(ctx context.Context, mfa *domain.UserMFA) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, mfaRepository.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"totp_secret", "enabled", "last_used_step", "confirmed_at", "updated_at"}),
+	}).Create(&mfa).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// UseStep records step as the last accepted TOTP step. It reports false when
+// the step, or a later one, was already used.
+func (mfaRepository *mfaRepository) UseStep(ctx context.Context, userID string, step int64) (used bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, mfaRepository.db).Model(&domain.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (mfaRepository *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []domain.RecoveryCode) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	for i := range codes {
+		ID, _ := gonanoid.New(16)
+
+		codes[i].ID = fmt.Sprintf("recoverycode-%s", ID)
+		codes[i].UserID = userID
+	}
+
+	return database.FromContext(ctx, mfaRepository.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (mfaRepository *mfaRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (used bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, mfaRepository.db).Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,527 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/config/database/databasetest"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	userRepository "api-mygram-go/user/repository/postgres"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	databasetest.Main(m)
+}
+
+func register(t *testing.T, db *gorm.DB, username string) domain.User {
+	t.Helper()
+
+	user := domain.User{Username: username, Email: username + "@example.com", Password: "secret", Age: 20}
+
+	if err := userRepository.NewUserRepository(db, helpers.NewBcryptHasher(4)).Register(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+func TestUserRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewUserRepository(db, helpers.NewBcryptHasher(4))
+	user := register(t, db, "johndoe")
+
+	if !strings.HasPrefix(user.ID, "user-") {
+		t.Errorf("id %q doesn't have the user- prefix", user.ID)
+	}
+
+	if user.Password == "secret" {
+		t.Error("password was stored in plain text")
+	}
+
+	duplicate := domain.User{Username: "johndoe", Email: "other@example.com", Password: "secret", Age: 20}
+
+	if err := repository.Register(ctx, &duplicate); !errors.Is(err, domain.ErrUsernameTaken) {
+		t.Errorf("registering a taken username returned %v", err)
+	}
+
+	duplicate = domain.User{Username: "janedoe", Email: "JohnDoe@Example.com", Password: "secret", Age: 20}
+
+	if err := repository.Register(ctx, &duplicate); !errors.Is(err, domain.ErrEmailTaken) {
+		t.Errorf("registering a taken email in other case returned %v", err)
+	}
+
+	login := domain.User{Email: "johndoe@example.com", Password: "secret"}
+
+	if err := repository.Login(ctx, &login); err != nil || login.ID != user.ID {
+		t.Errorf("login returned %v for %q", err, login.ID)
+	}
+
+	if err := repository.Login(ctx, &domain.User{Email: " JohnDoe@Example.com ", Password: "secret"}); err != nil {
+		t.Errorf("login is case sensitive: %v", err)
+	}
+
+	if err := repository.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "wrong"}); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Errorf("login with a wrong password returned %v", err)
+	}
+
+	var byEmail domain.User
+
+	if err := repository.GetByEmail(ctx, &byEmail, "JohnDoe@Example.com"); err != nil || byEmail.ID != user.ID {
+		t.Errorf("GetByEmail is case sensitive: %v", err)
+	}
+
+	if err := db.Create(&domain.SocialMedia{Name: "GitHub", SocialMediaUrl: "https://github.com/johndoe", UserID: user.ID, ID: "socialmedia-1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.GetByID(ctx, &domain.User{}, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleted user is still found: %v", err)
+	}
+
+	var socialMedias int64
+
+	db.Model(&domain.SocialMedia{}).Where("user_id = ?", user.ID).Count(&socialMedias)
+
+	if socialMedias != 0 {
+		t.Errorf("%d social medias of the deleted user remain", socialMedias)
+	}
+}
+
+func TestUserRepositoryUpdatesTheGivenUser(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewUserRepository(db, helpers.NewBcryptHasher(4))
+	first := register(t, db, "johndoe")
+	second := register(t, db, "janedoe")
+
+	second.Username = "janedoe_2"
+	second.Bio = "Sunsets"
+
+	updated, err := repository.Update(ctx, second)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.ID != second.ID || updated.Username != "janedoe_2" {
+		t.Errorf("updated user = %+v", updated)
+	}
+
+	var stored domain.User
+
+	if err = repository.GetByID(ctx, &stored, first.ID); err != nil || stored.Username != "johndoe" {
+		t.Errorf("the other user was changed to %q: %v", stored.Username, err)
+	}
+
+	second.Bio = ""
+
+	if _, err = repository.Update(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	stored = domain.User{}
+
+	if err = repository.GetByID(ctx, &stored, second.ID); err != nil || stored.Bio != "" || stored.Username != "janedoe_2" {
+		t.Errorf("stored user = %+v (%v), want the bio cleared", stored, err)
+	}
+
+	second.Username = "johndoe"
+
+	if _, err = repository.Update(ctx, second); !errors.Is(err, domain.ErrUsernameTaken) {
+		t.Errorf("updating to a taken username returned %v", err)
+	}
+
+	if _, err = repository.Update(ctx, domain.User{ID: "user-missing", Username: "ghost", Email: "ghost@example.com", Password: "secret", Age: 20}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("updating a missing user returned %v", err)
+	}
+}
+
+func TestEmailChangeRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewEmailChangeRepository(db)
+	user := register(t, db, "johndoe")
+	expiresAt := time.Now().Add(time.Hour)
+
+	first := domain.EmailChange{UserID: user.ID, Email: "johnny@example.com", TokenHash: "first", ExpiresAt: &expiresAt}
+	second := domain.EmailChange{UserID: user.ID, Email: "john@example.com", TokenHash: "second", ExpiresAt: &expiresAt}
+
+	for _, change := range []*domain.EmailChange{&first, &second} {
+		if err := repository.Store(ctx, change); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repository.Store(ctx, &domain.EmailChange{UserID: user.ID, Email: "not an email", TokenHash: "third", ExpiresAt: &expiresAt}); err == nil {
+		t.Error("an invalid email address was stored")
+	}
+
+	if err := repository.GetPending(ctx, &domain.EmailChange{}, "first"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("a replaced change is still pending: %v", err)
+	}
+
+	var pending domain.EmailChange
+
+	if err := repository.GetPending(ctx, &pending, "second"); err != nil || pending.ID != second.ID {
+		t.Fatalf("GetPending returned %q: %v", pending.ID, err)
+	}
+
+	if err := repository.Confirm(ctx, second.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Confirm(ctx, second.ID, time.Now()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("confirming twice returned %v", err)
+	}
+
+	if err := repository.GetPending(ctx, &domain.EmailChange{}, "second"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("a confirmed change is still pending: %v", err)
+	}
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewAPIKeyRepository(db)
+	user := register(t, db, "johndoe")
+	apiKey := domain.APIKey{UserID: user.ID, Name: "deploy", Prefix: "1a2b3c4d", KeyHash: "hash", Scopes: domain.ScopePhotosRead}
+
+	if err := repository.Store(ctx, &apiKey); err != nil {
+		t.Fatal(err)
+	}
+
+	clash := domain.APIKey{UserID: user.ID, Name: "backup", Prefix: "1a2b3c4d", KeyHash: "other", Scopes: domain.ScopePhotosRead}
+
+	if err := repository.Store(ctx, &clash); !errors.Is(err, domain.ErrAPIKeyPrefixTaken) {
+		t.Errorf("storing a key with a taken prefix returned %v, want ErrAPIKeyPrefixTaken", err)
+	}
+
+	var found domain.APIKey
+
+	if err := repository.GetByPrefix(ctx, &found, "1a2b3c4d"); err != nil || found.ID != apiKey.ID {
+		t.Fatalf("GetByPrefix returned %v", err)
+	}
+
+	if err := repository.Revoke(ctx, apiKey.ID, "user-someone-else"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("revoking another user's key returned %v", err)
+	}
+
+	if err := repository.Revoke(ctx, apiKey.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Revoke(ctx, apiKey.ID, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("revoking twice returned %v", err)
+	}
+}
+
+func TestSessionRepositoryFetchesActiveSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewSessionRepository(db)
+	user := register(t, db, "johndoe")
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	active := domain.Session{UserID: user.ID, DeviceName: "laptop", ExpiresAt: &later}
+	expired := domain.Session{UserID: user.ID, DeviceName: "phone", ExpiresAt: &earlier}
+	revoked := domain.Session{UserID: user.ID, DeviceName: "tablet", ExpiresAt: &later}
+
+	for _, session := range []*domain.Session{&active, &expired, &revoked} {
+		if err := repository.Store(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repository.Revoke(ctx, revoked.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var sessions []domain.Session
+
+	if err := repository.Fetch(ctx, &sessions, user.ID, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 1 || sessions[0].ID != active.ID {
+		t.Errorf("fetched %d sessions, want only the active one", len(sessions))
+	}
+}
+
+func TestLoginAttemptRepositoryCountsFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repository := userRepository.NewLoginAttemptRepository(databasetest.Open(t))
+
+	for want := 1; want <= 3; want++ {
+		failures, err := repository.RecordFailure(ctx, "email:johndoe@example.com")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if failures != want {
+			t.Errorf("failure count = %d, want %d", failures, want)
+		}
+	}
+
+	if err := repository.Reset(ctx, "email:johndoe@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	var throttle domain.LoginThrottle
+
+	if err := repository.GetThrottle(ctx, &throttle, "email:johndoe@example.com"); err != nil || throttle.Failures != 0 {
+		t.Errorf("throttle after reset = %+v, %v", throttle, err)
+	}
+}
+
+func TestAccountDeletionRepositoryPurgesContent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewAccountDeletionRepository(db)
+	leaving, staying := register(t, db, "johndoe"), register(t, db, "janedoe")
+
+	rows := []interface{}{
+		&domain.Photo{ID: "photo-leaving", Title: "Sunrise", PhotoUrl: "https://example.com/sunrise.jpg", UserID: leaving.ID},
+		&domain.Photo{ID: "photo-staying", Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: staying.ID},
+		&domain.Comment{ID: "comment-own", Message: "mine", UserID: leaving.ID, PhotoID: "photo-leaving"},
+		&domain.Comment{ID: "comment-received", Message: "nice", UserID: staying.ID, PhotoID: "photo-leaving"},
+		&domain.Comment{ID: "comment-given", Message: "lovely", UserID: leaving.ID, PhotoID: "photo-staying"},
+		&domain.LoginEvent{ID: "login-success", UserID: leaving.ID, Email: leaving.Email, Success: true},
+		&domain.LoginEvent{ID: "login-unknown", Email: leaving.Email, Reason: "invalid_credentials"},
+		&domain.LoginEvent{ID: "login-staying", UserID: staying.ID, Email: staying.Email, Success: true},
+		&domain.LoginThrottle{Key: domain.EmailThrottleKey(leaving.Email), Failures: 3},
+		&domain.LoginThrottle{Key: domain.EmailThrottleKey(staying.Email), Failures: 1},
+	}
+
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purge, err := repository.PurgeContent(ctx, leaving.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (domain.AccountPurge{Photos: 1, Comments: 2, AnonymizedComments: 1, LoginEvents: 2}); purge != want {
+		t.Errorf("purge = %+v, want %+v", purge, want)
+	}
+
+	var given domain.Comment
+
+	if err = db.Take(&given, "id = ?", "comment-given").Error; err != nil || given.UserID != domain.GhostUserID {
+		t.Errorf("comment on another user's photo = %+v, %v, want it owned by the ghost user", given, err)
+	}
+
+	var remaining int64
+
+	db.Model(&domain.Photo{}).Where("user_id = ?", leaving.ID).Count(&remaining)
+
+	if remaining != 0 {
+		t.Errorf("%d photos of the user remain", remaining)
+	}
+
+	db.Model(&domain.LoginEvent{}).Where("user_id = ? OR email = ?", leaving.ID, leaving.Email).Count(&remaining)
+
+	if remaining != 0 {
+		t.Errorf("%d login events of the user remain", remaining)
+	}
+
+	if err = db.Take(&domain.LoginThrottle{}, "key = ?", domain.EmailThrottleKey(leaving.Email)).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("looking up the user's failed logins returned %v, want them deleted", err)
+	}
+
+	if err = db.Take(&domain.LoginThrottle{}, "key = ?", domain.EmailThrottleKey(staying.Email)).Error; err != nil {
+		t.Errorf("the other user's failed logins are gone: %v", err)
+	}
+
+	if _, err = repository.PurgeContent(ctx, staying.ID); err != nil {
+		t.Errorf("purging with an existing ghost user returned %v", err)
+	}
+}
+
+func TestAccountDeletionRepositoryPurgesDespiteASquatter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewAccountDeletionRepository(db)
+	leaving, staying := register(t, db, "johndoe"), register(t, db, "janedoe")
+
+	if err := userRepository.NewUserRepository(db, helpers.NewBcryptHasher(4)).Register(ctx, &domain.User{Username: domain.GhostUsername, Email: "squatter@example.com", Password: "secret", Age: 20}); !errors.Is(err, domain.ErrUsernameTaken) {
+		t.Errorf("registering as the ghost user returned %v, want ErrUsernameTaken", err)
+	}
+
+	// Taken before the username was reserved.
+	squatter := domain.User{ID: "user-squatter", Username: domain.GhostUsername, Email: "deleted@mygram.invalid", Password: "secret", Age: 20}
+
+	if err := db.Session(&gorm.Session{SkipHooks: true}).Create(&squatter).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []interface{}{
+		&domain.Photo{ID: "photo-staying", Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: staying.ID},
+		&domain.Comment{ID: "comment-given", Message: "lovely", UserID: leaving.ID, PhotoID: "photo-staying"},
+	}
+
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := database.NewTxManager(db).Do(ctx, func(ctx context.Context) error {
+		_, err := repository.PurgeContent(ctx, leaving.ID)
+
+		return err
+	}); err != nil {
+		t.Fatalf("purging with a squatter returned %v", err)
+	}
+
+	var ghost domain.User
+
+	if err := db.Take(&ghost, "id = ?", domain.GhostUserID).Error; err != nil || !strings.HasPrefix(ghost.Username, domain.GhostUsername+"-") {
+		t.Errorf("ghost user = %+v, %v, want a suffixed username", ghost, err)
+	}
+
+	var given domain.Comment
+
+	if err := db.Take(&given, "id = ?", "comment-given").Error; err != nil || given.UserID != domain.GhostUserID {
+		t.Errorf("comment on another user's photo = %+v, %v, want it owned by the ghost user", given, err)
+	}
+}
+
+func TestAccountDeletionRepositoryClosesPendingDeletions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repository := userRepository.NewAccountDeletionRepository(databasetest.Open(t))
+	now := time.Now()
+	purgeAfter := now.Add(-time.Minute)
+	deletion := domain.AccountDeletion{UserID: "user-gone", PurgeAfter: &purgeAfter}
+
+	if err := repository.Store(ctx, &deletion); err != nil {
+		t.Fatal(err)
+	}
+
+	var due []domain.AccountDeletion
+
+	if err := repository.FetchDue(ctx, &due, now, 10); err != nil || len(due) != 1 {
+		t.Fatalf("FetchDue returned %d deletions, %v", len(due), err)
+	}
+
+	if err := repository.Complete(ctx, deletion.ID, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Cancel(ctx, deletion.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("canceling a completed deletion returned %v", err)
+	}
+
+	if err := repository.GetPending(ctx, &domain.AccountDeletion{}, "user-gone"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetPending found a completed deletion: %v", err)
+	}
+}
+
+func TestDataExportRepository(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := databasetest.Open(t)
+	repository := userRepository.NewDataExportRepository(db)
+	user, other := register(t, db, "johndoe"), register(t, db, "janedoe")
+
+	rows := []interface{}{
+		&domain.Photo{ID: "photo-1", Title: "Sunrise", PhotoUrl: "https://example.com/sunrise.jpg", UserID: user.ID},
+		&domain.Photo{ID: "photo-2", Title: "Sunset", PhotoUrl: "https://example.com/sunset.jpg", UserID: other.ID},
+		&domain.Comment{ID: "comment-1", Message: "lovely", UserID: user.ID, PhotoID: "photo-2"},
+		&domain.Comment{ID: "comment-2", Message: "thanks", UserID: other.ID, PhotoID: "photo-2"},
+	}
+
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := repository.Collect(ctx, user.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content.User.ID != user.ID || len(content.Photos) != 1 || len(content.Comments) != 1 || content.Comments[0].ID != "comment-1" {
+		t.Errorf("Collect = %+v, want only the user's rows", content)
+	}
+
+	export := domain.DataExport{UserID: user.ID, Status: domain.DataExportPending}
+
+	if err = repository.Store(ctx, &export); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repository.Claim(ctx, export.ID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repository.Claim(ctx, export.ID, time.Now()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("claiming a running export returned %v", err)
+	}
+
+	var stale []domain.DataExport
+
+	if err = repository.FetchStale(ctx, &stale, time.Now().Add(-time.Minute), 10); err != nil || len(stale) != 1 || stale[0].ID != export.ID {
+		t.Errorf("FetchStale = %+v, %v, want the export claimed an hour ago", stale, err)
+	}
+
+	if err = repository.FetchStale(ctx, &stale, time.Now().Add(-2*time.Hour), 10); err != nil || len(stale) != 0 {
+		t.Errorf("FetchStale = %+v, %v, want no export claimed two hours ago", stale, err)
+	}
+
+	if err = repository.GetActive(ctx, &domain.DataExport{}, user.ID); err != nil {
+		t.Errorf("GetActive didn't find the running export: %v", err)
+	}
+
+	expiresAt := time.Now().Add(-time.Minute)
+	export.Status, export.BlobKey, export.ExpiresAt = domain.DataExportReady, "users/"+user.ID+"/exports/"+export.ID+".zip", &expiresAt
+
+	if err = repository.Update(ctx, export); err != nil {
+		t.Fatal(err)
+	}
+
+	var expired []domain.DataExport
+
+	if err = repository.FetchExpired(ctx, &expired, time.Now(), 10); err != nil || len(expired) != 1 || expired[0].BlobKey != export.BlobKey {
+		t.Errorf("FetchExpired = %+v, %v", expired, err)
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"api-mygram-go/config/database"
+	"api-mygram-go/domain"
+	"context"
+	"fmt"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *sessionRepository {
+	return &sessionRepository{db}
+}
+
+func (sessionRepository *sessionRepository) Store(ctx context.Context, session *domain.Session) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	ID, _ := gonanoid.New(16)
+
+	session.ID = fmt.Sprintf("session-%s", ID)
+
+	if err = database.FromContext(ctx, sessionRepository.db).Create(&session).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (sessionRepository *sessionRepository) Fetch(ctx context.Context, sessions *[]domain.Session, userID string, now time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, sessionRepository.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("COALESCE(last_seen_at, created_at) DESC").
+		Find(&sessions).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (sessionRepository *sessionRepository) GetByID(ctx context.Context, session *domain.Session, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, sessionRepository.db).Where("id = ?", id).Take(&session).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (sessionRepository *sessionRepository) Revoke(ctx context.Context, id string, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	result := database.FromContext(ctx, sessionRepository.db).Model(&domain.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+func (sessionRepository *sessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, sessionRepository.db).Model(&domain.Session{}).Where("id = ?", id).Update("last_seen_at", seenAt).Error; err != nil {
+		return err
+	}
+
+	return
+}
@@ -4,20 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"api-mygram-go/config/database"
 	"api-mygram-go/domain"
 	"api-mygram-go/helpers"
+	"strings"
 	"time"
 
 	gonanoid "github.com/matoous/go-nanoid/v2"
 	"gorm.io/gorm"
 )
 
+// dummyPasswordHash is compared against when the email is unknown so that the
+// response time doesn't reveal which accounts exist.
+const dummyPasswordHash = "$2a$10$7EqJtq98hPqEX7fNZaFWoOa2bIH8c0wlrn5yuU7ex0RGsqsbVLmrq"
+
 type userRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	hasher helpers.PasswordHasher
 }
 
-func NewUserRepository(db *gorm.DB) *userRepository {
-	return &userRepository{db}
+func NewUserRepository(db *gorm.DB, hasher helpers.PasswordHasher) *userRepository {
+	return &userRepository{db, hasher}
 }
 
 func (userRepository *userRepository) Register(ctx context.Context, user *domain.User) (err error) {
@@ -29,7 +36,23 @@ func (userRepository *userRepository) Register(ctx context.Context, user *domain
 
 	user.ID = fmt.Sprintf("user-%s", ID)
 
-	if err = userRepository.db.WithContext(ctx).Create(&user).Error; err != nil {
+	if err = user.Validate(); err != nil {
+		return err
+	}
+
+	if user.Password, err = userRepository.hasher.Hash(user.Password); err != nil {
+		return err
+	}
+
+	if err = database.FromContext(ctx, userRepository.db).Create(&user).Error; err != nil {
+		if database.UniqueViolation(err, "users", "username") {
+			return domain.ErrUsernameTaken
+		}
+
+		if database.UniqueViolation(err, "users", "email") {
+			return domain.ErrEmailTaken
+		}
+
 		return err
 	}
 
@@ -43,33 +66,84 @@ func (userRepository *userRepository) Login(ctx context.Context, user *domain.Us
 
 	password := user.Password
 
-	if err = userRepository.db.WithContext(ctx).Where("email = ?", user.Email).Take(&user).Error; err != nil {
-		return errors.New("the email you entered are not found")
+	if err = database.FromContext(ctx, userRepository.db).Where("LOWER(email) = LOWER(?)", strings.TrimSpace(user.Email)).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			userRepository.hasher.Compare([]byte(dummyPasswordHash), []byte(password))
+
+			return domain.ErrInvalidCredentials
+		}
+
+		return err
 	}
 
-	if isValid := helpers.Compare([]byte(user.Password), []byte(password)); !isValid {
-		return errors.New("the credential you entered are wrong")
+	if isValid := userRepository.hasher.Compare([]byte(user.Password), []byte(password)); !isValid {
+		return domain.ErrInvalidCredentials
+	}
+
+	if userRepository.hasher.NeedsRehash([]byte(user.Password)) {
+		if hashedPassword, err := userRepository.hasher.Hash(password); err == nil {
+			if err = database.FromContext(ctx, userRepository.db).Model(&user).UpdateColumn("password", hashedPassword).Error; err == nil {
+				user.Password = hashedPassword
+			}
+		}
 	}
 
 	return
 }
 
+func (userRepository *userRepository) GetByID(ctx context.Context, user *domain.User, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, userRepository.db).First(&user, &id).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+func (userRepository *userRepository) GetByEmail(ctx context.Context, user *domain.User, email string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+
+	defer cancel()
+
+	if err = database.FromContext(ctx, userRepository.db).Where("LOWER(email) = LOWER(?)", email).Take(&user).Error; err != nil {
+		return err
+	}
+
+	return
+}
+
+// Update writes the profile fields of user, found by its id, including the
+// ones left empty.
 func (userRepository *userRepository) Update(ctx context.Context, user domain.User) (u domain.User, err error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 
 	defer cancel()
 
-	u = domain.User{}
+	result := database.FromContext(ctx, userRepository.db).
+		Model(&user).
+		Select("username", "email", "age", "profile_image_url", "display_name", "bio").
+		Updates(&user)
+
+	if err = result.Error; err != nil {
+		if database.UniqueViolation(err, "users", "username") {
+			return u, domain.ErrUsernameTaken
+		}
+
+		if database.UniqueViolation(err, "users", "email") {
+			return u, domain.ErrEmailTaken
+		}
 
-	if err = userRepository.db.WithContext(ctx).First(&u).Error; err != nil {
 		return u, err
 	}
 
-	if err = userRepository.db.WithContext(ctx).Model(&u).Updates(user).Error; err != nil {
-		return u, err
+	if result.RowsAffected == 0 {
+		return u, gorm.ErrRecordNotFound
 	}
 
-	return u, nil
+	return user, nil
 }
 
 func (userRepository *userRepository) Delete(ctx context.Context, id string) (err error) {
@@ -77,15 +151,15 @@ func (userRepository *userRepository) Delete(ctx context.Context, id string) (er
 
 	defer cancel()
 
-	if err = userRepository.db.WithContext(ctx).First(&domain.User{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, userRepository.db).First(&domain.User{}, &id).Error; err != nil {
 		return err
 	}
 
-	if err = userRepository.db.WithContext(ctx).Where("user_id = ?", id).Delete(&domain.SocialMedia{}).Error; err != nil {
+	if err = database.FromContext(ctx, userRepository.db).Where("user_id = ?", id).Delete(&domain.SocialMedia{}).Error; err != nil {
 		return err
 	}
 
-	if err = userRepository.db.WithContext(ctx).Delete(&domain.User{}, &id).Error; err != nil {
+	if err = database.FromContext(ctx, userRepository.db).Delete(&domain.User{}, &id).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize bounds how many accounts one PurgeDue call erases.
+const purgeBatchSize = 50
+
+type accountDeletionUseCase struct {
+	accountDeletionRepository domain.AccountDeletionRepository
+	userRepository            domain.UserRepository
+	auditRepository           domain.AuditRepository
+	blobStore                 domain.BlobStore
+	txManager                 domain.TxManager
+	gracePeriod               time.Duration
+}
+
+func NewAccountDeletionUseCase(accountDeletionRepository domain.AccountDeletionRepository, userRepository domain.UserRepository, auditRepository domain.AuditRepository, blobStore domain.BlobStore, txManager domain.TxManager, gracePeriod time.Duration) *accountDeletionUseCase {
+	return &accountDeletionUseCase{accountDeletionRepository, userRepository, auditRepository, blobStore, txManager, gracePeriod}
+}
+
+// Schedule asks for the account to be purged once the grace period is over.
+// Asking again returns the pending deletion unchanged.
+func (accountDeletionUseCase *accountDeletionUseCase) Schedule(ctx context.Context, userID string) (deletion domain.AccountDeletion, err error) {
+	err = accountDeletionUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := accountDeletionUseCase.accountDeletionRepository.GetPending(ctx, &deletion, userID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := accountDeletionUseCase.userRepository.GetByID(ctx, &domain.User{}, userID); err != nil {
+			return err
+		}
+
+		purgeAfter := time.Now().Add(accountDeletionUseCase.gracePeriod)
+		deletion = domain.AccountDeletion{UserID: userID, PurgeAfter: &purgeAfter}
+
+		if err := accountDeletionUseCase.accountDeletionRepository.Store(ctx, &deletion); err != nil {
+			return err
+		}
+
+		return accountDeletionUseCase.audit(ctx, domain.AuditAccountDeletionScheduled, userID, userID, map[string]any{
+			"deletion_id": deletion.ID,
+			"purge_after": purgeAfter,
+		})
+	})
+
+	return deletion, err
+}
+
+func (accountDeletionUseCase *accountDeletionUseCase) GetPending(ctx context.Context, deletion *domain.AccountDeletion, userID string) (err error) {
+	return accountDeletionUseCase.accountDeletionRepository.GetPending(ctx, deletion, userID)
+}
+
+// Cancel keeps the account. It reports gorm.ErrRecordNotFound when no
+// deletion is pending.
+func (accountDeletionUseCase *accountDeletionUseCase) Cancel(ctx context.Context, userID string) (err error) {
+	return accountDeletionUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		deletion := domain.AccountDeletion{}
+
+		if err := accountDeletionUseCase.accountDeletionRepository.GetPending(ctx, &deletion, userID); err != nil {
+			return err
+		}
+
+		if err := accountDeletionUseCase.accountDeletionRepository.Cancel(ctx, deletion.ID, time.Now()); err != nil {
+			return err
+		}
+
+		return accountDeletionUseCase.audit(ctx, domain.AuditAccountDeletionCanceled, userID, userID, map[string]any{
+			"deletion_id": deletion.ID,
+		})
+	})
+}
+
+// PurgeDue erases the accounts whose grace period ended before now and
+// reports how many it erased. A failed purge is retried on the next call and
+// doesn't stop the others.
+func (accountDeletionUseCase *accountDeletionUseCase) PurgeDue(ctx context.Context, now time.Time) (purged int, err error) {
+	deletions := []domain.AccountDeletion{}
+
+	if err = accountDeletionUseCase.accountDeletionRepository.FetchDue(ctx, &deletions, now, purgeBatchSize); err != nil {
+		return 0, err
+	}
+
+	var errs []error
+
+	for _, deletion := range deletions {
+		if err := accountDeletionUseCase.purge(ctx, deletion); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+// purge deletes the user's files before touching the database: deleting
+// files can't be rolled back, but it can be repeated when the transaction
+// fails.
+func (accountDeletionUseCase *accountDeletionUseCase) purge(ctx context.Context, deletion domain.AccountDeletion) (err error) {
+	files, err := accountDeletionUseCase.blobStore.DeletePrefix(ctx, domain.UserBlobPrefix(deletion.UserID))
+
+	if err != nil {
+		return err
+	}
+
+	return accountDeletionUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		purge, err := accountDeletionUseCase.accountDeletionRepository.PurgeContent(ctx, deletion.UserID)
+
+		if err != nil {
+			return err
+		}
+
+		purge.Files = files
+
+		if err = accountDeletionUseCase.userRepository.Delete(ctx, deletion.UserID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err = accountDeletionUseCase.accountDeletionRepository.Complete(ctx, deletion.ID, time.Now()); err != nil {
+			return err
+		}
+
+		return accountDeletionUseCase.audit(ctx, domain.AuditAccountPurged, "", deletion.UserID, map[string]any{
+			"deletion_id": deletion.ID,
+			"purged":      purge,
+		})
+	})
+}
+
+func (accountDeletionUseCase *accountDeletionUseCase) audit(ctx context.Context, action string, actorID string, subjectID string, details map[string]any) error {
+	encoded, err := json.Marshal(details)
+
+	if err != nil {
+		return err
+	}
+
+	return accountDeletionUseCase.auditRepository.Store(ctx, &domain.AuditEvent{
+		Action:    action,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		Details:   string(encoded),
+	})
+}
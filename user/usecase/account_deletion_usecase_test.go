@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestAccountDeletionPurgesAfterTheGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	users := fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe"}, domain.User{ID: "user-2", Username: "janedoe"})
+	deletions := fakes.NewAccountDeletionRepository()
+	audit := fakes.NewAuditRepository()
+	blobs := fakes.NewBlobStore()
+	useCase := NewAccountDeletionUseCase(deletions, users, audit, blobs, fakes.TxManager{}, 24*time.Hour)
+
+	for _, key := range []string{"users/user-1/avatar.jpg", "users/user-1/export.zip", "users/user-2/avatar.jpg"} {
+		if err := blobs.Put(ctx, key, strings.NewReader("content")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scheduled, err := useCase.Schedule(ctx, "user-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again, err := useCase.Schedule(ctx, "user-1"); err != nil || again.ID != scheduled.ID {
+		t.Errorf("scheduling twice returned %q, %v, want the pending deletion %q", again.ID, err, scheduled.ID)
+	}
+
+	if _, err = useCase.Schedule(ctx, "user-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = useCase.Cancel(ctx, "user-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if purged, err := useCase.PurgeDue(ctx, time.Now()); err != nil || purged != 0 {
+		t.Errorf("PurgeDue within the grace period purged %d, %v", purged, err)
+	}
+
+	if purged, err := useCase.PurgeDue(ctx, time.Now().Add(25*time.Hour)); err != nil || purged != 1 {
+		t.Fatalf("PurgeDue after the grace period purged %d, %v, want 1", purged, err)
+	}
+
+	if err = users.GetByID(ctx, &domain.User{}, "user-1"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("purged user is still found: %v", err)
+	}
+
+	if err = users.GetByID(ctx, &domain.User{}, "user-2"); err != nil {
+		t.Errorf("user who canceled the deletion is gone: %v", err)
+	}
+
+	if _, err = blobs.Open(ctx, "users/user-1/avatar.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("files of the purged user remain: %v", err)
+	}
+
+	if _, err = blobs.Open(ctx, "users/user-2/avatar.jpg"); err != nil {
+		t.Errorf("files of another user were deleted: %v", err)
+	}
+
+	var actions []string
+
+	for _, event := range audit.Events() {
+		actions = append(actions, event.Action+" "+event.SubjectID)
+	}
+
+	want := []string{
+		domain.AuditAccountDeletionScheduled + " user-1",
+		domain.AuditAccountDeletionScheduled + " user-2",
+		domain.AuditAccountDeletionCanceled + " user-2",
+		domain.AuditAccountPurged + " user-1",
+	}
+
+	if strings.Join(actions, ", ") != strings.Join(want, ", ") {
+		t.Errorf("audit log = %v, want %v", actions, want)
+	}
+
+	if purged := audit.Events()[3].Details; !strings.Contains(purged, `"files":2`) {
+		t.Errorf("purge details %s don't count the 2 deleted files", purged)
+	}
+}
+
+func TestCancelWithoutPendingDeletion(t *testing.T) {
+	useCase := NewAccountDeletionUseCase(fakes.NewAccountDeletionRepository(), fakes.NewUserRepository(), fakes.NewAuditRepository(), fakes.NewBlobStore(), fakes.TxManager{}, time.Hour)
+
+	if err := useCase.Cancel(context.Background(), "user-1"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Cancel returned %v, want gorm.ErrRecordNotFound", err)
+	}
+}
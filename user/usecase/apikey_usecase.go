@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyTouchInterval limits how often last_used_at is written for a key
+	// that is used continuously.
+	apiKeyTouchInterval = time.Minute
+
+	// apiKeyAttempts bounds how many keys Create generates when their
+	// prefixes are taken, which is unlikely enough that a few tries do.
+	apiKeyAttempts = 3
+)
+
+type apiKeyUseCase struct {
+	apiKeyRepository domain.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(apiKeyRepository domain.APIKeyRepository) *apiKeyUseCase {
+	return &apiKeyUseCase{apiKeyRepository}
+}
+
+// Create stores apiKey with the requested scopes and returns the plain key,
+// which is never retrievable again.
+func (apiKeyUseCase *apiKeyUseCase) Create(ctx context.Context, apiKey *domain.APIKey, scopes []string) (key string, err error) {
+	granted := []string{}
+
+	for _, scope := range scopes {
+		if !contains(domain.Scopes, scope) {
+			return "", fmt.Errorf("unknown scope %q, use one of %s", scope, strings.Join(domain.Scopes, ", "))
+		}
+
+		if !contains(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+
+	if len(granted) == 0 {
+		return "", errors.New("an api key needs at least one scope")
+	}
+
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return "", errors.New("the expiry of an api key must be in the future")
+	}
+
+	apiKey.Scopes = strings.Join(granted, " ")
+
+	for attempt := 1; ; attempt++ {
+		var prefix string
+
+		if key, prefix, err = helpers.GenerateAPIKey(); err != nil {
+			return "", err
+		}
+
+		apiKey.Prefix = prefix
+		apiKey.KeyHash = helpers.HashAPIKey(key)
+
+		err = apiKeyUseCase.apiKeyRepository.Store(ctx, apiKey)
+
+		if !errors.Is(err, domain.ErrAPIKeyPrefixTaken) || attempt == apiKeyAttempts {
+			break
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (apiKeyUseCase *apiKeyUseCase) Fetch(ctx context.Context, apiKeys *[]domain.APIKey, userID string) (err error) {
+	if err = apiKeyUseCase.apiKeyRepository.Fetch(ctx, apiKeys, userID); err != nil {
+		return err
+	}
+
+	return
+}
+
+func (apiKeyUseCase *apiKeyUseCase) Revoke(ctx context.Context, id string, userID string) (err error) {
+	if err = apiKeyUseCase.apiKeyRepository.Revoke(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return
+}
+
+func (apiKeyUseCase *apiKeyUseCase) Authenticate(ctx context.Context, key string) (apiKey domain.APIKey, err error) {
+	prefix, ok := helpers.ParseAPIKey(key)
+
+	if !ok {
+		return apiKey, domain.ErrInvalidAPIKey
+	}
+
+	if err = apiKeyUseCase.apiKeyRepository.GetByPrefix(ctx, &apiKey, prefix); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apiKey, domain.ErrInvalidAPIKey
+		}
+
+		return apiKey, err
+	}
+
+	now := time.Now()
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(helpers.HashAPIKey(key))) != 1 || !apiKey.Active(now) {
+		return domain.APIKey{}, domain.ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err = apiKeyUseCase.apiKeyRepository.Touch(ctx, apiKey.ID, now); err == nil {
+			apiKey.LastUsedAt = &now
+		}
+	}
+
+	return apiKey, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"api-mygram-go/helpers"
+	"context"
+	"errors"
+	"testing"
+)
+
+// clashingAPIKeys reports the prefix of the first clashes keys as taken.
+type clashingAPIKeys struct {
+	*fakes.APIKeyRepository
+	clashes int
+	stores  int
+}
+
+func (repository *clashingAPIKeys) Store(ctx context.Context, apiKey *domain.APIKey) error {
+	if repository.stores++; repository.stores <= repository.clashes {
+		return domain.ErrAPIKeyPrefixTaken
+	}
+
+	return repository.APIKeyRepository.Store(ctx, apiKey)
+}
+
+func TestCreateAPIKeyRetriesTakenPrefixes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		clashes int
+		wantErr error
+	}{
+		{0, nil},
+		{apiKeyAttempts - 1, nil},
+		{apiKeyAttempts, domain.ErrAPIKeyPrefixTaken},
+	}
+
+	for _, test := range tests {
+		apiKeys := &clashingAPIKeys{APIKeyRepository: fakes.NewAPIKeyRepository(), clashes: test.clashes}
+		apiKey := domain.APIKey{UserID: "user-1", Name: "deploy"}
+
+		key, err := NewAPIKeyUseCase(apiKeys).Create(ctx, &apiKey, []string{domain.ScopePhotosRead})
+
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%d clashes: Create returned %v, want %v", test.clashes, err, test.wantErr)
+
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if prefix, ok := helpers.ParseAPIKey(key); !ok || prefix != apiKey.Prefix || apiKey.KeyHash != helpers.HashAPIKey(key) {
+			t.Errorf("%d clashes: key %q was stored as %+v, want the key that was stored", test.clashes, key, apiKey)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"bytes"
+	"context"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	minAvatarSide = 64
+
+	// maxAvatarPixels bounds the memory a decoded upload takes, whatever
+	// its file size.
+	maxAvatarPixels = 40_000_000
+)
+
+var (
+	avatarFilePattern = regexp.MustCompile(`^([a-z0-9]{12})-([0-9]+)\.jpg$`)
+	userIDPattern     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+type avatarUseCase struct {
+	userRepository domain.UserRepository
+	blobStore      domain.BlobStore
+}
+
+func NewAvatarUseCase(userRepository domain.UserRepository, blobStore domain.BlobStore) *avatarUseCase {
+	return &avatarUseCase{userRepository, blobStore}
+}
+
+// Set makes the image read from r the avatar of the user with userID. The
+// image is cropped to a centered square and stored in every
+// domain.AvatarSizes, then the files of previous avatars are removed.
+func (avatarUseCase *avatarUseCase) Set(ctx context.Context, userID string, r io.Reader) (user domain.User, err error) {
+	if err = avatarUseCase.userRepository.GetByID(ctx, &user, userID); err != nil {
+		return domain.User{}, err
+	}
+
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil || config.Width < minAvatarSide || config.Height < minAvatarSide || config.Width*config.Height > maxAvatarPixels {
+		return domain.User{}, domain.ErrInvalidImage
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return domain.User{}, domain.ErrInvalidImage
+	}
+
+	version, err := gonanoid.Generate("abcdefghijklmnopqrstuvwxyz0123456789", 12)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	square := centeredSquare(src.Bounds())
+
+	for _, size := range domain.AvatarSizes {
+		var buf bytes.Buffer
+
+		if err = jpeg.Encode(&buf, scale(src, square, size), &jpeg.Options{Quality: 85}); err != nil {
+			return domain.User{}, err
+		}
+
+		if err = avatarUseCase.blobStore.Put(ctx, domain.AvatarKey(userID, version, size), &buf); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	user.ProfileImageUrl = domain.AvatarPath(userID, version, domain.ProfileAvatarSize)
+
+	if user, err = avatarUseCase.userRepository.Update(ctx, user); err != nil {
+		avatarUseCase.removeAvatars(ctx, userID, func(v string) bool { return v != version })
+
+		return domain.User{}, err
+	}
+
+	// Files left behind by a failure here are removed with the next upload
+	// or with the account.
+	avatarUseCase.removeAvatars(ctx, userID, func(v string) bool { return v == version })
+
+	return user, nil
+}
+
+// Open returns the avatar file of the user with userID, named as in
+// domain.AvatarPath.
+func (avatarUseCase *avatarUseCase) Open(ctx context.Context, userID string, file string) (io.ReadCloser, error) {
+	match := avatarFilePattern.FindStringSubmatch(file)
+
+	if match == nil || !userIDPattern.MatchString(userID) {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	if size, err := strconv.Atoi(match[2]); err != nil || !slices.Contains(domain.AvatarSizes, size) {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return avatarUseCase.blobStore.Open(ctx, domain.UserBlobPrefix(userID)+"avatar/"+file)
+}
+
+// removeAvatars deletes the avatar files of the user except the versions
+// kept.
+func (avatarUseCase *avatarUseCase) removeAvatars(ctx context.Context, userID string, keep func(version string) bool) {
+	prefix := domain.UserBlobPrefix(userID) + "avatar/"
+	keys, err := avatarUseCase.blobStore.List(ctx, prefix)
+
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		version, _, _ := strings.Cut(strings.TrimPrefix(key, prefix), "-")
+
+		if !keep(version) {
+			_ = avatarUseCase.blobStore.Delete(ctx, key)
+		}
+	}
+}
+
+// centeredSquare returns the largest square in the middle of bounds.
+func centeredSquare(bounds image.Rectangle) image.Rectangle {
+	side := min(bounds.Dx(), bounds.Dy())
+	corner := bounds.Min.Add(image.Pt((bounds.Dx()-side)/2, (bounds.Dy()-side)/2))
+
+	return image.Rectangle{Min: corner, Max: corner.Add(image.Pt(side, side))}
+}
+
+// scale draws the square of src on a white size by size image, so that
+// transparent areas don't turn black in JPEG.
+func scale(src image.Image, square image.Rectangle, size int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Over, nil)
+
+	return dst
+}
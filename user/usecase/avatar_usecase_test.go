@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/asaskevich/govalidator"
+)
+
+func encodePNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			// The left and right thirds are transparent, the middle is red.
+			if x >= width/3 && x < 2*width/3 {
+				img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestAvatarUseCaseSet(t *testing.T) {
+	ctx := context.Background()
+	users := fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20})
+	blobs := fakes.NewBlobStore()
+	useCase := NewAvatarUseCase(users, blobs)
+
+	first, err := useCase.Set(ctx, "user-1", bytes.NewReader(encodePNG(t, 300, 100)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = govalidator.ValidateStruct(first); err != nil {
+		t.Errorf("the user with an avatar doesn't validate: %v", err)
+	}
+
+	keys, _ := blobs.List(ctx, "users/user-1/avatar/")
+
+	if len(keys) != len(domain.AvatarSizes) {
+		t.Fatalf("stored %v, want one file per size", keys)
+	}
+
+	file, err := useCase.Open(ctx, "user-1", first.ProfileImageUrl[len("/avatars/user-1/"):])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	avatar, err := jpeg.Decode(file)
+	file.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bounds := avatar.Bounds(); bounds.Dx() != domain.ProfileAvatarSize || bounds.Dy() != domain.ProfileAvatarSize {
+		t.Errorf("the profile avatar is %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	// The centered square of a 300x100 image is the middle third, which is
+	// all red.
+	if r, g, b, _ := avatar.At(5, 128).RGBA(); r>>8 < 200 || g>>8 > 60 || b>>8 > 60 {
+		t.Errorf("the edge of the avatar is %d,%d,%d, want red", r>>8, g>>8, b>>8)
+	}
+
+	second, err := useCase.Set(ctx, "user-1", bytes.NewReader(encodePNG(t, 64, 64)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ = blobs.List(ctx, "users/user-1/avatar/")
+
+	if len(keys) != len(domain.AvatarSizes) || second.ProfileImageUrl == first.ProfileImageUrl {
+		t.Errorf("after a second upload stored %v, want only the files of the new avatar", keys)
+	}
+
+	if _, err = useCase.Open(ctx, "user-1", first.ProfileImageUrl[len("/avatars/user-1/"):]); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("the previous avatar is still served: %v", err)
+	}
+}
+
+func TestAvatarUseCaseRejectsInvalidImages(t *testing.T) {
+	ctx := context.Background()
+	users := fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20})
+	useCase := NewAvatarUseCase(users, fakes.NewBlobStore())
+
+	uploads := map[string][]byte{
+		"text":      []byte("not an image"),
+		"too small": encodePNG(t, 63, 200),
+		"truncated": encodePNG(t, 100, 100)[:200],
+	}
+
+	for name, upload := range uploads {
+		if _, err := useCase.Set(ctx, "user-1", bytes.NewReader(upload)); !errors.Is(err, domain.ErrInvalidImage) {
+			t.Errorf("%s upload returned %v, want ErrInvalidImage", name, err)
+		}
+	}
+
+	for _, file := range []string{"../../secret", "abcdefghijkl-100.jpg", "abcdefghijkl-64.png"} {
+		if _, err := useCase.Open(ctx, "user-1", file); !errors.Is(err, domain.ErrBlobNotFound) {
+			t.Errorf("opening %s returned %v, want ErrBlobNotFound", file, err)
+		}
+	}
+}
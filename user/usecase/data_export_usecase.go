@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// exportBatchSize bounds how many exports one Process call assembles.
+	exportBatchSize = 10
+
+	// exportClaimTimeout is how long an export may be running before it is
+	// taken for interrupted, so its user can request another one.
+	exportClaimTimeout = 30 * time.Minute
+)
+
+var errExportInterrupted = errors.New("assembling the export was interrupted, request a new one")
+
+type dataExportUseCase struct {
+	dataExportRepository domain.DataExportRepository
+	blobStore            domain.BlobStore
+	signingKey           []byte
+	retention            time.Duration
+	linkTTL              time.Duration
+	notify               func()
+}
+
+// NewDataExportUseCase signs download links with signingKey. notify is called
+// whenever an export is requested, to wake whatever calls Process.
+func NewDataExportUseCase(dataExportRepository domain.DataExportRepository, blobStore domain.BlobStore, signingKey []byte, retention time.Duration, linkTTL time.Duration, notify func()) *dataExportUseCase {
+	return &dataExportUseCase{dataExportRepository, blobStore, signingKey, retention, linkTTL, notify}
+}
+
+// Request queues an export of the user's data. Requesting again while an
+// export is queued or running returns that export.
+func (dataExportUseCase *dataExportUseCase) Request(ctx context.Context, userID string) (export domain.DataExport, err error) {
+	if err = dataExportUseCase.dataExportRepository.GetActive(ctx, &export, userID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		return export, err
+	}
+
+	export = domain.DataExport{UserID: userID, Status: domain.DataExportPending}
+
+	if err = dataExportUseCase.dataExportRepository.Store(ctx, &export); err != nil {
+		return export, err
+	}
+
+	dataExportUseCase.notify()
+
+	return export, nil
+}
+
+// GetByID returns the export with id when it belongs to userID, and
+// gorm.ErrRecordNotFound otherwise.
+func (dataExportUseCase *dataExportUseCase) GetByID(ctx context.Context, export *domain.DataExport, id string, userID string) (err error) {
+	if err = dataExportUseCase.dataExportRepository.GetByID(ctx, export, id); err != nil {
+		return err
+	}
+
+	if export.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+
+	return
+}
+
+// Link returns a download link that lasts the link TTL, or until the export
+// expires when that is sooner.
+func (dataExportUseCase *dataExportUseCase) Link(export domain.DataExport) domain.DataExportLink {
+	expires := time.Now().Add(dataExportUseCase.linkTTL).Truncate(time.Second)
+
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(expires) {
+		expires = export.ExpiresAt.Truncate(time.Second)
+	}
+
+	return domain.DataExportLink{Expires: expires, Signature: dataExportUseCase.sign(export.ID, expires)}
+}
+
+func (dataExportUseCase *dataExportUseCase) sign(id string, expires time.Time) string {
+	mac := hmac.New(sha256.New, dataExportUseCase.signingKey)
+	mac.Write([]byte(id + "\n" + strconv.FormatInt(expires.Unix(), 10)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Open returns the archive of the export with id when link was issued for it
+// and hasn't expired, and domain.ErrExportLinkInvalid otherwise.
+func (dataExportUseCase *dataExportUseCase) Open(ctx context.Context, id string, link domain.DataExportLink) (io.ReadCloser, error) {
+	now := time.Now()
+
+	if !hmac.Equal([]byte(link.Signature), []byte(dataExportUseCase.sign(id, link.Expires))) || !link.Expires.After(now) {
+		return nil, domain.ErrExportLinkInvalid
+	}
+
+	export := domain.DataExport{}
+
+	if err := dataExportUseCase.dataExportRepository.GetByID(ctx, &export, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrExportLinkInvalid
+		}
+
+		return nil, err
+	}
+
+	if export.Status != domain.DataExportReady || export.ExpiresAt == nil || !export.ExpiresAt.After(now) {
+		return nil, domain.ErrExportLinkInvalid
+	}
+
+	return dataExportUseCase.blobStore.Open(ctx, export.BlobKey)
+}
+
+// Process deletes the archives of expired exports and fails the exports left
+// running for too long, then assembles the pending ones and reports how many
+// it assembled. An export that can't be assembled is marked failed with the
+// reason, and one interrupted by ctx is put back to pending.
+func (dataExportUseCase *dataExportUseCase) Process(ctx context.Context) (processed int, err error) {
+	if err = dataExportUseCase.expire(ctx); err != nil {
+		return 0, err
+	}
+
+	if err = dataExportUseCase.failStale(ctx); err != nil {
+		return 0, err
+	}
+
+	exports := []domain.DataExport{}
+
+	if err = dataExportUseCase.dataExportRepository.FetchPending(ctx, &exports, exportBatchSize); err != nil {
+		return 0, err
+	}
+
+	for _, export := range exports {
+		if err = dataExportUseCase.dataExportRepository.Claim(ctx, export.ID, time.Now()); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+
+			return processed, err
+		}
+
+		export.BlobKey, err = dataExportUseCase.assemble(ctx, export)
+		now := time.Now()
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// Interrupted, by a shutdown most likely: the next run assembles
+			// it again.
+			export.Status = domain.DataExportPending
+			export.BlobKey = ""
+		case err != nil:
+			export.Status = domain.DataExportFailed
+			export.Error = err.Error()
+			export.CompletedAt = &now
+		default:
+			expiresAt := now.Add(dataExportUseCase.retention)
+			export.Status = domain.DataExportReady
+			export.CompletedAt = &now
+			export.ExpiresAt = &expiresAt
+		}
+
+		// The outcome is recorded even if ctx is done, or the export would
+		// stay running.
+		if err = dataExportUseCase.dataExportRepository.Update(context.WithoutCancel(ctx), export); err != nil {
+			return processed, err
+		}
+
+		if export.Status == domain.DataExportPending {
+			return processed, ctx.Err()
+		}
+
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (dataExportUseCase *dataExportUseCase) expire(ctx context.Context) (err error) {
+	exports := []domain.DataExport{}
+
+	if err = dataExportUseCase.dataExportRepository.FetchExpired(ctx, &exports, time.Now(), exportBatchSize); err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err = dataExportUseCase.blobStore.Delete(ctx, export.BlobKey); err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+			return err
+		}
+
+		export.Status = domain.DataExportExpired
+
+		if err = dataExportUseCase.dataExportRepository.Update(ctx, export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failStale marks failed the exports running for longer than
+// exportClaimTimeout, whose worker must have died, so their users can
+// request new ones.
+func (dataExportUseCase *dataExportUseCase) failStale(ctx context.Context) (err error) {
+	exports := []domain.DataExport{}
+	now := time.Now()
+
+	if err = dataExportUseCase.dataExportRepository.FetchStale(ctx, &exports, now.Add(-exportClaimTimeout), exportBatchSize); err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		export.Status = domain.DataExportFailed
+		export.Error = errExportInterrupted.Error()
+		export.CompletedAt = &now
+
+		if err = dataExportUseCase.dataExportRepository.Update(ctx, export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assemble writes the archive of export to the blob store and returns its
+// key. The archive holds the records as JSON and the user's stored files,
+// except earlier exports.
+func (dataExportUseCase *dataExportUseCase) assemble(ctx context.Context, export domain.DataExport) (string, error) {
+	content, err := dataExportUseCase.dataExportRepository.Collect(ctx, export.UserID)
+
+	if err != nil {
+		return "", err
+	}
+
+	content.User.Password = ""
+
+	prefix := domain.UserBlobPrefix(export.UserID)
+	keys, err := dataExportUseCase.blobStore.List(ctx, prefix)
+
+	if err != nil {
+		return "", err
+	}
+
+	files := []string{}
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix+"exports/") {
+			files = append(files, key)
+		}
+	}
+
+	key := prefix + "exports/" + export.ID + ".zip"
+	reader, writer := io.Pipe()
+
+	go func() {
+		writer.CloseWithError(dataExportUseCase.writeArchive(ctx, writer, content, prefix, files, time.Now()))
+	}()
+
+	err = dataExportUseCase.blobStore.Put(ctx, key, reader)
+	reader.CloseWithError(err)
+
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (dataExportUseCase *dataExportUseCase) writeArchive(ctx context.Context, w io.Writer, content domain.DataExportContent, prefix string, files []string, modified time.Time) error {
+	archive := zip.NewWriter(w)
+	create := func(name string) (io.Writer, error) {
+		return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	}
+	records := []struct {
+		name  string
+		value any
+	}{
+		{"profile.json", content.User},
+		{"photos.json", content.Photos},
+		{"comments.json", content.Comments},
+		{"social_medias.json", content.SocialMedias},
+	}
+
+	for _, record := range records {
+		entry, err := create(record.name)
+
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+
+		if err = encoder.Encode(record.value); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range files {
+		if err := dataExportUseCase.copyFile(ctx, create, "files/"+strings.TrimPrefix(key, prefix), key); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (dataExportUseCase *dataExportUseCase) copyFile(ctx context.Context, create func(string) (io.Writer, error), name string, key string) error {
+	file, err := dataExportUseCase.blobStore.Open(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	entry, err := create(name)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, file)
+
+	return err
+}
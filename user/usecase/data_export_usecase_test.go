@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDataExportAssemblesADownloadableArchive(t *testing.T) {
+	ctx := context.Background()
+	exports := fakes.NewDataExportRepository()
+	exports.Content = domain.DataExportContent{
+		User:   domain.User{ID: "user-1", Username: "johndoe", Password: "$2a$04$hash"},
+		Photos: []domain.Photo{{ID: "photo-1", UserID: "user-1", Title: "Sunrise"}},
+	}
+	blobs := fakes.NewBlobStore()
+	notified := 0
+	useCase := NewDataExportUseCase(exports, blobs, []byte("key"), time.Hour, time.Minute, func() { notified++ })
+
+	if err := blobs.Put(ctx, "users/user-1/avatar.jpg", strings.NewReader("jpeg")); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := useCase.Request(ctx, "user-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again, err := useCase.Request(ctx, "user-1"); err != nil || again.ID != export.ID || notified != 1 {
+		t.Errorf("requesting twice returned %q, %v after %d notifications, want the queued export", again.ID, err, notified)
+	}
+
+	if processed, err := useCase.Process(ctx); err != nil || processed != 1 {
+		t.Fatalf("Process assembled %d exports, %v", processed, err)
+	}
+
+	if err = useCase.GetByID(ctx, &export, export.ID, "user-1"); err != nil || export.Status != domain.DataExportReady {
+		t.Fatalf("export is %q, %v, want ready", export.Status, err)
+	}
+
+	if err = useCase.GetByID(ctx, &domain.DataExport{}, export.ID, "user-2"); err == nil {
+		t.Error("another user could see the export")
+	}
+
+	link := useCase.Link(export)
+	forged := link
+	forged.Expires = forged.Expires.Add(time.Hour)
+
+	if _, err = useCase.Open(ctx, export.ID, forged); !errors.Is(err, domain.ErrExportLinkInvalid) {
+		t.Errorf("opening with an extended link returned %v", err)
+	}
+
+	file, err := useCase.Open(ctx, export.ID, link)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := map[string]string{}
+
+	for _, entry := range archive.File {
+		reader, err := entry.Open()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := io.ReadAll(reader)
+		reader.Close()
+		entries[entry.Name] = string(body)
+	}
+
+	for _, name := range []string{"profile.json", "photos.json", "comments.json", "social_medias.json", "files/avatar.jpg"} {
+		if _, ok := entries[name]; !ok {
+			t.Errorf("archive has no %s", name)
+		}
+	}
+
+	if strings.Contains(entries["profile.json"], "hash") {
+		t.Errorf("profile.json leaks the password hash: %s", entries["profile.json"])
+	}
+
+	if !strings.Contains(entries["photos.json"], "Sunrise") {
+		t.Errorf("photos.json = %s, want the user's photo", entries["photos.json"])
+	}
+}
+
+func TestDataExportLinksStopWorkingWhenTheExportExpires(t *testing.T) {
+	ctx := context.Background()
+	exports := fakes.NewDataExportRepository()
+	blobs := fakes.NewBlobStore()
+	useCase := NewDataExportUseCase(exports, blobs, []byte("key"), time.Hour, time.Minute, func() {})
+
+	expiresAt := time.Now().Add(-time.Second)
+	export := domain.DataExport{UserID: "user-1", Status: domain.DataExportReady, BlobKey: "users/user-1/exports/old.zip", ExpiresAt: &expiresAt}
+
+	if err := exports.Store(ctx, &export); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := blobs.Put(ctx, export.BlobKey, strings.NewReader("PK")); err != nil {
+		t.Fatal(err)
+	}
+
+	link := domain.DataExportLink{Expires: time.Now().Add(time.Minute)}
+	link.Signature = useCase.sign(export.ID, link.Expires)
+
+	if _, err := useCase.Open(ctx, export.ID, link); !errors.Is(err, domain.ErrExportLinkInvalid) {
+		t.Errorf("opening an expired export returned %v", err)
+	}
+
+	if _, err := useCase.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := blobs.Open(ctx, export.BlobKey); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("the archive of an expired export remains: %v", err)
+	}
+
+	if err := exports.GetByID(ctx, &export, export.ID); err != nil || export.Status != domain.DataExportExpired {
+		t.Errorf("expired export is %q, %v", export.Status, err)
+	}
+}
+
+func TestDataExportLeftRunningIsFailed(t *testing.T) {
+	ctx := context.Background()
+	exports := fakes.NewDataExportRepository()
+	useCase := NewDataExportUseCase(exports, fakes.NewBlobStore(), []byte("key"), time.Hour, time.Minute, func() {})
+
+	stuck, err := useCase.Request(ctx, "user-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A worker claimed it an hour ago and died.
+	if err = exports.Claim(ctx, stuck.ID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if processed, err := useCase.Process(ctx); err != nil || processed != 0 {
+		t.Fatalf("Process assembled %d exports, %v", processed, err)
+	}
+
+	if err = useCase.GetByID(ctx, &stuck, stuck.ID, "user-1"); err != nil || stuck.Status != domain.DataExportFailed || stuck.Error == "" {
+		t.Fatalf("export is %q, %v, want failed with the reason", stuck.Status, err)
+	}
+
+	if export, err := useCase.Request(ctx, "user-1"); err != nil || export.ID == stuck.ID {
+		t.Errorf("requesting again returned %q, %v, want a new export", export.ID, err)
+	}
+}
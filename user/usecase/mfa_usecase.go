@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+type mfaUseCase struct {
+	mfaRepository          domain.MFARepository
+	loginAttemptRepository domain.LoginAttemptRepository
+	txManager              domain.TxManager
+	loginPolicy            LoginPolicy
+	issuer                 string
+	secrets                *helpers.SecretBox
+}
+
+// NewMFAUseCase returns the MFA usecase. TOTP secrets are stored encrypted
+// with cfg.EncryptionKey.
+func NewMFAUseCase(mfaRepository domain.MFARepository, loginAttemptRepository domain.LoginAttemptRepository, txManager domain.TxManager, cfg config.TOTP) *mfaUseCase {
+	return &mfaUseCase{mfaRepository, loginAttemptRepository, txManager, DefaultLoginPolicy, cfg.Issuer, helpers.NewSecretBox(cfg.EncryptionKey)}
+}
+
+func (mfaUseCase *mfaUseCase) EnrollTOTP(ctx context.Context, userID string, account string) (enrollment domain.TOTPEnrollment, err error) {
+	mfa := domain.UserMFA{}
+
+	if err = mfaUseCase.mfaRepository.GetByUserID(ctx, &mfa, userID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return enrollment, err
+	}
+
+	if mfa.Enabled {
+		return enrollment, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+
+	if err != nil {
+		return enrollment, err
+	}
+
+	sealed, err := mfaUseCase.secrets.Seal(secret, userID)
+
+	if err != nil {
+		return enrollment, err
+	}
+
+	mfa = domain.UserMFA{
+		UserID:     userID,
+		TOTPSecret: sealed,
+	}
+
+	if err = mfaUseCase.mfaRepository.Save(ctx, &mfa); err != nil {
+		return enrollment, err
+	}
+
+	return domain.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: helpers.TOTPProvisioningURI(mfaUseCase.issuer, account, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables the enrolled authenticator once it produced code, and
+// returns a new set of recovery codes. The codes and the enabled flag are
+// saved together, so a failure can't leave one without the other.
+func (mfaUseCase *mfaUseCase) ConfirmTOTP(ctx context.Context, userID string, code string) (recoveryCodes []string, err error) {
+	err = mfaUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		mfa := domain.UserMFA{}
+
+		if err := mfaUseCase.mfaRepository.GetByUserID(ctx, &mfa, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrMFANotEnrolled
+			}
+
+			return err
+		}
+
+		if mfa.Enabled {
+			return domain.ErrMFAAlreadyEnabled
+		}
+
+		secret, err := mfaUseCase.secrets.Open(mfa.TOTPSecret, userID)
+
+		if err != nil {
+			return err
+		}
+
+		step, ok := helpers.ValidateTOTP(secret, code, time.Now())
+
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+
+		now := time.Now()
+
+		mfa.Enabled = true
+		mfa.LastUsedStep = step
+		mfa.ConfirmedAt = &now
+
+		codes := make([]domain.RecoveryCode, recoveryCodeCount)
+		recoveryCodes = make([]string, recoveryCodeCount)
+
+		for i := range codes {
+			if recoveryCodes[i], err = generateRecoveryCode(); err != nil {
+				return err
+			}
+
+			codes[i].CodeHash = hashRecoveryCode(recoveryCodes[i])
+		}
+
+		if err = mfaUseCase.mfaRepository.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+			return err
+		}
+
+		return mfaUseCase.mfaRepository.Save(ctx, &mfa)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+func (mfaUseCase *mfaUseCase) IsEnabled(ctx context.Context, userID string) (enabled bool, err error) {
+	mfa := domain.UserMFA{}
+
+	if err = mfaUseCase.mfaRepository.GetByUserID(ctx, &mfa, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return mfa.Enabled, nil
+}
+
+// Verify accepts either a current TOTP code or an unused recovery code. Failed
+// attempts are throttled like password logins so codes can't be brute forced.
+func (mfaUseCase *mfaUseCase) Verify(ctx context.Context, userID string, code string) (err error) {
+	key := "mfa:" + userID
+	throttle := domain.LoginThrottle{}
+	now := time.Now()
+
+	if err = mfaUseCase.loginAttemptRepository.GetThrottle(ctx, &throttle, key); err != nil {
+		return err
+	}
+
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+		return &domain.LoginLockedError{RetryAfter: throttle.LockedUntil.Sub(now)}
+	}
+
+	mfa := domain.UserMFA{}
+
+	if err = mfaUseCase.mfaRepository.GetByUserID(ctx, &mfa, userID); err != nil {
+		return err
+	}
+
+	if !mfa.Enabled {
+		return domain.ErrMFANotEnrolled
+	}
+
+	secret, err := mfaUseCase.secrets.Open(mfa.TOTPSecret, userID)
+
+	if err != nil {
+		return err
+	}
+
+	valid := false
+
+	if step, ok := helpers.ValidateTOTP(secret, code, now); ok {
+		if valid, err = mfaUseCase.mfaRepository.UseStep(ctx, userID, step); err != nil {
+			return err
+		}
+	} else if valid, err = mfaUseCase.mfaRepository.UseRecoveryCode(ctx, userID, hashRecoveryCode(code)); err != nil {
+		return err
+	}
+
+	if !valid {
+		failures, err := mfaUseCase.loginAttemptRepository.RecordFailure(ctx, key)
+
+		if err != nil {
+			return err
+		}
+
+		if delay := mfaUseCase.loginPolicy.delay(failures, mfaUseCase.loginPolicy.AccountBackoffAfter); delay > 0 {
+			if err = mfaUseCase.loginAttemptRepository.Lock(ctx, key, now.Add(delay)); err != nil {
+				return err
+			}
+		}
+
+		return domain.ErrInvalidMFACode
+	}
+
+	return mfaUseCase.loginAttemptRepository.Reset(ctx, key)
+}
+
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+
+	return code[:8] + "-" + code[8:16], nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"api-mygram-go/config"
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"api-mygram-go/helpers"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mfaRepositoryInTx counts the writes made outside of tx.
+type mfaRepositoryInTx struct {
+	*fakes.MFARepository
+	tx      *committingTxManager
+	outside int
+}
+
+func (repository *mfaRepositoryInTx) Save(ctx context.Context, mfa *domain.UserMFA) error {
+	if !repository.tx.open {
+		repository.outside++
+	}
+
+	return repository.MFARepository.Save(ctx, mfa)
+}
+
+func (repository *mfaRepositoryInTx) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []domain.RecoveryCode) error {
+	if !repository.tx.open {
+		repository.outside++
+	}
+
+	return repository.MFARepository.ReplaceRecoveryCodes(ctx, userID, codes)
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	ctx := context.Background()
+	tx := &committingTxManager{}
+	mfas := &mfaRepositoryInTx{MFARepository: fakes.NewMFARepository(), tx: tx}
+	useCase := NewMFAUseCase(mfas, fakes.NewLoginAttemptRepository(), tx, config.TOTP{Issuer: "MyGram", EncryptionKey: "totp-key"})
+
+	enrollment, err := useCase.EnrollTOTP(ctx, "user-1", "johndoe@example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(enrollment.ProvisioningURI, "secret="+enrollment.Secret) {
+		t.Errorf("provisioning uri %s doesn't hold the secret", enrollment.ProvisioningURI)
+	}
+
+	var stored domain.UserMFA
+
+	if err = mfas.GetByUserID(ctx, &stored, "user-1"); err != nil || strings.Contains(stored.TOTPSecret, enrollment.Secret) {
+		t.Errorf("stored secret %q, %v, want it encrypted", stored.TOTPSecret, err)
+	}
+
+	if _, err = useCase.ConfirmTOTP(ctx, "user-1", "000000"); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("confirming with a wrong code returned %v, want ErrInvalidMFACode", err)
+	}
+
+	mfas.outside = 0
+	code, _ := helpers.TOTPCode(enrollment.Secret, helpers.TOTPStep(time.Now()))
+	recoveryCodes, err := useCase.ConfirmTOTP(ctx, "user-1", code)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Errorf("%d recovery codes, want %d", len(recoveryCodes), recoveryCodeCount)
+	}
+
+	if mfas.outside != 0 {
+		t.Errorf("%d writes were made outside of the transaction", mfas.outside)
+	}
+
+	if enabled, err := useCase.IsEnabled(ctx, "user-1"); err != nil || !enabled {
+		t.Errorf("enabled = %t, %v after confirming", enabled, err)
+	}
+
+	if _, err = useCase.ConfirmTOTP(ctx, "user-1", code); !errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+		t.Errorf("confirming twice returned %v, want ErrMFAAlreadyEnabled", err)
+	}
+
+	if _, err = useCase.EnrollTOTP(ctx, "user-1", "johndoe@example.com"); !errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+		t.Errorf("enrolling again returned %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	ctx := context.Background()
+	useCase := NewMFAUseCase(fakes.NewMFARepository(), fakes.NewLoginAttemptRepository(), fakes.TxManager{}, config.TOTP{Issuer: "MyGram", EncryptionKey: "totp-key"})
+
+	enrollment, err := useCase.EnrollTOTP(ctx, "user-1", "johndoe@example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step := helpers.TOTPStep(time.Now())
+	code, _ := helpers.TOTPCode(enrollment.Secret, step)
+	recoveryCodes, err := useCase.ConfirmTOTP(ctx, "user-1", code)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = useCase.Verify(ctx, "user-1", code); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("reusing the confirmation code returned %v, want ErrInvalidMFACode", err)
+	}
+
+	next, _ := helpers.TOTPCode(enrollment.Secret, step+1)
+
+	if err = useCase.Verify(ctx, "user-1", next); err != nil {
+		t.Errorf("verifying the next code returned %v", err)
+	}
+
+	if err = useCase.Verify(ctx, "user-1", strings.ToUpper(recoveryCodes[0])); err != nil {
+		t.Errorf("verifying a recovery code returned %v", err)
+	}
+
+	if err = useCase.Verify(ctx, "user-1", recoveryCodes[0]); !errors.Is(err, domain.ErrInvalidMFACode) {
+		t.Errorf("reusing a recovery code returned %v, want ErrInvalidMFACode", err)
+	}
+
+	for i := 1; i < DefaultLoginPolicy.AccountBackoffAfter; i++ {
+		if err = useCase.Verify(ctx, "user-1", "000000"); !errors.Is(err, domain.ErrInvalidMFACode) {
+			t.Fatalf("failure %d returned %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	var locked *domain.LoginLockedError
+
+	if err = useCase.Verify(ctx, "user-1", recoveryCodes[1]); !errors.As(err, &locked) {
+		t.Errorf("verifying after %d failures returned %v, want a lockout", DefaultLoginPolicy.AccountBackoffAfter, err)
+	}
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/helpers"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval limits how often last_seen_at is written for a
+// session that makes requests continuously.
+const sessionTouchInterval = time.Minute
+
+type sessionUseCase struct {
+	sessionRepository domain.SessionRepository
+	ttl               time.Duration
+}
+
+// NewSessionUseCase keeps sessions for ttl, the lifetime of the access tokens
+// issued with them.
+func NewSessionUseCase(sessionRepository domain.SessionRepository, ttl time.Duration) *sessionUseCase {
+	return &sessionUseCase{sessionRepository, ttl}
+}
+
+// Start records a session for a successful login. It lives as long as the
+// access token issued with it.
+func (sessionUseCase *sessionUseCase) Start(ctx context.Context, userID string, info domain.LoginInfo) (session domain.Session, err error) {
+	now := time.Now()
+	expiresAt := now.Add(sessionUseCase.ttl)
+	deviceName := info.DeviceName
+
+	if deviceName == "" {
+		deviceName = helpers.DeviceName(info.UserAgent)
+	}
+
+	session = domain.Session{
+		UserID:     userID,
+		DeviceName: truncate(deviceName, 100),
+		IPAddress:  info.IPAddress,
+		UserAgent:  info.UserAgent,
+		ExpiresAt:  &expiresAt,
+		LastSeenAt: &now,
+	}
+
+	if err = sessionUseCase.sessionRepository.Store(ctx, &session); err != nil {
+		return domain.Session{}, err
+	}
+
+	return session, nil
+}
+
+func (sessionUseCase *sessionUseCase) Fetch(ctx context.Context, sessions *[]domain.Session, userID string) (err error) {
+	if err = sessionUseCase.sessionRepository.Fetch(ctx, sessions, userID, time.Now()); err != nil {
+		return err
+	}
+
+	return
+}
+
+func (sessionUseCase *sessionUseCase) Revoke(ctx context.Context, id string, userID string) (err error) {
+	if err = sessionUseCase.sessionRepository.Revoke(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return
+}
+
+// Validate returns domain.ErrSessionRevoked unless the session is active and
+// records that it was seen.
+func (sessionUseCase *sessionUseCase) Validate(ctx context.Context, id string) (err error) {
+	var session domain.Session
+
+	if err = sessionUseCase.sessionRepository.GetByID(ctx, &session, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrSessionRevoked
+		}
+
+		return err
+	}
+
+	now := time.Now()
+
+	if !session.Active(now) {
+		return domain.ErrSessionRevoked
+	}
+
+	if session.LastSeenAt == nil || now.Sub(*session.LastSeenAt) > sessionTouchInterval {
+		_ = sessionUseCase.sessionRepository.Touch(ctx, session.ID, now)
+	}
+
+	return
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestStartCutsTheDeviceNameByCharacter(t *testing.T) {
+	useCase := NewSessionUseCase(fakes.NewSessionRepository(), time.Hour)
+
+	session, err := useCase.Start(context.Background(), "user-1", domain.LoginInfo{DeviceName: strings.Repeat("é", 120)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !utf8.ValidString(session.DeviceName) || utf8.RuneCountInString(session.DeviceName) != 100 {
+		t.Errorf("device name = %q, want the first 100 characters", session.DeviceName)
+	}
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/tracing"
+	"context"
+	"io"
+	"time"
+)
+
+type tracedUserUseCase struct {
+	next domain.UserUseCase
+}
+
+// NewTracedUserUseCase wraps next so every call is recorded as a span.
+func NewTracedUserUseCase(next domain.UserUseCase) *tracedUserUseCase {
+	return &tracedUserUseCase{next}
+}
+
+func (traced *tracedUserUseCase) Register(ctx context.Context, user *domain.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.Register")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Register(ctx, user)
+}
+
+func (traced *tracedUserUseCase) Login(ctx context.Context, user *domain.User, info domain.LoginInfo) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.Login")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Login(ctx, user, info)
+}
+
+func (traced *tracedUserUseCase) Update(ctx context.Context, userID string, update domain.UserUpdate) (user domain.User, change *domain.EmailChange, err error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.Update")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Update(ctx, userID, update)
+}
+
+func (traced *tracedUserUseCase) ConfirmEmail(ctx context.Context, token string) (user domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.ConfirmEmail")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.ConfirmEmail(ctx, token)
+}
+
+type tracedMFAUseCase struct {
+	next domain.MFAUseCase
+}
+
+// NewTracedMFAUseCase wraps next so every call is recorded as a span.
+func NewTracedMFAUseCase(next domain.MFAUseCase) *tracedMFAUseCase {
+	return &tracedMFAUseCase{next}
+}
+
+func (traced *tracedMFAUseCase) EnrollTOTP(ctx context.Context, userID string, email string) (enrollment domain.TOTPEnrollment, err error) {
+	ctx, span := tracing.Start(ctx, "MFAUseCase.EnrollTOTP")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.EnrollTOTP(ctx, userID, email)
+}
+
+func (traced *tracedMFAUseCase) ConfirmTOTP(ctx context.Context, userID string, code string) (recoveryCodes []string, err error) {
+	ctx, span := tracing.Start(ctx, "MFAUseCase.ConfirmTOTP")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.ConfirmTOTP(ctx, userID, code)
+}
+
+func (traced *tracedMFAUseCase) IsEnabled(ctx context.Context, userID string) (enabled bool, err error) {
+	ctx, span := tracing.Start(ctx, "MFAUseCase.IsEnabled")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.IsEnabled(ctx, userID)
+}
+
+func (traced *tracedMFAUseCase) Verify(ctx context.Context, userID string, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "MFAUseCase.Verify")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Verify(ctx, userID, code)
+}
+
+type tracedAPIKeyUseCase struct {
+	next domain.APIKeyUseCase
+}
+
+// NewTracedAPIKeyUseCase wraps next so every call is recorded as a span.
+func NewTracedAPIKeyUseCase(next domain.APIKeyUseCase) *tracedAPIKeyUseCase {
+	return &tracedAPIKeyUseCase{next}
+}
+
+func (traced *tracedAPIKeyUseCase) Create(ctx context.Context, apiKey *domain.APIKey, scopes []string) (key string, err error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.Create")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Create(ctx, apiKey, scopes)
+}
+
+func (traced *tracedAPIKeyUseCase) Fetch(ctx context.Context, apiKeys *[]domain.APIKey, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.Fetch")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Fetch(ctx, apiKeys, userID)
+}
+
+func (traced *tracedAPIKeyUseCase) Revoke(ctx context.Context, id string, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.Revoke")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Revoke(ctx, id, userID)
+}
+
+func (traced *tracedAPIKeyUseCase) Authenticate(ctx context.Context, key string) (apiKey domain.APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.Authenticate")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Authenticate(ctx, key)
+}
+
+type tracedSessionUseCase struct {
+	next domain.SessionUseCase
+}
+
+// NewTracedSessionUseCase wraps next so every call is recorded as a span.
+func NewTracedSessionUseCase(next domain.SessionUseCase) *tracedSessionUseCase {
+	return &tracedSessionUseCase{next}
+}
+
+func (traced *tracedSessionUseCase) Start(ctx context.Context, userID string, info domain.LoginInfo) (session domain.Session, err error) {
+	ctx, span := tracing.Start(ctx, "SessionUseCase.Start")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Start(ctx, userID, info)
+}
+
+func (traced *tracedSessionUseCase) Fetch(ctx context.Context, sessions *[]domain.Session, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionUseCase.Fetch")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Fetch(ctx, sessions, userID)
+}
+
+func (traced *tracedSessionUseCase) Revoke(ctx context.Context, id string, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionUseCase.Revoke")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Revoke(ctx, id, userID)
+}
+
+func (traced *tracedSessionUseCase) Validate(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionUseCase.Validate")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Validate(ctx, id)
+}
+
+type tracedAccountDeletionUseCase struct {
+	next domain.AccountDeletionUseCase
+}
+
+// NewTracedAccountDeletionUseCase wraps next so every call is recorded as a
+// span.
+func NewTracedAccountDeletionUseCase(next domain.AccountDeletionUseCase) *tracedAccountDeletionUseCase {
+	return &tracedAccountDeletionUseCase{next}
+}
+
+func (traced *tracedAccountDeletionUseCase) Schedule(ctx context.Context, userID string) (deletion domain.AccountDeletion, err error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionUseCase.Schedule")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Schedule(ctx, userID)
+}
+
+func (traced *tracedAccountDeletionUseCase) GetPending(ctx context.Context, deletion *domain.AccountDeletion, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionUseCase.GetPending")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.GetPending(ctx, deletion, userID)
+}
+
+func (traced *tracedAccountDeletionUseCase) Cancel(ctx context.Context, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionUseCase.Cancel")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Cancel(ctx, userID)
+}
+
+func (traced *tracedAccountDeletionUseCase) PurgeDue(ctx context.Context, now time.Time) (purged int, err error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionUseCase.PurgeDue")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.PurgeDue(ctx, now)
+}
+
+type tracedDataExportUseCase struct {
+	next domain.DataExportUseCase
+}
+
+// NewTracedDataExportUseCase wraps next so every call is recorded as a span.
+func NewTracedDataExportUseCase(next domain.DataExportUseCase) *tracedDataExportUseCase {
+	return &tracedDataExportUseCase{next}
+}
+
+func (traced *tracedDataExportUseCase) Request(ctx context.Context, userID string) (export domain.DataExport, err error) {
+	ctx, span := tracing.Start(ctx, "DataExportUseCase.Request")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Request(ctx, userID)
+}
+
+func (traced *tracedDataExportUseCase) GetByID(ctx context.Context, export *domain.DataExport, id string, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "DataExportUseCase.GetByID")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.GetByID(ctx, export, id, userID)
+}
+
+// Link only signs, so it isn't worth a span.
+func (traced *tracedDataExportUseCase) Link(export domain.DataExport) domain.DataExportLink {
+	return traced.next.Link(export)
+}
+
+func (traced *tracedDataExportUseCase) Open(ctx context.Context, id string, link domain.DataExportLink) (file io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "DataExportUseCase.Open")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Open(ctx, id, link)
+}
+
+func (traced *tracedDataExportUseCase) Process(ctx context.Context) (processed int, err error) {
+	ctx, span := tracing.Start(ctx, "DataExportUseCase.Process")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Process(ctx)
+}
+
+type tracedAvatarUseCase struct {
+	next domain.AvatarUseCase
+}
+
+// NewTracedAvatarUseCase wraps next so every call is recorded as a span.
+func NewTracedAvatarUseCase(next domain.AvatarUseCase) *tracedAvatarUseCase {
+	return &tracedAvatarUseCase{next}
+}
+
+func (traced *tracedAvatarUseCase) Set(ctx context.Context, userID string, r io.Reader) (user domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "AvatarUseCase.Set")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Set(ctx, userID, r)
+}
+
+func (traced *tracedAvatarUseCase) Open(ctx context.Context, userID string, file string) (r io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "AvatarUseCase.Open")
+
+	defer func() { tracing.End(span, err) }()
+
+	return traced.next.Open(ctx, userID, file)
+}
@@ -1,16 +1,57 @@
 package usecase
 
 import (
-	"context"
 	"api-mygram-go/domain"
+	"api-mygram-go/logging"
+	"api-mygram-go/metrics"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// LoginPolicy controls how failed logins slow down further attempts. Once a key
+// reaches BackoffAfter failures it is locked for BaseDelay, doubling with each
+// further failure up to MaxDelay. Failures older than ResetAfter are forgotten.
+type LoginPolicy struct {
+	AccountBackoffAfter int
+	IPBackoffAfter      int
+	BaseDelay           time.Duration
+	MaxDelay            time.Duration
+	ResetAfter          time.Duration
+}
+
+var DefaultLoginPolicy = LoginPolicy{
+	AccountBackoffAfter: 5,
+	IPBackoffAfter:      20,
+	BaseDelay:           time.Second,
+	MaxDelay:            15 * time.Minute,
+	ResetAfter:          time.Hour,
+}
+
 type userUseCase struct {
-	userRepository domain.UserRepository
+	userRepository         domain.UserRepository
+	loginAttemptRepository domain.LoginAttemptRepository
+	emailChangeRepository  domain.EmailChangeRepository
+	mailer                 domain.Mailer
+	txManager              domain.TxManager
+	loginPolicy            LoginPolicy
+	verificationTTL        time.Duration
+	verificationURL        string
 }
 
-func NewUserUseCase(userRepository domain.UserRepository) *userUseCase {
-	return &userUseCase{userRepository}
+// NewUserUseCase returns the user usecase. Email changes are confirmed with
+// a link valid for verificationTTL, built from verificationURL when it is set.
+func NewUserUseCase(userRepository domain.UserRepository, loginAttemptRepository domain.LoginAttemptRepository, emailChangeRepository domain.EmailChangeRepository, mailer domain.Mailer, txManager domain.TxManager, verificationTTL time.Duration, verificationURL string) *userUseCase {
+	return &userUseCase{userRepository, loginAttemptRepository, emailChangeRepository, mailer, txManager, DefaultLoginPolicy, verificationTTL, verificationURL}
 }
 
 func (userUseCase *userUseCase) Register(ctx context.Context, user *domain.User) (err error) {
@@ -18,29 +59,304 @@ func (userUseCase *userUseCase) Register(ctx context.Context, user *domain.User)
 		return err
 	}
 
+	metrics.UsersRegistered.Inc()
+
 	return
 }
 
-func (userUseCase *userUseCase) Login(ctx context.Context, user *domain.User) (err error) {
+func (userUseCase *userUseCase) Login(ctx context.Context, user *domain.User, info domain.LoginInfo) (err error) {
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+	keys := map[string]int{
+		domain.EmailThrottleKey(email):       userUseCase.loginPolicy.AccountBackoffAfter,
+		domain.IPThrottleKey(info.IPAddress): userUseCase.loginPolicy.IPBackoffAfter,
+	}
+
+	event := domain.LoginEvent{
+		Email:     email,
+		IPAddress: info.IPAddress,
+		UserAgent: info.UserAgent,
+	}
+
+	now := time.Now()
+
+	for key := range keys {
+		throttle := domain.LoginThrottle{}
+
+		if err = userUseCase.loginAttemptRepository.GetThrottle(ctx, &throttle, key); err != nil {
+			return err
+		}
+
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			event.Reason = "locked"
+			userUseCase.recordLoginEvent(ctx, event)
+			metrics.Logins.WithLabelValues(event.Reason).Inc()
+
+			return &domain.LoginLockedError{RetryAfter: throttle.LockedUntil.Sub(now)}
+		}
+
+		if throttle.UpdatedAt != nil && now.Sub(*throttle.UpdatedAt) > userUseCase.loginPolicy.ResetAfter {
+			if err = userUseCase.loginAttemptRepository.Reset(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
 	if err = userUseCase.userRepository.Login(ctx, user); err != nil {
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			return err
+		}
+
+		for key, backoffAfter := range keys {
+			failures, err := userUseCase.loginAttemptRepository.RecordFailure(ctx, key)
+
+			if err != nil {
+				return err
+			}
+
+			if delay := userUseCase.loginPolicy.delay(failures, backoffAfter); delay > 0 {
+				if err = userUseCase.loginAttemptRepository.Lock(ctx, key, now.Add(delay)); err != nil {
+					return err
+				}
+			}
+		}
+
+		event.UserID = user.ID
+		event.Reason = "invalid_credentials"
+		userUseCase.recordLoginEvent(ctx, event)
+		metrics.Logins.WithLabelValues(event.Reason).Inc()
+
+		return domain.ErrInvalidCredentials
+	}
+
+	if err = userUseCase.loginAttemptRepository.Reset(ctx, domain.EmailThrottleKey(email)); err != nil {
 		return err
 	}
 
+	event.UserID = user.ID
+	event.Success = true
+	userUseCase.recordLoginEvent(ctx, event)
+	metrics.Logins.WithLabelValues("success").Inc()
+
 	return
 }
 
-func (userUseCase *userUseCase) Update(ctx context.Context, user domain.User) (u domain.User, err error) {
-	if u, err = userUseCase.userRepository.Update(ctx, user); err != nil {
-		return u, err
+// recordLoginEvent stores event, cutting the values the client chose to the
+// size of their columns. A login doesn't fail for want of its audit record,
+// which is only logged as missing.
+func (userUseCase *userUseCase) recordLoginEvent(ctx context.Context, event domain.LoginEvent) {
+	event.Email = truncate(event.Email, 50)
+	event.IPAddress = truncate(event.IPAddress, 64)
+
+	if err := userUseCase.loginAttemptRepository.StoreEvent(ctx, &event); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "recording the login event", "error", err, "reason", event.Reason)
+	}
+}
+
+// truncate cuts s to at most n characters.
+func truncate(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+
+		n--
 	}
 
-	return u, nil
+	return s
 }
 
-func (userUseCase *userUseCase) Delete(ctx context.Context, id string) (err error) {
-	if err = userUseCase.userRepository.Delete(ctx, id); err != nil {
+func (policy LoginPolicy) delay(failures, backoffAfter int) time.Duration {
+	if failures < backoffAfter {
+		return 0
+	}
+
+	delay := policy.BaseDelay
+
+	for i := backoffAfter; i < failures && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	return delay
+}
+
+// Update changes the profile of the user with userID. A new email address is
+// kept aside and a verification link is mailed to it, the returned change
+// describes it. The mail is sent once the change is committed, so a slow
+// mail server doesn't hold the transaction open.
+func (userUseCase *userUseCase) Update(ctx context.Context, userID string, update domain.UserUpdate) (user domain.User, change *domain.EmailChange, err error) {
+	var verification domain.Mail
+
+	err = userUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		if err := userUseCase.userRepository.GetByID(ctx, &user, userID); err != nil {
+			return err
+		}
+
+		if update.Username != nil {
+			user.Username = strings.TrimSpace(*update.Username)
+		}
+
+		if update.Age != nil {
+			user.Age = *update.Age
+		}
+
+		if update.ProfileImageUrl != nil {
+			user.ProfileImageUrl = strings.TrimSpace(*update.ProfileImageUrl)
+		}
+
+		if update.DisplayName != nil {
+			user.DisplayName = strings.TrimSpace(*update.DisplayName)
+		}
+
+		if update.Bio != nil {
+			user.Bio = strings.TrimSpace(*update.Bio)
+		}
+
+		var newEmail string
+
+		if update.Email != nil {
+			email := strings.TrimSpace(*update.Email)
+
+			if strings.EqualFold(email, user.Email) {
+				user.Email = email
+			} else {
+				newEmail = email
+			}
+		}
+
+		updated, err := userUseCase.userRepository.Update(ctx, user)
+
+		if err != nil {
+			return err
+		}
+
+		user = updated
+
+		if newEmail == "" {
+			return nil
+		}
+
+		change, verification, err = userUseCase.requestEmailChange(ctx, user, newEmail)
+
 		return err
+	})
+
+	if err != nil {
+		return domain.User{}, nil, err
 	}
 
-	return
+	if change != nil {
+		if err = userUseCase.mailer.Send(ctx, verification); err != nil {
+			return domain.User{}, nil, fmt.Errorf("sending the verification email: %w", err)
+		}
+	}
+
+	return user, change, nil
+}
+
+// requestEmailChange stores the change of user to email and returns the
+// mail with its verification link.
+func (userUseCase *userUseCase) requestEmailChange(ctx context.Context, user domain.User, email string) (*domain.EmailChange, domain.Mail, error) {
+	existing := domain.User{}
+
+	if err := userUseCase.userRepository.GetByEmail(ctx, &existing, email); err == nil {
+		return nil, domain.Mail{}, domain.ErrEmailTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.Mail{}, err
+	}
+
+	token, err := generateVerificationToken()
+
+	if err != nil {
+		return nil, domain.Mail{}, err
+	}
+
+	expiresAt := time.Now().Add(userUseCase.verificationTTL)
+	change := domain.EmailChange{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: hashVerificationToken(token),
+		ExpiresAt: &expiresAt,
+	}
+
+	if err = userUseCase.emailChangeRepository.Store(ctx, &change); err != nil {
+		return nil, domain.Mail{}, err
+	}
+
+	link := token
+
+	if userUseCase.verificationURL != "" {
+		link = userUseCase.verificationURL + "?" + url.Values{"token": {token}}.Encode()
+	}
+
+	mail := domain.Mail{
+		To:      email,
+		Subject: "Confirm your new MyGram email address",
+		Body: fmt.Sprintf("Hi %s,\n\nconfirm that %s is your new MyGram email address with this link before %s:\n\n%s\n\nYour current address stays in use until then. If you didn't ask for the change, ignore this email.\n",
+			user.Username, email, expiresAt.UTC().Format("2006-01-02 15:04 UTC"), link),
+	}
+
+	return &change, mail, nil
+}
+
+// ConfirmEmail switches the user who received token to the email address it
+// was sent to.
+func (userUseCase *userUseCase) ConfirmEmail(ctx context.Context, token string) (user domain.User, err error) {
+	err = userUseCase.txManager.Do(ctx, func(ctx context.Context) error {
+		change := domain.EmailChange{}
+		now := time.Now()
+
+		if err := userUseCase.emailChangeRepository.GetPending(ctx, &change, hashVerificationToken(strings.TrimSpace(token))); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrEmailChangeInvalid
+			}
+
+			return err
+		}
+
+		if change.ExpiresAt == nil || !change.ExpiresAt.After(now) {
+			return domain.ErrEmailChangeInvalid
+		}
+
+		if err := userUseCase.userRepository.GetByID(ctx, &user, change.UserID); err != nil {
+			return err
+		}
+
+		user.Email = change.Email
+
+		updated, err := userUseCase.userRepository.Update(ctx, user)
+
+		if err != nil {
+			return err
+		}
+
+		user = updated
+
+		return userUseCase.emailChangeRepository.Confirm(ctx, change.ID, now)
+	})
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func generateVerificationToken() (string, error) {
+	buf := make([]byte, 32)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
 }
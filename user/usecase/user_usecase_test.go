@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"api-mygram-go/domain"
+	"api-mygram-go/domain/fakes"
+	"api-mygram-go/logging"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoginLocksTheAccountAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	loginAttempts := fakes.NewLoginAttemptRepository()
+	useCase := NewUserUseCase(fakes.NewUserRepository(), loginAttempts, fakes.NewEmailChangeRepository(), &fakes.Mailer{}, fakes.TxManager{}, time.Hour, "")
+	info := domain.LoginInfo{IPAddress: "203.0.113.7", UserAgent: "test"}
+
+	if err := useCase.Register(ctx, &domain.User{Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	user := domain.User{Email: "johndoe@example.com", Password: "secret"}
+
+	if err := useCase.Login(ctx, &user, info); err != nil {
+		t.Fatalf("login with the right password failed: %v", err)
+	}
+
+	for i := 0; i < DefaultLoginPolicy.AccountBackoffAfter; i++ {
+		err := useCase.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "wrong"}, info)
+
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("failure %d returned %v, want invalid credentials", i+1, err)
+		}
+	}
+
+	var locked *domain.LoginLockedError
+
+	if err := useCase.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "secret"}, info); !errors.As(err, &locked) {
+		t.Fatalf("login after %d failures returned %v, want a lockout", DefaultLoginPolicy.AccountBackoffAfter, err)
+	}
+
+	events := loginAttempts.Events()
+	reasons := map[string]int{}
+
+	for _, event := range events {
+		if event.Success {
+			reasons["success"]++
+		} else {
+			reasons[event.Reason]++
+		}
+	}
+
+	want := map[string]int{"success": 1, "invalid_credentials": DefaultLoginPolicy.AccountBackoffAfter, "locked": 1}
+
+	for reason, count := range want {
+		if reasons[reason] != count {
+			t.Errorf("%d %s events recorded, want %d", reasons[reason], reason, count)
+		}
+	}
+}
+
+func TestLoginThrottlesPerAddressAndForgetsAfterSuccess(t *testing.T) {
+	ctx := context.Background()
+	useCase := NewUserUseCase(fakes.NewUserRepository(), fakes.NewLoginAttemptRepository(), fakes.NewEmailChangeRepository(), &fakes.Mailer{}, fakes.TxManager{}, time.Hour, "")
+	info := domain.LoginInfo{IPAddress: "203.0.113.7", UserAgent: "test"}
+
+	if err := useCase.Register(ctx, &domain.User{Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Failures below the account threshold are forgotten once the right
+	// password is given.
+	for round := 0; round < 2; round++ {
+		for i := 1; i < DefaultLoginPolicy.AccountBackoffAfter; i++ {
+			if err := useCase.Login(ctx, &domain.User{Email: "JohnDoe@example.com", Password: "wrong"}, info); !errors.Is(err, domain.ErrInvalidCredentials) {
+				t.Fatalf("failure %d returned %v, want invalid credentials", i, err)
+			}
+		}
+
+		if err := useCase.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "secret"}, info); err != nil {
+			t.Fatalf("round %d: login with the right password returned %v", round+1, err)
+		}
+	}
+
+	// Guessing across accounts from one address locks the address.
+	failures := 2 * (DefaultLoginPolicy.AccountBackoffAfter - 1)
+
+	for i := failures; i < DefaultLoginPolicy.IPBackoffAfter; i++ {
+		email := fmt.Sprintf("user%d@example.com", i)
+
+		if err := useCase.Login(ctx, &domain.User{Email: email, Password: "wrong"}, info); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("failure %d returned %v, want invalid credentials", i+1, err)
+		}
+	}
+
+	var locked *domain.LoginLockedError
+
+	if err := useCase.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "secret"}, info); !errors.As(err, &locked) {
+		t.Errorf("login from the address after %d failures returned %v, want a lockout", DefaultLoginPolicy.IPBackoffAfter, err)
+	}
+
+	if err := useCase.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "secret"}, domain.LoginInfo{IPAddress: "198.51.100.1"}); err != nil {
+		t.Errorf("login from another address returned %v", err)
+	}
+}
+
+// failingLoginEvents fails to store login events.
+type failingLoginEvents struct {
+	*fakes.LoginAttemptRepository
+}
+
+func (failingLoginEvents) StoreEvent(ctx context.Context, event *domain.LoginEvent) error {
+	return errors.New("value too long for type character varying(50)")
+}
+
+func TestLoginKeepsOversizedValuesOutOfTheThrottleKeysAndEvents(t *testing.T) {
+	ctx := context.Background()
+	loginAttempts := fakes.NewLoginAttemptRepository()
+	useCase := NewUserUseCase(fakes.NewUserRepository(), loginAttempts, fakes.NewEmailChangeRepository(), &fakes.Mailer{}, fakes.TxManager{}, time.Hour, "")
+	email := strings.Repeat("a", 240) + "@example.com"
+	info := domain.LoginInfo{IPAddress: strings.Repeat("f", 100), UserAgent: "test"}
+
+	if err := useCase.Login(ctx, &domain.User{Email: email, Password: "wrong"}, info); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("login returned %v, want invalid credentials", err)
+	}
+
+	for _, key := range []string{domain.EmailThrottleKey(email), domain.IPThrottleKey(info.IPAddress)} {
+		var throttle domain.LoginThrottle
+
+		if err := loginAttempts.GetThrottle(ctx, &throttle, key); err != nil || throttle.Failures != 1 || len(key) > 120 {
+			t.Errorf("throttle %q = %+v, %v, want one failure under a key of at most 120 characters", key, throttle, err)
+		}
+	}
+
+	events := loginAttempts.Events()
+
+	if len(events) != 1 || len(events[0].Email) > 50 || len(events[0].IPAddress) > 64 {
+		t.Errorf("events = %+v, want one fitting its columns", events)
+	}
+}
+
+func TestLoginLogsTheEventsItCantRecord(t *testing.T) {
+	var buf bytes.Buffer
+
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+	useCase := NewUserUseCase(fakes.NewUserRepository(), failingLoginEvents{fakes.NewLoginAttemptRepository()}, fakes.NewEmailChangeRepository(), &fakes.Mailer{}, fakes.TxManager{}, time.Hour, "")
+
+	if err := useCase.Register(ctx, &domain.User{Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := useCase.Login(ctx, &domain.User{Email: "johndoe@example.com", Password: "secret"}, domain.LoginInfo{IPAddress: "203.0.113.7"}); err != nil {
+		t.Fatalf("login returned %v, want it to succeed without its event", err)
+	}
+
+	if !strings.Contains(buf.String(), "recording the login event") || !strings.Contains(buf.String(), "character varying(50)") {
+		t.Errorf("logged %q, want the failure to record the event", buf.String())
+	}
+}
+
+func TestLoginPolicyDelay(t *testing.T) {
+	policy := DefaultLoginPolicy
+
+	tests := []struct {
+		failures int
+		want     string
+	}{
+		{4, "0s"},
+		{5, "1s"},
+		{6, "2s"},
+		{8, "8s"},
+		{50, "15m0s"},
+	}
+
+	for _, test := range tests {
+		if got := policy.delay(test.failures, policy.AccountBackoffAfter).String(); got != test.want {
+			t.Errorf("delay after %d failures = %s, want %s", test.failures, got, test.want)
+		}
+	}
+}
+
+func TestUpdateKeepsTheEmailUntilTheNewOneIsConfirmed(t *testing.T) {
+	ctx := context.Background()
+	users := fakes.NewUserRepository()
+	mailer := &fakes.Mailer{}
+	useCase := NewUserUseCase(users, fakes.NewLoginAttemptRepository(), fakes.NewEmailChangeRepository(), mailer, fakes.TxManager{}, time.Hour, "https://mygram.example.com/verify-email")
+
+	johndoe := domain.User{Username: "johndoe", Email: "johndoe@example.com", Password: "secret", Age: 20}
+	janedoe := domain.User{Username: "janedoe", Email: "janedoe@example.com", Password: "secret", Age: 20}
+
+	for _, user := range []*domain.User{&johndoe, &janedoe} {
+		if err := useCase.Register(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	username, bio, email := "johnny", "Sunsets", "johnny@example.com"
+
+	user, change, err := useCase.Update(ctx, johndoe.ID, domain.UserUpdate{Username: &username, Bio: &bio, Email: &email})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Username != "johnny" || user.Bio != "Sunsets" || user.Email != "johndoe@example.com" {
+		t.Errorf("updated user = %+v, want the new username and bio with the old email", user)
+	}
+
+	if change == nil || change.Email != "johnny@example.com" {
+		t.Fatalf("email change = %+v, want one to johnny@example.com", change)
+	}
+
+	mails := mailer.Mails()
+
+	if len(mails) != 1 || mails[0].To != "johnny@example.com" {
+		t.Fatalf("mails = %+v, want one verification mail to johnny@example.com", mails)
+	}
+
+	token := regexp.MustCompile(`verify-email\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(mails[0].Body)
+
+	if token == nil {
+		t.Fatalf("no verification link in %q", mails[0].Body)
+	}
+
+	taken := "janedoe@example.com"
+
+	if _, _, err = useCase.Update(ctx, johndoe.ID, domain.UserUpdate{Email: &taken}); !errors.Is(err, domain.ErrEmailTaken) {
+		t.Errorf("update to a taken email returned %v, want ErrEmailTaken", err)
+	}
+
+	if _, _, err = useCase.Update(ctx, janedoe.ID, domain.UserUpdate{Username: &username}); !errors.Is(err, domain.ErrUsernameTaken) {
+		t.Errorf("update to a taken username returned %v, want ErrUsernameTaken", err)
+	}
+
+	if user, err = useCase.ConfirmEmail(ctx, token[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Email != "johnny@example.com" {
+		t.Errorf("email after confirming = %s, want johnny@example.com", user.Email)
+	}
+
+	if _, err = useCase.ConfirmEmail(ctx, token[1]); !errors.Is(err, domain.ErrEmailChangeInvalid) {
+		t.Errorf("confirming twice returned %v, want ErrEmailChangeInvalid", err)
+	}
+}
+
+func TestConfirmEmailRejectsExpiredLinks(t *testing.T) {
+	ctx := context.Background()
+	users := fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Age: 20})
+	mailer := &fakes.Mailer{}
+	useCase := NewUserUseCase(users, fakes.NewLoginAttemptRepository(), fakes.NewEmailChangeRepository(), mailer, fakes.TxManager{}, -time.Minute, "")
+
+	email := "johnny@example.com"
+
+	if _, _, err := useCase.Update(ctx, "user-1", domain.UserUpdate{Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := mailer.Mails()[0].Body
+	token := strings.TrimSpace(strings.SplitN(strings.SplitN(body, ":\n\n", 2)[1], "\n", 2)[0])
+
+	if _, err := useCase.ConfirmEmail(ctx, token); !errors.Is(err, domain.ErrEmailChangeInvalid) {
+		t.Errorf("confirming an expired link returned %v, want ErrEmailChangeInvalid", err)
+	}
+}
+
+// committingTxManager reports whether a transaction is open.
+type committingTxManager struct {
+	open bool
+}
+
+func (tx *committingTxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx.open = true
+	defer func() { tx.open = false }()
+
+	return fn(ctx)
+}
+
+// mailerOutsideTx fails sends made while tx is open.
+type mailerOutsideTx struct {
+	fakes.Mailer
+	tx *committingTxManager
+}
+
+func (mailer *mailerOutsideTx) Send(ctx context.Context, mail domain.Mail) error {
+	if mailer.tx.open {
+		return errors.New("mail sent inside the transaction")
+	}
+
+	return mailer.Mailer.Send(ctx, mail)
+}
+
+func TestUpdateMailsTheVerificationLinkAfterCommitting(t *testing.T) {
+	ctx := context.Background()
+	users := fakes.NewUserRepository(domain.User{ID: "user-1", Username: "johndoe", Email: "johndoe@example.com", Age: 20})
+	tx := &committingTxManager{}
+	mailer := &mailerOutsideTx{tx: tx}
+	useCase := NewUserUseCase(users, fakes.NewLoginAttemptRepository(), fakes.NewEmailChangeRepository(), mailer, tx, time.Hour, "")
+
+	email := "johnny@example.com"
+
+	if _, _, err := useCase.Update(ctx, "user-1", domain.UserUpdate{Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	if mails := mailer.Mails(); len(mails) != 1 {
+		t.Errorf("mails = %+v, want the verification mail", mails)
+	}
+}
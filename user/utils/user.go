@@ -36,16 +36,39 @@ type ResponseDataLoggedinUser struct {
 }
 
 type UpdateUser struct {
-	Email    string `json:"email" example:"newjohndoe@example.com"`
-	Username string `json:"username" example:"newjohndoe"`
+	Email           string `json:"email" example:"newjohndoe@example.com"`
+	Username        string `json:"username" example:"newjohndoe"`
+	Age             uint   `json:"age" example:"9"`
+	ProfileImageUrl string `json:"profileImageUrl" example:"https://www.example.com/image.jpg"`
+	DisplayName     string `json:"display_name" example:"John Doe"`
+	Bio             string `json:"bio" example:"Sunsets and street photography"`
 }
 
 type UpdatedUser struct {
-	ID        string     `json:"id" example:"here is the generated user id"`
-	Email     string     `json:"email" example:"newjohndoe@example.com"`
-	Username  string     `json:"username" example:"newjohndoe"`
-	Age       uint       `json:"age" example:"8"`
-	UpdatedAt *time.Time `json:"updated_at" example:"the updated at generated here"`
+	ID                    string     `json:"id" example:"here is the generated user id"`
+	Email                 string     `json:"email" example:"johndoe@example.com"`
+	PendingEmail          string     `json:"pending_email,omitempty" example:"newjohndoe@example.com"`
+	PendingEmailExpiresAt *time.Time `json:"pending_email_expires_at,omitempty" example:"the verification link expires at generated here"`
+	Username              string     `json:"username" example:"newjohndoe"`
+	Age                   uint       `json:"age" example:"9"`
+	ProfileImageUrl       string     `json:"profileImageUrl,omitempty" example:"https://www.example.com/image.jpg"`
+	DisplayName           string     `json:"display_name,omitempty" example:"John Doe"`
+	Bio                   string     `json:"bio,omitempty" example:"Sunsets and street photography"`
+	UpdatedAt             *time.Time `json:"updated_at" example:"the updated at generated here"`
+}
+
+type Avatar struct {
+	ProfileImageUrl string            `json:"profileImageUrl" example:"/avatars/user-1/k3j5h2g8d9s1-256.jpg"`
+	Sizes           map[string]string `json:"sizes"`
+}
+
+type ResponseDataAvatar struct {
+	Status string `json:"status" example:"success"`
+	Data   Avatar `json:"data"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" binding:"required" example:"the token of the verification link"`
 }
 
 type ResponseDataUpdatedUser struct {
@@ -55,10 +78,143 @@ type ResponseDataUpdatedUser struct {
 
 type ResponseMessageDeletedUser struct {
 	Status  string `json:"status" example:"success"`
-	Message string `json:"message" example:"your account has been successfully deleted"`
+	Message string `json:"message" example:"your account will be deleted after 2030-01-31, cancel the deletion before then to keep it"`
+}
+
+type AccountDeletion struct {
+	ID         string     `json:"id" example:"here is the generated deletion id"`
+	PurgeAfter *time.Time `json:"purge_after" example:"2030-01-31T00:00:00Z"`
+	CreatedAt  *time.Time `json:"created_at" example:"the created at generated here"`
+}
+
+type ResponseDataAccountDeletion struct {
+	Status string          `json:"status" example:"success"`
+	Data   AccountDeletion `json:"data"`
+}
+
+type ResponseMessageCanceledDeletion struct {
+	Status  string `json:"status" example:"success"`
+	Message string `json:"message" example:"your account will no longer be deleted"`
 }
 
 type ResponseMessage struct {
 	Status string `json:"status" example:"fail"`
 	Data   string `json:"data" example:"the error explained here"`
 }
+
+type MFAChallenge struct {
+	MFAToken  string `json:"mfa_token" example:"the challenge token generated here"`
+	ExpiresIn int    `json:"expires_in" example:"300"`
+}
+
+type ResponseDataMFAChallenge struct {
+	Status string       `json:"status" example:"mfa_required"`
+	Data   MFAChallenge `json:"data"`
+}
+
+type LoginMFA struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"the challenge token generated here"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/MyGram:johndoe@example.com?secret=JBSWY3DPEHPK3PXP&issuer=MyGram"`
+}
+
+type ResponseDataTOTPEnrollment struct {
+	Status string         `json:"status" example:"success"`
+	Data   TOTPEnrollment `json:"data"`
+}
+
+type ConfirmTOTP struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"abcd2345-efgh6789"`
+}
+
+type ResponseDataRecoveryCodes struct {
+	Status string        `json:"status" example:"success"`
+	Data   RecoveryCodes `json:"data"`
+}
+
+type CreateAPIKey struct {
+	Name      string     `json:"name" binding:"required" example:"deploy script"`
+	Scopes    []string   `json:"scopes" binding:"required" example:"photos:read,photos:write"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2030-01-01T00:00:00Z"`
+}
+
+type CreatedAPIKey struct {
+	ID        string     `json:"id" example:"here is the generated api key id"`
+	Name      string     `json:"name" example:"deploy script"`
+	Key       string     `json:"key" example:"mygram_1a2b3c4d_the secret shown once here"`
+	Prefix    string     `json:"prefix" example:"1a2b3c4d"`
+	Scopes    []string   `json:"scopes" example:"photos:read,photos:write"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2030-01-01T00:00:00Z"`
+	CreatedAt *time.Time `json:"created_at" example:"the created at generated here"`
+}
+
+type ResponseDataCreatedAPIKey struct {
+	Status string        `json:"status" example:"success"`
+	Data   CreatedAPIKey `json:"data"`
+}
+
+type APIKey struct {
+	ID         string     `json:"id" example:"here is the generated api key id"`
+	Name       string     `json:"name" example:"deploy script"`
+	Prefix     string     `json:"prefix" example:"1a2b3c4d"`
+	Scopes     []string   `json:"scopes" example:"photos:read,photos:write"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2030-01-01T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"the last used at generated here"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"the revoked at generated here"`
+	CreatedAt  *time.Time `json:"created_at" example:"the created at generated here"`
+}
+
+type ResponseDataFetchedAPIKeys struct {
+	Status string   `json:"status" example:"success"`
+	Data   []APIKey `json:"data"`
+}
+
+type ResponseMessageRevokedAPIKey struct {
+	Status  string `json:"status" example:"success"`
+	Message string `json:"message" example:"your api key has been successfully revoked"`
+}
+
+type Session struct {
+	ID         string     `json:"id" example:"here is the generated session id"`
+	DeviceName string     `json:"device_name" example:"Firefox on Linux"`
+	IPAddress  string     `json:"ip_address" example:"203.0.113.7"`
+	UserAgent  string     `json:"user_agent" example:"Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/119.0"`
+	Current    bool       `json:"current" example:"true"`
+	CreatedAt  *time.Time `json:"created_at" example:"the created at generated here"`
+	LastSeenAt *time.Time `json:"last_seen_at" example:"the last seen at generated here"`
+	ExpiresAt  *time.Time `json:"expires_at" example:"the expires at generated here"`
+}
+
+type ResponseDataFetchedSessions struct {
+	Status string    `json:"status" example:"success"`
+	Data   []Session `json:"data"`
+}
+
+type ResponseMessageRevokedSession struct {
+	Status  string `json:"status" example:"success"`
+	Message string `json:"message" example:"the session has been successfully signed out"`
+}
+
+type DataExport struct {
+	ID                string     `json:"id" example:"here is the generated export id"`
+	Status            string     `json:"status" example:"ready"`
+	Error             string     `json:"error,omitempty" example:"the reason the export failed"`
+	CreatedAt         *time.Time `json:"created_at" example:"the created at generated here"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" example:"the completed at generated here"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty" example:"the expires at generated here"`
+	DownloadURL       string     `json:"download_url,omitempty" example:"/users/me/export/export-1/download?expires=1893456000&signature=the signature generated here"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty" example:"the download link expires at generated here"`
+}
+
+type ResponseDataDataExport struct {
+	Status string     `json:"status" example:"success"`
+	Data   DataExport `json:"data"`
+}
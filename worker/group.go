@@ -0,0 +1,112 @@
+// Package worker runs background jobs that stop with the server.
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Group runs background workers until Stop cancels their context.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewGroup(parent context.Context) *Group {
+	ctx, cancel := context.WithCancel(parent)
+
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go runs work in its own goroutine. work must return once ctx is done.
+func (group *Group) Go(name string, work func(ctx context.Context)) {
+	group.wg.Add(1)
+
+	go func() {
+		defer group.wg.Done()
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Printf("worker %s panicked: %v", name, recovered)
+			}
+		}()
+
+		work(group.ctx)
+	}()
+}
+
+// Every runs work each interval until the group stops.
+func (group *Group) Every(name string, interval time.Duration, work func(ctx context.Context)) {
+	group.Go(name, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				work(ctx)
+			}
+		}
+	})
+}
+
+// Stop cancels the workers and waits for them to return, or for ctx to end.
+func (group *Group) Stop(ctx context.Context) error {
+	group.cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		group.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Signal wakes a worker started with OnSignal.
+type Signal chan struct{}
+
+func NewSignal() Signal {
+	return make(Signal, 1)
+}
+
+// Notify wakes the worker without waiting for it. Notifications sent while
+// the worker is busy are merged into one.
+func (signal Signal) Notify() {
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
+
+// OnSignal runs work whenever signal is notified, and at least every interval
+// to catch up on anything missed, until the group stops.
+func (group *Group) OnSignal(name string, signal Signal, interval time.Duration, work func(ctx context.Context)) {
+	group.Go(name, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signal:
+			case <-ticker.C:
+			}
+
+			work(ctx)
+		}
+	})
+}